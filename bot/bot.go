@@ -3,19 +3,21 @@ package bot
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"errors"
 	"fmt"
+	"github.com/bojanz/currency"
 	"github.com/corona10/goimagehash"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/grulex/go-wishlist/container"
+	"github.com/grulex/go-wishlist/fetcher"
+	"github.com/grulex/go-wishlist/miniapp"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
 	"github.com/grulex/go-wishlist/pkg/notify"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
-	"github.com/grulex/go-wishlist/scrapper"
 	"github.com/grulex/go-wishlist/translate"
 	"github.com/jmoiron/sqlx"
 	"github.com/mvdan/xurls"
@@ -24,9 +26,7 @@ import (
 	"image"
 	_ "image/jpeg"
 	_ "image/png"
-	"io"
 	"log"
-	"net/http"
 	urlPkg "net/url"
 	"strconv"
 	"strings"
@@ -34,12 +34,15 @@ import (
 )
 
 const defaultAvatarImageID = imagePkg.ID("0fc13627-7e95-4bde-ac63-e962969b921a")
+const adminChatID = 39439763
 
 type TelegramBot struct {
 	telegramBot *tgbotapi.BotAPI
 	miniAppUrl  string
 	container   *container.ServiceContainer
 	translator  *translate.Translator
+	// imageFetcher downloads the pictures by the urls from users and shops
+	imageFetcher *fetcher.Fetcher
 }
 
 func NewTelegramBot(token, miniAppUrl string, container *container.ServiceContainer) *TelegramBot {
@@ -53,10 +56,11 @@ func NewTelegramBot(token, miniAppUrl string, container *container.ServiceContai
 	translator := translate.NewTranslator("en")
 
 	return &TelegramBot{
-		telegramBot: telegramBot,
-		miniAppUrl:  miniAppUrl,
-		container:   container,
-		translator:  translator,
+		telegramBot:  telegramBot,
+		miniAppUrl:   miniAppUrl,
+		container:    container,
+		translator:   translator,
+		imageFetcher: fetcher.NewFetcher(fetcher.ImageConfig()),
 	}
 }
 
@@ -87,10 +91,14 @@ func (s TelegramBot) Start() error {
 			if err != nil {
 				continue
 			}
+			link, ok := s.getInlineWishlistLink(ctx, update.InlineQuery.From.ID, wishlist)
+			if !ok {
+				continue
+			}
 
 			postText := wishlist.Title + "\n\n" + wishlist.Description
 			article := tgbotapi.NewInlineQueryResultArticle(update.InlineQuery.ID, wishlist.Title, postText)
-			button := getButton(s.translator.Translate(lang, "open_wishlist")+"!", s.miniAppUrl+"?startapp="+string(wishlist.ID))
+			button := getButton(s.translator.Translate(lang, "open_wishlist")+"!", link)
 			article.ReplyMarkup = &button
 			article.Description = wishlist.Description
 			article.ThumbURL = "https://png.pngtree.com/png-vector/20221121/ourmid/pngtree-comicstyle-wishlist-icon-with-splash-effect-health-sign-add-vector-png-image_41870708.jpg"
@@ -119,6 +127,10 @@ func (s TelegramBot) Start() error {
 
 				tgUserID := update.MyChatMember.From.ID
 				tgChatID := update.MyChatMember.Chat.ID
+				err = s.setNotifyChannel(ctx, tgUserID, tgChatID)
+				if err != nil {
+					log.Println(err)
+				}
 				go func() {
 					err := s.checkUpdates(ctx, tgUserID, tgChatID)
 					if err != nil {
@@ -126,12 +138,18 @@ func (s TelegramBot) Start() error {
 					}
 				}()
 			} else if update.MyChatMember.NewChatMember.Status == "kicked" {
-				// TODO: remove notify channel
+				err := s.clearNotifyChannel(ctx, update.MyChatMember.From.ID)
+				if err != nil {
+					log.Println(err)
+				}
 			}
 		}
 
 		if update.Message != nil {
-			if update.Message.Text == "/stats_week" && update.Message.Chat.ID == 39439763 {
+			if update.Message.Chat.ID == adminChatID && s.handleAdminCommand(ctx, update.Message) {
+				continue
+			}
+			if update.Message.Text == "/stats_week" && update.Message.Chat.ID == adminChatID {
 				stats, err := s.container.User.GetDailyStats(ctx, time.Hour*24*7)
 				if err != nil {
 					log.Println(err)
@@ -184,6 +202,47 @@ func (s TelegramBot) Start() error {
 	return nil
 }
 
+// handleAdminCommand reports whether the message was an admin command
+func (s TelegramBot) handleAdminCommand(ctx context.Context, message *tgbotapi.Message) bool {
+	msgStr := ""
+	switch message.Command() {
+	case "dead_letters":
+		deadLetters, err := s.container.EventManager.GetDeadLetters(ctx)
+		if err != nil {
+			log.Println(err)
+			return true
+		}
+		for _, deadLetter := range deadLetters {
+			msgStr += fmt.Sprintf("%s %s %s (%d): %s\n",
+				deadLetter.ID,
+				deadLetter.FailedAt.Format(time.DateTime),
+				deadLetter.SubscriptionID,
+				deadLetter.Attempts,
+				deadLetter.Error,
+			)
+		}
+		if msgStr == "" {
+			msgStr = "no dead letters"
+		}
+	case "replay_dead_letter":
+		id := eventmanager.DeadLetterID(strings.TrimSpace(message.CommandArguments()))
+		err := s.container.EventManager.ReplayDeadLetter(ctx, id)
+		msgStr = "replayed " + string(id)
+		if err != nil {
+			msgStr = "replay failed: " + err.Error()
+		}
+	default:
+		return false
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, msgStr)
+	_, err := s.telegramBot.Send(msg)
+	if err != nil {
+		log.Println(err)
+	}
+	return true
+}
+
 func (s TelegramBot) checkAndRegisterUser(ctx context.Context, tgUser tgbotapi.User, tgChat tgbotapi.Chat) error {
 	userSocialID := authPkg.SocialID(null.NewString(strconv.Itoa(int(tgUser.ID)), true))
 
@@ -228,21 +287,7 @@ func (s TelegramBot) checkUpdates(ctx context.Context, tgUserID, tgChatID int64)
 		return nil
 	}
 
-	user, err := s.container.User.Get(ctx, auth.UserID)
-	if err != nil {
-		return err
-	}
-	if user.NotifyType == nil {
-		tgType := notify.TypeTelegram
-		channelID := strconv.Itoa(int(tgChatID))
-		user.NotifyType = &tgType
-		user.NotifyChannelID = &channelID
-		err = s.container.User.Update(ctx, user)
-		if err != nil {
-			return err
-		}
-	}
-	wishlists, err := s.container.Wishlist.GetByUserID(ctx, user.ID)
+	wishlists, err := s.container.Wishlist.GetByUserID(ctx, auth.UserID)
 	if err != nil {
 		return err
 	}
@@ -258,6 +303,54 @@ func (s TelegramBot) checkUpdates(ctx context.Context, tgUserID, tgChatID int64)
 	return nil
 }
 
+// setNotifyChannel points notifications to the chat unless the user has chosen another channel
+func (s TelegramBot) setNotifyChannel(ctx context.Context, tgUserID, tgChatID int64) error {
+	userSocialID := authPkg.SocialID(null.NewString(strconv.Itoa(int(tgUserID)), true))
+	auth, err := s.container.Auth.Get(ctx, authPkg.MethodTelegram, userSocialID)
+	if err != nil {
+		return err
+	}
+	user, err := s.container.User.Get(ctx, auth.UserID)
+	if err != nil {
+		return err
+	}
+	if user.NotifyType != nil && *user.NotifyType != notify.TypeTelegram {
+		return nil
+	}
+
+	tgType := notify.TypeTelegram
+	channelID := strconv.Itoa(int(tgChatID))
+	if user.NotifyChannelID != nil && *user.NotifyChannelID == channelID {
+		return nil
+	}
+	user.NotifyType = &tgType
+	user.NotifyChannelID = &channelID
+	return s.container.User.Update(ctx, user)
+}
+
+// clearNotifyChannel stops telegram notifications after the user has blocked the bot
+func (s TelegramBot) clearNotifyChannel(ctx context.Context, tgUserID int64) error {
+	userSocialID := authPkg.SocialID(null.NewString(strconv.Itoa(int(tgUserID)), true))
+	auth, err := s.container.Auth.Get(ctx, authPkg.MethodTelegram, userSocialID)
+	if err != nil {
+		if errors.Is(err, authPkg.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	user, err := s.container.User.Get(ctx, auth.UserID)
+	if err != nil {
+		return err
+	}
+	if user.NotifyType == nil || *user.NotifyType != notify.TypeTelegram {
+		return nil
+	}
+
+	user.NotifyType = nil
+	user.NotifyChannelID = nil
+	return s.container.User.Update(ctx, user)
+}
+
 func (s TelegramBot) register(ctx context.Context, tgUser tgbotapi.User, tgChat tgbotapi.Chat) error {
 	createAuthTransaction, err := s.container.Auth.MakeCreateTransaction(ctx)
 	if err != nil {
@@ -295,13 +388,14 @@ func (s TelegramBot) register(ctx context.Context, tgUser tgbotapi.User, tgChat
 	}
 	avatarID := defaultAvatarImageID
 	newWishlist := &wishlistPkg.Wishlist{
-		ID:          wishlistPkg.ID(wishlistId),
-		UserID:      user.ID,
-		IsDefault:   true,
-		Title:       s.translator.Translate(string(user.Language), "wishlist_title") + " — " + name,
-		Description: s.translator.Translate(string(user.Language), "init_description"),
-		IsArchived:  false,
-		Avatar:      &avatarID,
+		ID:             wishlistPkg.ID(wishlistId),
+		UserID:         user.ID,
+		IsDefault:      true,
+		Title:          s.translator.Translate(string(user.Language), "wishlist_title") + " — " + name,
+		Description:    s.translator.Translate(string(user.Language), "init_description"),
+		IsArchived:     false,
+		IsSurpriseMode: true,
+		Avatar:         &avatarID,
 	}
 
 	err = s.container.Wishlist.Create(ctx, newWishlist)
@@ -349,18 +443,12 @@ func (s TelegramBot) createAvatarImage(ctx context.Context, tgUserId int64) (*im
 }
 
 func (s TelegramBot) createImageFromUrl(ctx context.Context, fileUrl string) (*imagePkg.Image, error) {
-	httpClient := http.Client{}
-	httpResp, err := httpClient.Get(fileUrl)
+	resp, err := s.imageFetcher.Get(ctx, fileUrl)
 	if err != nil {
 		return nil, err
 	}
-	defer func(Body io.ReadCloser) {
-		_ = httpResp.Body
-	}(httpResp.Body)
 
-	var body bytes.Buffer
-	copyBody := io.TeeReader(httpResp.Body, &body)
-	httpImage, _, err := image.Decode(copyBody)
+	httpImage, _, err := image.Decode(bytes.NewReader(resp.Body))
 	if err != nil {
 		return nil, err
 	}
@@ -378,7 +466,7 @@ func (s TelegramBot) createImageFromUrl(ctx context.Context, fileUrl string) (*i
 		return nil, err
 	}
 
-	imgSizes, err := s.container.File.UploadPhoto(ctx, &body)
+	imgSizes, err := s.container.File.UploadPhoto(ctx, bytes.NewReader(resp.Body))
 	if err != nil {
 		return nil, err
 	}
@@ -465,21 +553,28 @@ func (s TelegramBot) createWishItemsFromUrls(ctx context.Context, urls []string,
 			urlObj.Scheme = "https"
 		}
 
-		linkResult, _ := scrapper.Scrape(urlObj.String(), 5)
+		linkResult, _ := s.container.Scrape.Scrape(ctx, urlObj.String())
 
 		title := ""
 		description := ""
 		var imageID *imagePkg.ID
+		var price *currency.Amount
 		if linkResult != nil {
-			title = linkResult.Preview.Title
-			description = linkResult.Preview.Description
-			if len(linkResult.Preview.Images) != 0 {
-				image, err := s.createImageFromUrl(ctx, linkResult.Preview.Images[0])
+			title = linkResult.Title
+			description = linkResult.Description
+			price = linkResult.Price
+			imageID = linkResult.ImageID
+			if imageID == nil && len(linkResult.Images) != 0 {
+				image, err := s.createImageFromUrl(ctx, linkResult.Images[0])
 				if err != nil {
 					resultProductByUrl[url] = nil
 					continue
 				}
 				imageID = &image.ID
+				err = s.container.Scrape.SetImage(ctx, linkResult.Url, image.ID)
+				if err != nil {
+					log.Println(err)
+				}
 			}
 		}
 
@@ -497,6 +592,7 @@ func (s TelegramBot) createWishItemsFromUrls(ctx context.Context, urls []string,
 			Description: null.NewString(description, true),
 			Url:         null.NewString(urlObj.String(), true),
 			ImageID:     imageID,
+			Price:       price,
 		}
 
 		err = s.container.Product.Create(ctx, product)
@@ -540,8 +636,18 @@ func (s TelegramBot) createWishItemsFromUrls(ctx context.Context, urls []string,
 }
 
 func (s TelegramBot) makeLinkToItem(wishlistID wishlistPkg.ID, productID productPkg.ID) string {
-	miniAppInternalRoute := "/wishlists/" + string(wishlistID) + "/items/" + string(productID)
+	return miniapp.MakeLinkToItem(s.miniAppUrl, wishlistID, productID)
+}
 
-	queryBase64 := base64.StdEncoding.EncodeToString([]byte(miniAppInternalRoute))
-	return s.miniAppUrl + "?startapp=-" + queryBase64
+// getInlineWishlistLink returns the link for sharing the wishlist from an inline query.
+// Only the owner can share not public wishlists, the link then carries the share token.
+func (s TelegramBot) getInlineWishlistLink(ctx context.Context, userSocialID int64, wishlist *wishlistPkg.Wishlist) (string, bool) {
+	if wishlist.Visibility == wishlistPkg.VisibilityPublic {
+		return miniapp.MakeLinkToWishlist(s.miniAppUrl, wishlist.ID), true
+	}
+	auth, err := s.container.Auth.Get(ctx, authPkg.MethodTelegram, authPkg.SocialID(null.StringFrom(strconv.FormatInt(userSocialID, 10))))
+	if err != nil || auth.UserID != wishlist.UserID {
+		return "", false
+	}
+	return miniapp.MakeSharedLinkToWishlist(s.miniAppUrl, wishlist.ID, wishlist.ShareToken), true
 }
@@ -8,7 +8,12 @@ import (
 	"github.com/grulex/go-wishlist/container"
 	"github.com/grulex/go-wishlist/db"
 	"github.com/grulex/go-wishlist/http"
+	digestsubscriber "github.com/grulex/go-wishlist/pkg/digest/subscriber"
+	notifydigest "github.com/grulex/go-wishlist/pkg/notify/digest"
+	notifynewitems "github.com/grulex/go-wishlist/pkg/notify/newitems"
 	notifysubscriber "github.com/grulex/go-wishlist/pkg/notify/subscriber"
+	"github.com/grulex/go-wishlist/pkg/scheduler"
+	subscribesubscriber "github.com/grulex/go-wishlist/pkg/subscribe/subscriber"
 	"github.com/jmoiron/sqlx"
 	"log"
 	"os"
@@ -66,7 +71,7 @@ func main() {
 		_ = b.Start()
 	}()
 
-	if err := initEventSubscribers(serviceContainer); err != nil {
+	if err := initEventSubscribers(serviceContainer, config); err != nil {
 		log.Fatal(err)
 	}
 	go func() {
@@ -80,6 +85,18 @@ func main() {
 		}
 	}()
 
+	jobScheduler := initScheduler(serviceContainer, config)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Println("Scheduler recovered. Panic:\n", r)
+			}
+		}()
+		if err := jobScheduler.Start(context.Background()); err != nil {
+			log.Println(err)
+		}
+	}()
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 
@@ -91,6 +108,10 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	err = jobScheduler.Stop(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
 	err = serviceContainer.EventManager.Stop(ctx)
 	if err != nil {
 		log.Fatal(err)
@@ -99,9 +120,62 @@ func main() {
 	os.Exit(0)
 }
 
-func initEventSubscribers(container *container.ServiceContainer) error {
-	notifySubscriber := notifysubscriber.NewSubscriberForNotify()
+func initEventSubscribers(container *container.ServiceContainer, config *configPkg.Config) error {
+	notifySubscriber := notifysubscriber.NewSubscriberForNotify(
+		container.Notify,
+		container.Product,
+		container.Wishlist,
+		container.Subscribe,
+		container.Image,
+		container.User,
+		container.NewItem,
+		config.TelegramMiniAppUrl,
+		config.ApiUrl,
+	)
 
 	notifySubscriber.Subscribe(container.EventManager)
+
+	digestSubscriber := digestsubscriber.NewSubscriberForDigest(
+		container.Digest,
+		container.User,
+		container.Wishlist,
+		container.Subscribe,
+	)
+	digestSubscriber.Subscribe(container.EventManager)
+
+	subscribeSubscriber := subscribesubscriber.NewSubscriberForSubscribe(container.Subscribe)
+	subscribeSubscriber.Subscribe(container.EventManager)
 	return nil
 }
+
+func initScheduler(container *container.ServiceContainer, config *configPkg.Config) *scheduler.Scheduler {
+	digestSender := notifydigest.NewDigestSender(
+		container.Digest,
+		container.Notify,
+		container.User,
+		container.Wishlist,
+		container.Product,
+		config.TelegramMiniAppUrl,
+	)
+
+	newItemsSender := notifynewitems.NewNewItemsSender(
+		container.NewItem,
+		container.Notify,
+		container.Wishlist,
+		container.Subscribe,
+		container.Product,
+		container.Image,
+		container.User,
+		config.TelegramMiniAppUrl,
+		config.ApiUrl,
+		time.Minute,
+	)
+
+	jobScheduler := scheduler.NewScheduler(scheduler.RealClock{})
+	jobScheduler.Every("digest", time.Hour, digestSender.SendDue)
+	jobScheduler.Every("new_items", time.Minute, newItemsSender.SendDue)
+	jobScheduler.Every("booking_expiry", time.Hour, container.Wishlist.ReleaseExpiredBookings)
+	jobScheduler.Every("occasion_reminders", time.Hour, container.Wishlist.SendOccasionReminders)
+	jobScheduler.Every("scrape_cache_cleanup", time.Hour, container.Scrape.DeleteExpired)
+	return jobScheduler
+}
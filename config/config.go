@@ -9,6 +9,7 @@ import (
 type Config struct {
 	TelegramBotToken   string
 	TelegramMiniAppUrl string
+	ApiUrl             string
 	TgStorageBotToken  string
 	TgStorageChatID    int64
 	IsPgEnabled        bool
@@ -17,6 +18,12 @@ type Config struct {
 	PgDatabase         string
 	PgUser             string
 	PgPassword         string
+	EventWorkers       int
+	SmtpHost           string
+	SmtpPort           int
+	SmtpUser           string
+	SmtpPassword       string
+	SmtpFrom           string
 }
 
 func InitFromEnv() *Config {
@@ -27,9 +34,20 @@ func InitFromEnv() *Config {
 
 	chatID, _ := strconv.ParseInt(os.Getenv("TELEGRAM_STORAGE_CHAT_ID"), 10, 64)
 
+	eventWorkers, _ := strconv.Atoi(os.Getenv("EVENT_WORKERS"))
+	if eventWorkers <= 0 {
+		eventWorkers = 4
+	}
+
+	smtpPort, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if smtpPort == 0 {
+		smtpPort = 25
+	}
+
 	return &Config{
 		TelegramBotToken:   os.Getenv("TELEGRAM_BOT_TOKEN"),
 		TelegramMiniAppUrl: os.Getenv("TELEGRAM_MINI_APP_URL"),
+		ApiUrl:             os.Getenv("API_URL"),
 		TgStorageBotToken:  os.Getenv("TELEGRAM_STORAGE_BOT_TOKEN"),
 		TgStorageChatID:    chatID,
 		IsPgEnabled:        os.Getenv("PG_HOST") != "",
@@ -38,5 +56,11 @@ func InitFromEnv() *Config {
 		PgDatabase:         os.Getenv("PG_DATABASE"),
 		PgUser:             os.Getenv("PG_USER"),
 		PgPassword:         os.Getenv("PG_PASSWORD"),
+		EventWorkers:       eventWorkers,
+		SmtpHost:           os.Getenv("SMTP_HOST"),
+		SmtpPort:           smtpPort,
+		SmtpUser:           os.Getenv("SMTP_USER"),
+		SmtpPassword:       os.Getenv("SMTP_PASSWORD"),
+		SmtpFrom:           os.Getenv("SMTP_FROM"),
 	}
 }
@@ -4,28 +4,51 @@ import (
 	"github.com/grulex/go-wishlist/config"
 	authSrv "github.com/grulex/go-wishlist/pkg/auth/service"
 	authStore "github.com/grulex/go-wishlist/pkg/auth/storage/postgres"
-	"github.com/grulex/go-wishlist/pkg/eventmanager/inmemory"
+	digestSrv "github.com/grulex/go-wishlist/pkg/digest/service"
+	digestStore "github.com/grulex/go-wishlist/pkg/digest/storage/postgres"
+	eventPostgres "github.com/grulex/go-wishlist/pkg/eventmanager/postgres"
 	fileSrv "github.com/grulex/go-wishlist/pkg/file/service"
 	fileStorePg "github.com/grulex/go-wishlist/pkg/file/storage/postgres"
 	fileStoreTg "github.com/grulex/go-wishlist/pkg/file/storage/telegram"
 	imageSrv "github.com/grulex/go-wishlist/pkg/image/service"
 	imageStore "github.com/grulex/go-wishlist/pkg/image/storage/postgres"
+	newItemSrv "github.com/grulex/go-wishlist/pkg/newitem/service"
+	newItemStore "github.com/grulex/go-wishlist/pkg/newitem/storage/postgres"
+	notifySenderEmail "github.com/grulex/go-wishlist/pkg/notify/sender/email"
+	notifySenderTg "github.com/grulex/go-wishlist/pkg/notify/sender/telegram"
+	notifySrv "github.com/grulex/go-wishlist/pkg/notify/service"
 	productSrv "github.com/grulex/go-wishlist/pkg/product/service"
 	productStore "github.com/grulex/go-wishlist/pkg/product/storage/postgres"
+	scrapeSrv "github.com/grulex/go-wishlist/pkg/scrape/service"
+	scrapeStore "github.com/grulex/go-wishlist/pkg/scrape/storage/postgres"
 	subscribeSrv "github.com/grulex/go-wishlist/pkg/subscribe/service"
 	subscribeStore "github.com/grulex/go-wishlist/pkg/subscribe/storage/postgres"
 	userSrv "github.com/grulex/go-wishlist/pkg/user/service"
 	userStore "github.com/grulex/go-wishlist/pkg/user/storage/postgres"
 	wishlistSrv "github.com/grulex/go-wishlist/pkg/wishlist/service"
 	wishlistStore "github.com/grulex/go-wishlist/pkg/wishlist/storage/postgres"
+	"github.com/grulex/go-wishlist/translate"
 	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+const (
+	// scrapeCacheTTL keeps the previews of the shared links, so the same product added by friends
+	// doesn't refetch the page and its image
+	scrapeCacheTTL = time.Hour * 12
+	// scrapeFailureCacheTTL is shorter, the shop may be down only for a while
+	scrapeFailureCacheTTL = time.Minute * 10
 )
 
 type ServiceContainer struct {
 	Auth         authService
+	Digest       digestService
 	File         fileService
 	Image        imageService
+	NewItem      newItemService
+	Notify       notifyService
 	Product      productService
+	Scrape       scrapeService
 	Subscribe    subscribeService
 	User         userService
 	Wishlist     wishlistService
@@ -33,11 +56,14 @@ type ServiceContainer struct {
 }
 
 func NewServiceContainer(db *sqlx.DB, config *config.Config) *ServiceContainer {
-	eventManager := inmemory.NewEventManager(1000)
+	eventManager := eventPostgres.NewEventManager(db, 100, time.Second, config.EventWorkers)
 
 	authStorage := authStore.NewAuthStorage(db)
 	authService := authSrv.NewAuthService(authStorage)
 
+	digestStorage := digestStore.NewDigestStorage(db)
+	digestService := digestSrv.NewDigestService(digestStorage)
+
 	fileStorages := make([]fileSrv.FileStorage, 0, 2)
 	if config.TgStorageBotToken != "" && config.TgStorageChatID != 0 {
 		fileStorages = append(fileStorages, fileStoreTg.NewTelegramStorage(config.TgStorageBotToken, config.TgStorageChatID))
@@ -48,23 +74,46 @@ func NewServiceContainer(db *sqlx.DB, config *config.Config) *ServiceContainer {
 	imageStorage := imageStore.NewImageStorage(db)
 	imageService := imageSrv.NewImageService(imageStorage)
 
+	newItemStorage := newItemStore.NewNewItemStorage(db)
+	newItemService := newItemSrv.NewNewItemService(newItemStorage)
+
 	productStorage := productStore.NewProductStorage(db)
-	productService := productSrv.NewProductService(productStorage)
+	productService := productSrv.NewProductService(productStorage, eventManager)
+
+	scrapeStorage := scrapeStore.NewScrapeStorage(db)
+	scrapeService := scrapeSrv.NewScrapeService(scrapeStorage, scrapeCacheTTL, scrapeFailureCacheTTL)
 
 	subscribeStorage := subscribeStore.NewSubscribeStorage(db)
-	subscribeService := subscribeSrv.NewSubscribeService(subscribeStorage)
+	subscribeService := subscribeSrv.NewSubscribeService(subscribeStorage, eventManager)
 
 	userStorage := userStore.NewUserStorage(db)
 	userService := userSrv.NewUserService(userStorage)
 
+	translator := translate.NewTranslator("en")
+	notifySenders := []notifySrv.Sender{notifySenderTg.NewTelegramSender(config.TelegramBotToken)}
+	if config.SmtpHost != "" {
+		notifySenders = append(notifySenders, notifySenderEmail.NewEmailSender(notifySenderEmail.Config{
+			Host:     config.SmtpHost,
+			Port:     config.SmtpPort,
+			User:     config.SmtpUser,
+			Password: config.SmtpPassword,
+			From:     config.SmtpFrom,
+		}, translator))
+	}
+	notifyService := notifySrv.NewNotifyService(notifySenders, userService, translator)
+
 	wishlistStorage := wishlistStore.NewImageStorage(db)
 	wishlistService := wishlistSrv.NewWishlistService(wishlistStorage, eventManager)
 
 	return &ServiceContainer{
 		Auth:         authService,
+		Digest:       digestService,
 		File:         fileService,
 		Image:        imageService,
+		NewItem:      newItemService,
+		Notify:       notifyService,
 		Product:      productService,
+		Scrape:       scrapeService,
 		Subscribe:    subscribeService,
 		User:         userService,
 		Wishlist:     wishlistService,
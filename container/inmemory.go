@@ -3,27 +3,40 @@ package container
 import (
 	authSrv "github.com/grulex/go-wishlist/pkg/auth/service"
 	authInmemory "github.com/grulex/go-wishlist/pkg/auth/storage/inmemory"
+	digestSrv "github.com/grulex/go-wishlist/pkg/digest/service"
+	digestInmemory "github.com/grulex/go-wishlist/pkg/digest/storage/inmemory"
 	"github.com/grulex/go-wishlist/pkg/eventmanager/inmemory"
 	fileSrv "github.com/grulex/go-wishlist/pkg/file/service"
 	fileInmemory "github.com/grulex/go-wishlist/pkg/file/storage/inmemory"
 	imageSrv "github.com/grulex/go-wishlist/pkg/image/service"
 	imageInmemory "github.com/grulex/go-wishlist/pkg/image/storage/inmemory"
+	newItemSrv "github.com/grulex/go-wishlist/pkg/newitem/service"
+	newItemInmemory "github.com/grulex/go-wishlist/pkg/newitem/storage/inmemory"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	notifySenderInmemory "github.com/grulex/go-wishlist/pkg/notify/sender/inmemory"
+	notifySrv "github.com/grulex/go-wishlist/pkg/notify/service"
 	productSrv "github.com/grulex/go-wishlist/pkg/product/service"
 	productInmemory "github.com/grulex/go-wishlist/pkg/product/storage/inmemory"
+	scrapeSrv "github.com/grulex/go-wishlist/pkg/scrape/service"
+	scrapeInmemory "github.com/grulex/go-wishlist/pkg/scrape/storage/inmemory"
 	subscribeSrv "github.com/grulex/go-wishlist/pkg/subscribe/service"
 	subscribeInmemory "github.com/grulex/go-wishlist/pkg/subscribe/storage/inmemory"
 	userSrv "github.com/grulex/go-wishlist/pkg/user/service"
 	userInmemory "github.com/grulex/go-wishlist/pkg/user/storage/inmemory"
 	wishlistSrv "github.com/grulex/go-wishlist/pkg/wishlist/service"
 	wishlistInmemory "github.com/grulex/go-wishlist/pkg/wishlist/storage/inmemory"
+	"github.com/grulex/go-wishlist/translate"
 )
 
 func NewInMemoryServiceContainer() *ServiceContainer {
-	eventManager := inmemory.NewEventManager(1000)
+	eventManager := inmemory.NewEventManager(1000, 4)
 
 	authStorage := authInmemory.NewAuthInMemory()
 	authService := authSrv.NewAuthService(authStorage)
 
+	digestStorage := digestInmemory.NewDigestInMemory()
+	digestService := digestSrv.NewDigestService(digestStorage)
+
 	fileStorages := make([]fileSrv.FileStorage, 1)
 	fileStorages[0] = fileInmemory.NewFileInMemory()
 	fileService := fileSrv.NewFileService(fileStorages)
@@ -31,23 +44,39 @@ func NewInMemoryServiceContainer() *ServiceContainer {
 	imageStorage := imageInmemory.NewImageInMemory()
 	imageService := imageSrv.NewImageService(imageStorage)
 
+	newItemStorage := newItemInmemory.NewNewItemInMemory()
+	newItemService := newItemSrv.NewNewItemService(newItemStorage)
+
 	productStorage := productInmemory.NewProductInMemory()
-	productService := productSrv.NewProductService(productStorage)
+	productService := productSrv.NewProductService(productStorage, eventManager)
+
+	scrapeStorage := scrapeInmemory.NewScrapeInMemory()
+	scrapeService := scrapeSrv.NewScrapeService(scrapeStorage, scrapeCacheTTL, scrapeFailureCacheTTL)
 
 	subscribeStorage := subscribeInmemory.NewSubscribeInMemory()
-	subscribeService := subscribeSrv.NewSubscribeService(subscribeStorage)
+	subscribeService := subscribeSrv.NewSubscribeService(subscribeStorage, eventManager)
 
 	userStorage := userInmemory.NewUserInMemory()
 	userService := userSrv.NewUserService(userStorage)
 
-	wishlistStorage := wishlistInmemory.NewWishlistInMemory()
+	notifySenders := []notifySrv.Sender{
+		notifySenderInmemory.NewSenderInMemory(notify.TypeTelegram),
+		notifySenderInmemory.NewSenderInMemory(notify.TypeEmail),
+	}
+	notifyService := notifySrv.NewNotifyService(notifySenders, userService, translate.NewTranslator("en"))
+
+	wishlistStorage := wishlistInmemory.NewWishlistInMemory(productStorage)
 	wishlistService := wishlistSrv.NewWishlistService(wishlistStorage, eventManager)
 
 	return &ServiceContainer{
 		Auth:         authService,
+		Digest:       digestService,
 		File:         fileService,
 		Image:        imageService,
+		NewItem:      newItemService,
+		Notify:       notifyService,
 		Product:      productService,
+		Scrape:       scrapeService,
 		Subscribe:    subscribeService,
 		User:         userService,
 		Wishlist:     wishlistService,
@@ -2,11 +2,16 @@ package container
 
 import (
 	"context"
+	"github.com/bojanz/currency"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
 	"github.com/grulex/go-wishlist/pkg/eventmanager"
 	filePkg "github.com/grulex/go-wishlist/pkg/file"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	"github.com/grulex/go-wishlist/pkg/notify"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
@@ -32,6 +37,12 @@ type imageService interface {
 	GetMany(ctx context.Context, ids []imagePkg.ID) ([]*imagePkg.Image, error)
 }
 
+type notifyService interface {
+	Notify(ctx context.Context, userID userPkg.ID, kind notify.EventKind, key string, params ...any) error
+	NotifyWithImage(ctx context.Context, userID userPkg.ID, kind notify.EventKind, imageUrl string, key string, params ...any) error
+	NotifyChannel(ctx context.Context, userID userPkg.ID, notifyType notify.Type, channelID string, key string, params ...any) error
+}
+
 type productService interface {
 	Create(ctx context.Context, product *productPkg.Product) error
 	Get(ctx context.Context, id productPkg.ID) (*productPkg.Product, error)
@@ -43,7 +54,10 @@ type subscribeService interface {
 	Subscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID) error
 	Get(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID) (*subscribePkg.Subscribe, error)
 	GetByUser(ctx context.Context, userID userPkg.ID) ([]*subscribePkg.Subscribe, error)
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*subscribePkg.Subscribe, error)
+	SetNotifyEnabled(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID, isEnabled bool) error
 	Unsubscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID) error
+	UnsubscribeAll(ctx context.Context, wishlistID wishlistPkg.ID) error
 }
 
 type userService interface {
@@ -51,6 +65,8 @@ type userService interface {
 	Get(ctx context.Context, userID userPkg.ID) (*userPkg.User, error)
 	GetDailyStats(ctx context.Context, duration time.Duration) ([]*userPkg.Stats, error)
 	Update(ctx context.Context, user *userPkg.User) error
+	SetEmail(ctx context.Context, id userPkg.ID, email string) (string, error)
+	VerifyEmail(ctx context.Context, id userPkg.ID, code string) error
 }
 
 type wishlistService interface {
@@ -60,19 +76,68 @@ type wishlistService interface {
 	Update(ctx context.Context, wishlist *wishlistPkg.Wishlist) error
 	Archive(ctx context.Context, id wishlistPkg.ID) error
 	Restore(ctx context.Context, id wishlistPkg.ID) error
+	SetDefault(ctx context.Context, id wishlistPkg.ID) error
+	Delete(ctx context.Context, id wishlistPkg.ID) error
+	RotateShareToken(ctx context.Context, id wishlistPkg.ID) (string, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	CanNotify(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID) (bool, error)
+	AcceptInvite(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID, token string) error
+	GetInvites(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Invite, error)
+	Invite(ctx context.Context, wishlistID wishlistPkg.ID, userID userPkg.ID) error
+	RemoveInvite(ctx context.Context, wishlistID wishlistPkg.ID, userID userPkg.ID) error
 	GetWishlistItem(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error)
-	GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, limit, offset uint) ([]*wishlistPkg.Item, bool, error)
+	GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, order wishlistPkg.ItemsOrder, limit, offset uint) ([]*wishlistPkg.Item, bool, error)
+	GetItemsByProductID(ctx context.Context, productID productPkg.ID) ([]*wishlistPkg.Item, error)
 	AddWishlistItem(ctx context.Context, item *wishlistPkg.Item) error
 	SetBookingAvailabilityForItem(ctx context.Context, itemID wishlistPkg.ItemID, isAvailable bool) error
 	RemoveItem(ctx context.Context, item wishlistPkg.ItemID) error
-	BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID) error
-	UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID) error
+	SetItemQuantity(ctx context.Context, itemID wishlistPkg.ItemID, quantity uint) error
+	SetItemPriority(ctx context.Context, itemID wishlistPkg.ItemID, priority wishlistPkg.Priority) error
+	ReorderItems(ctx context.Context, wishlistID wishlistPkg.ID, productIDs []productPkg.ID) error
+	GetBookings(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Bookings, error)
+	GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Bookings, error)
+	BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, quantity uint, expiresAt *time.Time) error
+	UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, quantity uint) error
+	GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Contributions, error)
+	GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Contributions, error)
+	Contribute(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, amount currency.Amount, price *currency.Amount) error
+	WithdrawContribution(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, price *currency.Amount) error
+	ReleaseExpiredBookings(ctx context.Context, at time.Time) error
+	SendOccasionReminders(ctx context.Context, at time.Time) error
+	FulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error
+	UnfulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error
+	GetReceivedGifts(ctx context.Context, userID userPkg.ID) ([]*wishlistPkg.Gift, error)
+	ThankGivers(ctx context.Context, itemID wishlistPkg.ItemID, message string) error
+}
+
+type digestService interface {
+	Add(ctx context.Context, entry *digestPkg.Entry) error
+	GetUserIDs(ctx context.Context) ([]userPkg.ID, error)
+	GetByUser(ctx context.Context, userID userPkg.ID) ([]*digestPkg.Entry, error)
+	DeleteByUser(ctx context.Context, userID userPkg.ID, before time.Time) error
+}
+
+type newItemService interface {
+	Add(ctx context.Context, item *newitemPkg.Pending) error
+	GetWishlistIDs(ctx context.Context, addedBefore time.Time) ([]wishlistPkg.ID, error)
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*newitemPkg.Pending, error)
+	DeleteByWishlist(ctx context.Context, wishlistID wishlistPkg.ID, before time.Time) error
+}
+
+type scrapeService interface {
+	Scrape(ctx context.Context, url string) (*scrapePkg.Result, error)
+	SetImage(ctx context.Context, url string, imageID imagePkg.ID) error
+	Invalidate(ctx context.Context, url string) error
+	DeleteExpired(ctx context.Context, at time.Time) error
 }
 
 type eventManager interface {
 	Publish(ctx context.Context, event eventmanager.Event) error
 	PublishMany(ctx context.Context, events ...eventmanager.Event) error
-	Subscribe(eventName eventmanager.EventName, handler eventmanager.EventHandler)
+	Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler)
+	SubscribeWithPolicy(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler, policy eventmanager.RetryPolicy)
+	GetDeadLetters(ctx context.Context) ([]*eventmanager.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id eventmanager.DeadLetterID) error
 	StartHandling(ctx context.Context) error
 	Stop(ctx context.Context) error
 }
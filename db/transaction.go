@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+type transactionKey struct{}
+
+// WithTransaction returns the context carrying the transaction. Storages and the event outbox using
+// the same connection join it instead of starting their own, so their changes are committed together.
+func WithTransaction(ctx context.Context, tx *sqlx.Tx) context.Context {
+	return context.WithValue(ctx, transactionKey{}, tx)
+}
+
+func TransactionFromContext(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(transactionKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// InTransaction runs the change in the transaction from the context. Without one it starts a new transaction,
+// passes it on with the context and commits it if the change succeeds.
+func InTransaction(ctx context.Context, conn *sqlx.DB, change func(ctx context.Context, tx *sqlx.Tx) error) error {
+	if tx, ok := TransactionFromContext(ctx); ok {
+		return change(ctx, tx)
+	}
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func(tx *sqlx.Tx) {
+		_ = tx.Rollback()
+	}(tx)
+
+	err = change(WithTransaction(ctx, tx), tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Executor returns the transaction from the context or the connection itself
+func Executor(ctx context.Context, conn *sqlx.DB) sqlx.ExtContext {
+	if tx, ok := TransactionFromContext(ctx); ok {
+		return tx
+	}
+	return conn
+}
@@ -0,0 +1,253 @@
+package fetcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrForbiddenAddress      = errors.New("address is not allowed")
+	ErrForbiddenScheme       = errors.New("only http and https urls are allowed")
+	ErrTooManyRedirects      = errors.New("too many redirects")
+	ErrBodyTooLarge          = errors.New("response body is too large")
+	ErrContentTypeNotAllowed = errors.New("content type is not allowed")
+)
+
+type Config struct {
+	// Timeout limits the whole request including the redirects and reading the body
+	Timeout      time.Duration
+	MaxBodySize  int64
+	MaxRedirects int
+	// ContentTypes are the allowed media type prefixes, e.g. "image/", any type is allowed when empty
+	ContentTypes []string
+	UserAgent    string
+}
+
+// PageConfig is for the html pages of the shops
+func PageConfig() Config {
+	return Config{
+		Timeout:      time.Second * 15,
+		MaxBodySize:  5 << 20,
+		MaxRedirects: 5,
+		ContentTypes: []string{"text/html", "application/xhtml+xml"},
+		UserAgent:    "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.1.2 Safari/605.1.15",
+	}
+}
+
+// ImageConfig is for the product pictures and the user avatars
+func ImageConfig() Config {
+	return Config{
+		Timeout:      time.Second * 20,
+		MaxBodySize:  10 << 20,
+		MaxRedirects: 3,
+		ContentTypes: []string{"image/"},
+		UserAgent:    "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.1.2 Safari/605.1.15",
+	}
+}
+
+type Response struct {
+	// Url is the final one after the redirects
+	Url         *url.URL
+	StatusCode  int
+	Header      http.Header
+	ContentType string
+	Body        []byte
+}
+
+// Fetcher downloads the urls given by users. The connections to private, loopback and link-local
+// addresses are refused after the DNS resolution, so neither the redirects nor the DNS records
+// can point the requests into the internal network.
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+func NewFetcher(config Config) *Fetcher {
+	dialer := &net.Dialer{
+		Timeout:   time.Second * 10,
+		KeepAlive: time.Second * 30,
+		Control:   controlAddress,
+	}
+	transport := &http.Transport{
+		// the proxy from the environment would do the resolution on its side
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       time.Second * 90,
+		TLSHandshakeTimeout:   time.Second * 10,
+		ResponseHeaderTimeout: time.Second * 10,
+	}
+	f := &Fetcher{config: config}
+	f.client = &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > config.MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			return checkScheme(req.URL)
+		},
+	}
+	return f
+}
+
+// NewRequest makes the GET request with the configured user agent, callers may add headers to it
+func (f *Fetcher) NewRequest(ctx context.Context, rawUrl string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.config.UserAgent != "" {
+		req.Header.Set("User-Agent", f.config.UserAgent)
+	}
+	return req, nil
+}
+
+func (f *Fetcher) Get(ctx context.Context, rawUrl string) (*Response, error) {
+	req, err := f.NewRequest(ctx, rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	return f.Do(req)
+}
+
+// Do sends the request and reads the body, responses with an error status are returned as errors
+func (f *Fetcher) Do(req *http.Request) (*Response, error) {
+	if err := checkScheme(req.URL); err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	if f.config.MaxBodySize > 0 && resp.ContentLength > f.config.MaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	var body bytes.Buffer
+	reader := io.Reader(resp.Body)
+	if f.config.MaxBodySize > 0 {
+		reader = io.LimitReader(resp.Body, f.config.MaxBodySize+1)
+	}
+	_, err = io.Copy(&body, reader)
+	if err != nil {
+		return nil, err
+	}
+	if f.config.MaxBodySize > 0 && int64(body.Len()) > f.config.MaxBodySize {
+		return nil, ErrBodyTooLarge
+	}
+
+	contentType := detectContentType(resp.Header.Get("Content-Type"), body.Bytes())
+	if !f.isAllowedContentType(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrContentTypeNotAllowed, contentType)
+	}
+
+	return &Response{
+		Url:         resp.Request.URL,
+		StatusCode:  resp.StatusCode,
+		Header:      resp.Header,
+		ContentType: contentType,
+		Body:        body.Bytes(),
+	}, nil
+}
+
+func (f *Fetcher) isAllowedContentType(contentType string) bool {
+	if len(f.config.ContentTypes) == 0 {
+		return true
+	}
+	for _, allowed := range f.config.ContentTypes {
+		if strings.HasPrefix(contentType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectContentType returns the declared media type, the generic or missing one is sniffed from the body
+func detectContentType(header string, body []byte) string {
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil || mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(body))
+	}
+	return strings.ToLower(mediaType)
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrForbiddenScheme
+	}
+	return nil
+}
+
+// controlAddress is called by the dialer with the resolved address right before connecting
+func controlAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+var reservedNetworks = mustParseNetworks(
+	"0.0.0.0/8",       // "this" network
+	"100.64.0.0/10",   // carrier-grade NAT
+	"192.0.0.0/24",    // IETF protocol assignments
+	"192.0.2.0/24",    // TEST-NET-1
+	"198.18.0.0/15",   // benchmarking
+	"198.51.100.0/24", // TEST-NET-2
+	"203.0.113.0/24",  // TEST-NET-3
+	"240.0.0.0/4",     // reserved and broadcast
+	"64:ff9b::/96",    // NAT64 may reach the IPv4 internal network
+	"2001:db8::/32",   // documentation
+)
+
+// IsPublicIP reports whether the address is routable in the internet
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
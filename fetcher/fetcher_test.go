@@ -0,0 +1,82 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"8.8.8.8":              true,
+		"2a00:1450:4001::200e": true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"224.0.0.1":            false,
+		"255.255.255.255":      false,
+		"::1":                  false,
+		"::":                   false,
+		"fc00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:10.0.0.1":      false,
+		"64:ff9b::a00:1":       false,
+	}
+	for address, expected := range tests {
+		if IsPublicIP(net.ParseIP(address)) != expected {
+			t.Fatalf("expected %s to be public: %v", address, expected)
+		}
+	}
+}
+
+func TestControlAddress(t *testing.T) {
+	if err := controlAddress("tcp4", "8.8.8.8:443", nil); err != nil {
+		t.Fatalf("expected the public address to be allowed, got %v", err)
+	}
+	for _, address := range []string{"127.0.0.1:80", "[::1]:443", "169.254.169.254:80", "localhost:80"} {
+		if err := controlAddress("tcp", address, nil); !errors.Is(err, ErrForbiddenAddress) {
+			t.Fatalf("expected %s to be forbidden, got %v", address, err)
+		}
+	}
+}
+
+func TestCheckScheme(t *testing.T) {
+	for raw, allowed := range map[string]bool{
+		"http://shop.example.com":  true,
+		"https://shop.example.com": true,
+		"ftp://shop.example.com":   false,
+		"file:///etc/passwd":       false,
+		"gopher://127.0.0.1:25":    false,
+	} {
+		u, _ := url.Parse(raw)
+		if err := checkScheme(u); (err == nil) != allowed {
+			t.Fatalf("expected %s to be allowed: %v, got %v", raw, allowed, err)
+		}
+	}
+}
+
+func TestFetcherRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+	f := NewFetcher(PageConfig())
+
+	_, err := f.Get(context.Background(), server.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected the loopback server to be refused, got %v", err)
+	}
+	_, err = f.Get(context.Background(), "file:///etc/passwd")
+	if !errors.Is(err, ErrForbiddenScheme) {
+		t.Fatalf("expected the file scheme to be refused, got %v", err)
+	}
+}
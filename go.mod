@@ -10,7 +10,9 @@ require (
 	github.com/gorilla/mux v1.8.0
 	github.com/jackc/pgx/v5 v5.4.3
 	github.com/jmoiron/sqlx v1.3.5
+	github.com/joho/godotenv v1.5.1
 	github.com/mvdan/xurls v1.1.0
+	golang.org/x/image v0.13.0
 	golang.org/x/net v0.10.0
 	gopkg.in/guregu/null.v4 v4.0.0
 )
@@ -19,9 +21,7 @@ require (
 	github.com/cockroachdb/apd/v3 v3.1.2 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20221227161230-091c0ba34f0a // indirect
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646 // indirect
 	golang.org/x/crypto v0.9.0 // indirect
-	golang.org/x/image v0.13.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
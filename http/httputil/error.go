@@ -29,6 +29,10 @@ func (h *HandleError) GetHttpStatus() int {
 		return http.StatusUnauthorized
 	case ErrorForbidden:
 		return http.StatusForbidden
+	case ErrorConflict:
+		return http.StatusConflict
+	case ErrorTooManyRequests:
+		return http.StatusTooManyRequests
 	default:
 		return http.StatusInternalServerError
 	}
@@ -39,5 +43,8 @@ const (
 	ErrorNotFound  errorType = "not_found"
 	ErrorBadAuth   errorType = "bad_auth"
 	ErrorForbidden errorType = "no_permission"
+	ErrorConflict  errorType = "conflict"
 	ErrorInternal  errorType = "internal"
+
+	ErrorTooManyRequests errorType = "too_many_requests"
 )
@@ -1,6 +1,9 @@
 package httputil
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 type HttpUseCase func(r *http.Request) HandleResult
 type responseType string
@@ -9,8 +12,16 @@ const (
 	ResponseTypeJson responseType = "json"
 	ResponseTypeHtml responseType = "html"
 	ResponseTypeJpeg responseType = "jpeg"
+	// ResponseTypeImage expects the Image payload
+	ResponseTypeImage responseType = "image"
 )
 
+// Image is the payload of an image of the given media type, e.g. "image/png"
+type Image struct {
+	ContentType string
+	Body        io.ReadCloser
+}
+
 type HandleResult struct {
 	Payload interface{}
 	Type    responseType
@@ -79,18 +79,13 @@ func responseOk(result HandleResult, w http.ResponseWriter) {
 	}
 
 	if result.Type == ResponseTypeJpeg {
-		now := time.Now()
-		reader := result.Payload.(io.ReadCloser)
-		w.Header().Set("Content-Type", "image/jpeg")
-		w.Header().Set("Last-Modified", now.Format(http.TimeFormat))
-		w.Header().Set("ETag", "image/jpeg")
-		w.Header().Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
-		w.Header().Set("Cache-Control", "public,max-age=86400;")
-		w.WriteHeader(http.StatusOK)
-		if n, err := io.Copy(w, reader); err != nil {
-			log.Println("error writing response", "err", err, "bytesWritten", n)
-		}
-		_ = reader.Close()
+		writeImage(w, "image/jpeg", result.Payload.(io.ReadCloser))
+		return
+	}
+
+	if result.Type == ResponseTypeImage {
+		image := result.Payload.(Image)
+		writeImage(w, image.ContentType, image.Body)
 		return
 	}
 
@@ -101,3 +96,18 @@ func responseOk(result HandleResult, w http.ResponseWriter) {
 		log.Println("error writing response", "err", err, "bytesWritten", n)
 	}
 }
+
+func writeImage(w http.ResponseWriter, contentType string, reader io.ReadCloser) {
+	now := time.Now()
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Last-Modified", now.Format(http.TimeFormat))
+	w.Header().Set("ETag", contentType)
+	w.Header().Set("Expires", now.Add(time.Hour).Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "public,max-age=86400;")
+	w.WriteHeader(http.StatusOK)
+	if n, err := io.Copy(w, reader); err != nil {
+		log.Println("error writing response", "err", err, "bytesWritten", n)
+	}
+	_ = reader.Close()
+}
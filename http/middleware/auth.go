@@ -195,13 +195,14 @@ func registerTelegramUser(
 	}
 	avatar := defaultAvatarImageID
 	newWishlist := &wishlistPkg.Wishlist{
-		ID:          wishlistPkg.ID(wishlistId),
-		UserID:      user.ID,
-		IsDefault:   true,
-		Title:       translator.Translate(string(user.Language), "wishlist_title") + " — " + name,
-		Description: translator.Translate(string(user.Language), "init_description"),
-		IsArchived:  false,
-		Avatar:      &avatar,
+		ID:             wishlistPkg.ID(wishlistId),
+		UserID:         user.ID,
+		IsDefault:      true,
+		Title:          translator.Translate(string(user.Language), "wishlist_title") + " — " + name,
+		Description:    translator.Translate(string(user.Language), "init_description"),
+		IsArchived:     false,
+		IsSurpriseMode: true,
+		Avatar:         &avatar,
 	}
 
 	err = wService.Create(ctx, newWishlist)
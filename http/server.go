@@ -5,21 +5,40 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/config"
 	"github.com/grulex/go-wishlist/container"
+	"github.com/grulex/go-wishlist/fetcher"
 	httpUtil "github.com/grulex/go-wishlist/http/httputil"
 	"github.com/grulex/go-wishlist/http/middleware"
 	"github.com/grulex/go-wishlist/http/usecase"
 	"github.com/grulex/go-wishlist/http/usecase/images"
+	"github.com/grulex/go-wishlist/http/usecase/scrape"
 	"github.com/grulex/go-wishlist/http/usecase/users"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/add_product_to_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/add_wishlist_invite"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/archive_wishlist"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/book_wishlist_item"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/contribute_wishlist_item"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/create_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/delete_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/fulfill_wishlist_item"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/get_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/get_wishlist_invites"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/get_wishlist_item_contributions"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/get_wishlist_items"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/remove_product_from_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/remove_wishlist_invite"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/reorder_wishlist_items"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/restore_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/rotate_wishlist_share_token"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/set_default_wishlist"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/subscribe_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/thank_wishlist_item_givers"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/unbook_wishlist_item"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/unfulfill_wishlist_item"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/unsubscribe_wishlist"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/update_subscribe_wishlist"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/update_wishlist"
 	"github.com/grulex/go-wishlist/http/usecase/wishlists/update_wishlist_item"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists/withdraw_wishlist_item_contribution"
 	"net/http"
 	"time"
 )
@@ -40,10 +59,47 @@ func NewServer(listenAddr string, container *container.ServiceContainer, config
 		images.MakeGetImageFileHandler(container.File),
 	)).Methods("GET")
 
+	imageProxySecret := []byte("image_proxy:" + config.TelegramBotToken)
+	apiRouter.HandleFunc("/images/proxy/{signature}/{url_base64}", httpUtil.ResponseWrapper(
+		images.MakeGetProxyImageHandler(fetcher.NewFetcher(fetcher.ImageConfig()), imageProxySecret),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/scrape", httpUtil.ResponseWrapper(
+		scrape.MakeScrapeUsecase(container.Scrape, imageProxySecret),
+	)).Methods("POST")
+
 	apiRouter.HandleFunc("/profile", httpUtil.ResponseWrapper(
 		users.MakeGetProfileUsecase(container.Subscribe, container.Wishlist, container.Image),
 	)).Methods("GET")
 
+	apiRouter.HandleFunc("/profile/notifications", httpUtil.ResponseWrapper(
+		users.MakeGetNotificationPreferencesUsecase(container.User, container.Subscribe),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/profile/notifications", httpUtil.ResponseWrapper(
+		users.MakeUpdateNotificationPreferencesUsecase(container.User, container.Subscribe),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/profile/email", httpUtil.ResponseWrapper(
+		users.MakeSetEmailUsecase(container.User, container.Notify),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/profile/email/verify", httpUtil.ResponseWrapper(
+		users.MakeVerifyEmailUsecase(container.User),
+	)).Methods("POST")
+
+	apiRouter.HandleFunc("/profile/wishlists", httpUtil.ResponseWrapper(
+		users.MakeGetProfileWishlistsUsecase(container.Wishlist, container.Image),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/profile/gifts", httpUtil.ResponseWrapper(
+		users.MakeGetReceivedGiftsUsecase(container.Wishlist, container.Product, container.User, container.Image),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/wishlists", httpUtil.ResponseWrapper(
+		create_wishlist.MakeCreateWishlistUsecase(container.Wishlist, container.File, container.Image),
+	)).Methods("POST")
+
 	apiRouter.HandleFunc("/wishlists/{id}", httpUtil.ResponseWrapper(
 		get_wishlist.MakeGetWishlistUsecase(container.Subscribe, container.Wishlist, container.Image),
 	)).Methods("GET")
@@ -52,10 +108,46 @@ func NewServer(listenAddr string, container *container.ServiceContainer, config
 		update_wishlist.MakeUpdateWishlistUsecase(container.Wishlist, container.File, container.Image),
 	)).Methods("PUT")
 
+	apiRouter.HandleFunc("/wishlists/{id}", httpUtil.ResponseWrapper(
+		delete_wishlist.MakeDeleteWishlistUsecase(container.Wishlist),
+	)).Methods("DELETE")
+
+	apiRouter.HandleFunc("/wishlists/{id}/archive", httpUtil.ResponseWrapper(
+		archive_wishlist.MakeArchiveWishlistUsecase(container.Wishlist),
+	)).Methods("POST")
+
+	apiRouter.HandleFunc("/wishlists/{id}/restore", httpUtil.ResponseWrapper(
+		restore_wishlist.MakeRestoreWishlistUsecase(container.Wishlist),
+	)).Methods("POST")
+
+	apiRouter.HandleFunc("/wishlists/{id}/default", httpUtil.ResponseWrapper(
+		set_default_wishlist.MakeSetDefaultWishlistUsecase(container.Wishlist),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/wishlists/{id}/share-token", httpUtil.ResponseWrapper(
+		rotate_wishlist_share_token.MakeRotateWishlistShareTokenUsecase(container.Wishlist),
+	)).Methods("POST")
+
+	apiRouter.HandleFunc("/wishlists/{id}/invites", httpUtil.ResponseWrapper(
+		get_wishlist_invites.MakeGetWishlistInvitesUsecase(container.Wishlist, container.User),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/wishlists/{id}/invites/{userId}", httpUtil.ResponseWrapper(
+		add_wishlist_invite.MakeAddWishlistInviteUsecase(container.Wishlist, container.User),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/wishlists/{id}/invites/{userId}", httpUtil.ResponseWrapper(
+		remove_wishlist_invite.MakeRemoveWishlistInviteUsecase(container.Wishlist),
+	)).Methods("DELETE")
+
 	apiRouter.HandleFunc("/wishlists/{id}/subscribe", httpUtil.ResponseWrapper(
 		subscribe_wishlist.MakeSubscribeWishlistUsecase(container.Wishlist, container.Subscribe),
 	)).Methods("POST")
 
+	apiRouter.HandleFunc("/wishlists/{id}/subscribe", httpUtil.ResponseWrapper(
+		update_subscribe_wishlist.MakeUpdateSubscribeWishlistUsecase(container.Subscribe),
+	)).Methods("PUT")
+
 	apiRouter.HandleFunc("/wishlists/{id}/unsubscribe", httpUtil.ResponseWrapper(
 		unsubscribe_wishlist.MakeUnSubscribeWishlistUsecase(container.Wishlist, container.Subscribe),
 	)).Methods("POST")
@@ -68,6 +160,10 @@ func NewServer(listenAddr string, container *container.ServiceContainer, config
 		add_product_to_wishlist.MakeAddProductToWishlistUsecase(container.Wishlist, container.Product, container.File, container.Image),
 	)).Methods("POST")
 
+	apiRouter.HandleFunc("/wishlists/{id}/items/reorder", httpUtil.ResponseWrapper(
+		reorder_wishlist_items.MakeReorderWishlistItemsUsecase(container.Wishlist),
+	)).Methods("POST")
+
 	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}", httpUtil.ResponseWrapper(
 		update_wishlist_item.MakeUpdateWishlistItemUsecase(container.Wishlist, container.Product, container.File, container.Image),
 	)).Methods("PUT")
@@ -80,6 +176,30 @@ func NewServer(listenAddr string, container *container.ServiceContainer, config
 		unbook_wishlist_item.MakeUnBookWishlistItemUsecase(container.Wishlist),
 	)).Methods("DELETE")
 
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/contributions", httpUtil.ResponseWrapper(
+		get_wishlist_item_contributions.MakeGetWishlistItemContributionsUsecase(container.Wishlist, container.Product, container.User),
+	)).Methods("GET")
+
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/contribution", httpUtil.ResponseWrapper(
+		contribute_wishlist_item.MakeContributeWishlistItemUsecase(container.Wishlist, container.Product),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/contribution", httpUtil.ResponseWrapper(
+		withdraw_wishlist_item_contribution.MakeWithdrawWishlistItemContributionUsecase(container.Wishlist, container.Product),
+	)).Methods("DELETE")
+
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/fulfilled", httpUtil.ResponseWrapper(
+		fulfill_wishlist_item.MakeFulfillWishlistItemUsecase(container.Wishlist),
+	)).Methods("PUT")
+
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/fulfilled", httpUtil.ResponseWrapper(
+		unfulfill_wishlist_item.MakeUnfulfillWishlistItemUsecase(container.Wishlist),
+	)).Methods("DELETE")
+
+	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}/thanks", httpUtil.ResponseWrapper(
+		thank_wishlist_item_givers.MakeThankWishlistItemGiversUsecase(container.Wishlist),
+	)).Methods("POST")
+
 	apiRouter.HandleFunc("/wishlists/{id}/items/{productId}", httpUtil.ResponseWrapper(
 		remove_product_from_wishlist.MakeRemoveProductFromWishlistUsecase(container.Wishlist),
 	)).Methods("DELETE")
@@ -8,18 +8,19 @@ import (
 )
 
 func GetFileUrl(r *http.Request, link file.Link) string {
-	if link.StorageType == file.StorageTypeRemoteLink {
-		return string(link.ID)
-	}
+	return link.Url(GetApiUrl(r))
+}
+
+// GetApiUrl returns the scheme and the host the request came to
+func GetApiUrl(r *http.Request) string {
 	host := r.Host
-	mask := "https://%s/api/images/%s"
+	mask := "https://%s"
 
 	// for local env
 	hostPort := strings.Split(r.Host, ":")
 	if hostPort[0] == "localhost" || hostPort[0] == "127.0.0.1" {
-		mask = "http://%s/api/images/%s"
+		mask = "http://%s"
 	}
 
-	linkBase64 := link.Base64()
-	return fmt.Sprintf(mask, host, linkBase64)
+	return fmt.Sprintf(mask, host)
 }
@@ -0,0 +1,84 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/fetcher"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase"
+	"io"
+	"net/http"
+)
+
+// proxySignatureLength is the number of hex characters of the signature kept in the link
+const proxySignatureLength = 32
+
+// svgContentType is never proxied: an svg image can carry scripts running on our domain
+const svgContentType = "image/svg+xml"
+
+type imageFetcher interface {
+	Get(ctx context.Context, rawUrl string) (*fetcher.Response, error)
+}
+
+// MakeProxyImageUrl returns the link to the image of another site served by our api, so the mini app
+// can show it and read it without CORS. The signature keeps the endpoint from being an open proxy.
+func MakeProxyImageUrl(r *http.Request, secret []byte, imageUrl string) string {
+	return usecase.GetApiUrl(r) + "/api/images/proxy/" + signProxyUrl(secret, imageUrl) + "/" +
+		base64.RawURLEncoding.EncodeToString([]byte(imageUrl))
+}
+
+func signProxyUrl(secret []byte, imageUrl string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(imageUrl))
+	return hex.EncodeToString(mac.Sum(nil))[:proxySignatureLength]
+}
+
+func MakeGetProxyImageHandler(imageFetcher imageFetcher, secret []byte) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		vars := mux.Vars(r)
+		imageUrl, err := base64.RawURLEncoding.DecodeString(vars["url_base64"])
+		if err != nil || !hmac.Equal([]byte(signProxyUrl(secret, string(imageUrl))), []byte(vars["signature"])) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      err,
+				},
+			}
+		}
+
+		resp, err := imageFetcher.Get(r.Context(), string(imageUrl))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "image not found",
+					Err:      err,
+				},
+			}
+		}
+		if resp.ContentType == svgContentType {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "unsupported_image",
+					Message:  "svg images are not supported",
+				},
+			}
+		}
+		return httputil.HandleResult{
+			Payload: httputil.Image{
+				ContentType: resp.ContentType,
+				Body:        io.NopCloser(bytes.NewReader(resp.Body)),
+			},
+			Type: httputil.ResponseTypeImage,
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package scrape
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/grulex/go-wishlist/fetcher"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/images"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
+	"net/http"
+	urlPkg "net/url"
+	"strings"
+)
+
+const (
+	maxUrlLength     = 2048
+	maxPreviewImages = 10
+)
+
+type scrapeService interface {
+	Scrape(ctx context.Context, url string) (*scrapePkg.Result, error)
+	Invalidate(ctx context.Context, url string) error
+}
+
+type requestJson struct {
+	Url string `json:"url"`
+	// Refresh drops the cached preview of the url
+	Refresh bool `json:"refresh"`
+}
+
+// MakeScrapeUsecase returns the preview of the page to prefill the wish form,
+// the images are proxied through the images endpoint
+func MakeScrapeUsecase(sService scrapeService, imageProxySecret []byte) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		_, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		request := requestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+		url, ok := parseUrl(request.Url)
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_url",
+					Message:  "url must be a http or https link",
+				},
+			}
+		}
+
+		if request.Refresh {
+			err := sService.Invalidate(r.Context(), url)
+			if err != nil && !errors.Is(err, scrapePkg.ErrInvalidUrl) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error invalidating scrape cache",
+						Err:     err,
+					},
+				}
+			}
+		}
+
+		result, err := sService.Scrape(r.Context(), url)
+		if errors.Is(err, scrapePkg.ErrInvalidUrl) || errors.Is(err, fetcher.ErrForbiddenAddress) ||
+			errors.Is(err, fetcher.ErrForbiddenScheme) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_url",
+					Message:  "url must be a http or https link",
+					Err:      err,
+				},
+			}
+		}
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "page_not_available",
+					Message:  "the page can't be read",
+					Err:      err,
+				},
+			}
+		}
+
+		title := result.Title
+		titleRunes := []rune(title)
+		if len(titleRunes) > productPkg.MaxTitleLength {
+			title = string(titleRunes[:productPkg.MaxTitleLength-3]) + "..."
+		}
+		previewImages := make([]string, 0, maxPreviewImages)
+		seen := make(map[string]bool, len(result.Images))
+		for _, imageUrl := range result.Images {
+			if len(previewImages) == maxPreviewImages {
+				break
+			}
+			if seen[imageUrl] {
+				continue
+			}
+			seen[imageUrl] = true
+			previewImages = append(previewImages, images.MakeProxyImageUrl(r, imageProxySecret, imageUrl))
+		}
+
+		payload := struct {
+			Preview types.ScrapePreview `json:"preview"`
+		}{
+			Preview: types.ScrapePreview{
+				Url:          result.Url,
+				Title:        title,
+				Description:  result.Description,
+				Images:       previewImages,
+				Price:        result.Price,
+				Brand:        result.Brand,
+				Availability: result.Availability,
+				SKU:          result.SKU,
+			},
+		}
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
+
+// parseUrl accepts the links without the scheme as https ones, like the bot does
+func parseUrl(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > maxUrlLength {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	url, err := urlPkg.Parse(raw)
+	if err != nil || (url.Scheme != "http" && url.Scheme != "https") || url.Hostname() == "" {
+		return "", false
+	}
+	return url.String(), true
+}
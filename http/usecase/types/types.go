@@ -3,10 +3,12 @@ package types
 import (
 	"github.com/bojanz/currency"
 	"github.com/grulex/go-wishlist/pkg/image"
+	"github.com/grulex/go-wishlist/pkg/notify"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	"github.com/grulex/go-wishlist/pkg/user"
 	"github.com/grulex/go-wishlist/pkg/wishlist"
 	"gopkg.in/guregu/null.v4"
+	"time"
 )
 
 type User struct {
@@ -14,20 +16,63 @@ type User struct {
 }
 
 type Wishlist struct {
-	ID           wishlist.ID `json:"id"`
-	IsDefault    bool        `json:"is_default"`
-	Title        string      `json:"title"`
-	Avatar       *Image      `json:"avatar,omitempty"`
-	Description  string      `json:"description"`
-	IsMyWishlist bool        `json:"is_my_wishlist"`
+	ID             wishlist.ID         `json:"id"`
+	IsDefault      bool                `json:"is_default"`
+	Title          string              `json:"title"`
+	Avatar         *Image              `json:"avatar,omitempty"`
+	Description    string              `json:"description"`
+	IsMyWishlist   bool                `json:"is_my_wishlist"`
+	IsArchived     bool                `json:"is_archived"`
+	Visibility     wishlist.Visibility `json:"visibility,omitempty"`
+	ShareToken     string              `json:"share_token,omitempty"`
+	IsSurpriseMode *bool               `json:"is_surprise_mode,omitempty"`
+	// OccasionDate is YYYY-MM-DD, the next occurrence is calculated from it for the yearly occasions
+	OccasionDate      *string `json:"occasion_date,omitempty"`
+	IsOccasionYearly  *bool   `json:"is_occasion_yearly,omitempty"`
+	NextOccasionDate  *string `json:"next_occasion_date,omitempty"`
+	DaysUntilOccasion *int    `json:"days_until_occasion,omitempty"`
 }
 
 type Item struct {
-	ID                    wishlist.ItemID `json:"id"`
-	IsBookingAvailable    bool            `json:"is_booking_available"`
-	IsBookedByCurrentUser bool            `json:"is_booked_by_current_user"`
-	IsBooked              bool            `json:"is_booked"`
-	Product               Product         `json:"product"`
+	ID                    wishlist.ItemID    `json:"id"`
+	IsBookingAvailable    bool               `json:"is_booking_available"`
+	IsBookedByCurrentUser bool               `json:"is_booked_by_current_user"`
+	IsBooked              bool               `json:"is_booked"`
+	Quantity              uint               `json:"quantity"`
+	Priority              wishlist.Priority  `json:"priority"`
+	Position              uint               `json:"position"`
+	BookedQuantity        uint               `json:"booked_quantity"`
+	BookedByCurrentUser   uint               `json:"booked_by_current_user"`
+	BookingExpiresAt      *time.Time         `json:"booking_expires_at,omitempty"`
+	Contributions         *ItemContributions `json:"contributions,omitempty"`
+	Product               Product            `json:"product"`
+}
+
+type Gift struct {
+	ID          wishlist.ItemID `json:"id"`
+	Product     Product         `json:"product"`
+	Givers      []Giver         `json:"givers"`
+	FulfilledAt time.Time       `json:"fulfilled_at"`
+}
+
+type Giver struct {
+	UserID   user.ID `json:"user_id"`
+	FullName string  `json:"full_name"`
+}
+
+type ItemContributions struct {
+	Total             currency.Amount  `json:"total"`
+	Progress          uint             `json:"progress"`
+	IsFullyCovered    bool             `json:"is_fully_covered"`
+	ContributorsCount int              `json:"contributors_count"`
+	CurrentUserAmount *currency.Amount `json:"current_user_amount,omitempty"`
+}
+
+type Contribution struct {
+	UserID    user.ID         `json:"user_id"`
+	FullName  string          `json:"full_name"`
+	Amount    currency.Amount `json:"amount"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type Product struct {
@@ -40,6 +85,18 @@ type Product struct {
 	Image       *Image           `json:"image,omitempty"`
 }
 
+// ScrapePreview prefills the wish form by the link, the images are proxied through the api
+type ScrapePreview struct {
+	Url          string           `json:"url"`
+	Title        string           `json:"title"`
+	Description  string           `json:"description"`
+	Images       []string         `json:"images"`
+	Price        *currency.Amount `json:"price,omitempty"`
+	Brand        string           `json:"brand,omitempty"`
+	Availability string           `json:"availability,omitempty"`
+	SKU          string           `json:"sku,omitempty"`
+}
+
 type Image struct {
 	ID    image.ID    `json:"id"`
 	Link  string      `json:"link,omitempty"`
@@ -54,5 +111,24 @@ type ImageSize struct {
 }
 
 type Subscribe struct {
-	ID wishlist.ID `json:"id"`
+	ID              wishlist.ID `json:"id"`
+	IsNotifyEnabled bool        `json:"is_notify_enabled"`
+}
+
+type NotificationPreferences struct {
+	Channel        *notify.Type              `json:"channel"`
+	Events         map[notify.EventKind]bool `json:"events"`
+	Delivery       notify.Delivery           `json:"delivery"`
+	DigestHour     int                       `json:"digest_hour"`
+	QuietHours     *QuietHours               `json:"quiet_hours"`
+	Timezone       string                    `json:"timezone"`
+	MutedWishlists []wishlist.ID             `json:"muted_wishlists"`
+	// Email is changed with the separate verification flow and ignored on update
+	Email           *string `json:"email"`
+	IsEmailVerified bool    `json:"is_email_verified"`
+}
+
+type QuietHours struct {
+	From int `json:"from"`
+	To   int `json:"to"`
 }
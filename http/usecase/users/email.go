@@ -0,0 +1,156 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/grulex/go-wishlist/http/httputil"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	"net/http"
+)
+
+type emailService interface {
+	SetEmail(ctx context.Context, id userPkg.ID, email string) (string, error)
+	VerifyEmail(ctx context.Context, id userPkg.ID, code string) error
+}
+
+type notifyService interface {
+	NotifyChannel(ctx context.Context, userID userPkg.ID, notifyType notify.Type, channelID string, key string, params ...any) error
+}
+
+type setEmailRequestJson struct {
+	Email string `json:"email"`
+}
+
+type verifyEmailRequestJson struct {
+	Code string `json:"code"`
+}
+
+func MakeSetEmailUsecase(uService emailService, nService notifyService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		request := setEmailRequestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+
+		code, err := uService.SetEmail(r.Context(), auth.UserID, request.Email)
+		if err != nil {
+			if errors.Is(err, userPkg.ErrInvalidEmail) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "invalid_email",
+						Message:  "invalid email",
+						Err:      err,
+					},
+				}
+			}
+			if errors.Is(err, userPkg.ErrEmailCodeTooSoon) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorTooManyRequests,
+						ErrorKey: "email_code_too_soon",
+						Message:  "verification code was sent recently, try again later",
+						Err:      err,
+					},
+				}
+			}
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error setting email",
+					Err:     err,
+				},
+			}
+		}
+
+		err = nService.NotifyChannel(r.Context(), auth.UserID, notify.TypeEmail, request.Email, "email_verification_code", code)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error sending verification code",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
+
+func MakeVerifyEmailUsecase(uService emailService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		request := verifyEmailRequestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+
+		err := uService.VerifyEmail(r.Context(), auth.UserID, request.Code)
+		if err != nil {
+			switch {
+			case errors.Is(err, userPkg.ErrEmailCodeInvalid):
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "invalid_code",
+						Message:  "invalid verification code",
+						Err:      err,
+					},
+				}
+			case errors.Is(err, userPkg.ErrEmailCodeExpired), errors.Is(err, userPkg.ErrEmailCodeAttempts):
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "code_expired",
+						Message:  "verification code expired, request a new one",
+						Err:      err,
+					},
+				}
+			}
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error verifying email",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/grulex/go-wishlist/http/httputil"
 	"github.com/grulex/go-wishlist/http/usecase"
 	"github.com/grulex/go-wishlist/http/usecase/types"
+	wishlistsUsecase "github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
@@ -69,7 +70,8 @@ func MakeGetProfileUsecase(subscribesService subscribeService, wService wishlist
 		subscribeAnswer := make([]types.Subscribe, len(subscribes))
 		for i, s := range subscribes {
 			subscribeAnswer[i] = types.Subscribe{
-				ID: s.WishlistID,
+				ID:              s.WishlistID,
+				IsNotifyEnabled: s.IsNotifyEnabled,
 			}
 		}
 
@@ -109,6 +111,7 @@ func MakeGetProfileUsecase(subscribesService subscribeService, wService wishlist
 			},
 			Subscribes: subscribeAnswer,
 		}
+		wishlistsUsecase.FillOccasion(&payload.DefaultWishlist, defaultWishlist)
 
 		return httputil.HandleResult{
 			Payload: payload,
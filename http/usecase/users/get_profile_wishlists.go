@@ -0,0 +1,89 @@
+package users
+
+import (
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	wishlistsUsecase "github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	"net/http"
+	"sort"
+)
+
+func MakeGetProfileWishlistsUsecase(wService wishlistService, iService imageService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		wishlists, err := wService.GetByUserID(r.Context(), auth.UserID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting wishlists",
+					Err:     err,
+				},
+			}
+		}
+		// the default one goes first, archived ones go last
+		sort.SliceStable(wishlists, func(i, j int) bool {
+			if wishlists[i].IsDefault != wishlists[j].IsDefault {
+				return wishlists[i].IsDefault
+			}
+			if wishlists[i].IsArchived != wishlists[j].IsArchived {
+				return !wishlists[i].IsArchived
+			}
+			return wishlists[i].CreatedAt.Before(wishlists[j].CreatedAt)
+		})
+
+		wishlistsAnswer := make([]types.Wishlist, 0, len(wishlists))
+		for _, wishlist := range wishlists {
+			var avatarAnswer *types.Image
+			if wishlist.Avatar != nil {
+				avatar, err := iService.Get(r.Context(), *wishlist.Avatar)
+				if err != nil {
+					return httputil.HandleResult{
+						Error: &httputil.HandleError{
+							Type:    httputil.ErrorInternal,
+							Message: "Error getting avatar",
+							Err:     err,
+						},
+					}
+				}
+				avatarAnswer = &types.Image{
+					ID:   *wishlist.Avatar,
+					Link: usecase.GetFileUrl(r, avatar.FileLink),
+				}
+			}
+			wishlistAnswer := types.Wishlist{
+				ID:           wishlist.ID,
+				IsDefault:    wishlist.IsDefault,
+				Title:        wishlist.Title,
+				Avatar:       avatarAnswer,
+				Description:  wishlist.Description,
+				IsMyWishlist: true,
+				IsArchived:   wishlist.IsArchived,
+			}
+			wishlistsUsecase.FillOccasion(&wishlistAnswer, wishlist)
+			wishlistsAnswer = append(wishlistsAnswer, wishlistAnswer)
+		}
+
+		payload := struct {
+			Wishlists []types.Wishlist `json:"wishlists"`
+		}{
+			Wishlists: wishlistsAnswer,
+		}
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package users
+
+import (
+	"context"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type giftService interface {
+	GetReceivedGifts(ctx context.Context, userID userPkg.ID) ([]*wishlistPkg.Gift, error)
+}
+
+type productService interface {
+	GetMany(ctx context.Context, ids []productPkg.ID) ([]*productPkg.Product, error)
+}
+
+func MakeGetReceivedGiftsUsecase(gService giftService, pService productService, uService userService, iService imageService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		gifts, err := gService.GetReceivedGifts(r.Context(), auth.UserID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting received gifts",
+					Err:     err,
+				},
+			}
+		}
+
+		productIDs := make([]productPkg.ID, 0, len(gifts))
+		for _, gift := range gifts {
+			productIDs = append(productIDs, gift.Item.ID.ProductID)
+		}
+		products, err := pService.GetMany(r.Context(), productIDs)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting products",
+					Err:     err,
+				},
+			}
+		}
+		productsMap := make(map[productPkg.ID]*productPkg.Product, len(products))
+		for _, product := range products {
+			productsMap[product.ID] = product
+		}
+
+		giverNames := make(map[userPkg.ID]string)
+		giftsAnswer := make([]types.Gift, 0, len(gifts))
+		for _, gift := range gifts {
+			product, ok := productsMap[gift.Item.ID.ProductID]
+			if !ok {
+				continue
+			}
+
+			givers := make([]types.Giver, 0, len(gift.Givers))
+			for _, giverID := range gift.Givers {
+				fullName, ok := giverNames[giverID]
+				if !ok {
+					giver, err := uService.Get(r.Context(), giverID)
+					if err != nil {
+						return httputil.HandleResult{
+							Error: &httputil.HandleError{
+								Type:    httputil.ErrorInternal,
+								Message: "Error getting giver",
+								Err:     err,
+							},
+						}
+					}
+					fullName = giver.FullName
+					giverNames[giverID] = fullName
+				}
+				givers = append(givers, types.Giver{
+					UserID:   giverID,
+					FullName: fullName,
+				})
+			}
+
+			var imageAnswer *types.Image
+			if product.ImageID != nil {
+				image, err := iService.Get(r.Context(), *product.ImageID)
+				if err != nil {
+					return httputil.HandleResult{
+						Error: &httputil.HandleError{
+							Type:    httputil.ErrorInternal,
+							Message: "Error getting image",
+							Err:     err,
+						},
+					}
+				}
+				imageAnswer = &types.Image{
+					ID:   *product.ImageID,
+					Link: usecase.GetFileUrl(r, image.FileLink),
+				}
+			}
+
+			giftsAnswer = append(giftsAnswer, types.Gift{
+				ID: gift.Item.ID,
+				Product: types.Product{
+					ID:          &product.ID,
+					Title:       product.Title,
+					PriceFrom:   product.Price,
+					Description: product.Description,
+					Url:         product.Url,
+					Image:       imageAnswer,
+				},
+				Givers:      givers,
+				FulfilledAt: *gift.Item.FulfilledAt,
+			})
+		}
+
+		payload := struct {
+			Gifts []types.Gift `json:"gifts"`
+		}{
+			Gifts: giftsAnswer,
+		}
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+	"time"
+)
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+	Update(ctx context.Context, user *userPkg.User) error
+}
+
+type subscribeSettingsService interface {
+	GetByUser(ctx context.Context, userID userPkg.ID) ([]*subscribePkg.Subscribe, error)
+	SetNotifyEnabled(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID, isEnabled bool) error
+}
+
+func MakeGetNotificationPreferencesUsecase(uService userService, sService subscribeSettingsService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		return makePreferencesResult(r.Context(), uService, sService, auth.UserID)
+	}
+}
+
+func MakeUpdateNotificationPreferencesUsecase(uService userService, sService subscribeSettingsService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		request := types.NotificationPreferences{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+		if err := validatePreferences(request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_preferences",
+					Message:  err.Error(),
+					Err:      err,
+				},
+			}
+		}
+
+		user, err := uService.Get(r.Context(), auth.UserID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting user",
+					Err:     err,
+				},
+			}
+		}
+
+		if request.Channel != nil {
+			user.NotifyType = request.Channel
+			if _, ok := user.GetNotifyChannelID(); !ok {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "channel_not_connected",
+						Message:  "notify channel is not connected",
+						Err:      userPkg.ErrNotifyChannelNotFound,
+					},
+				}
+			}
+		}
+		user.MutedNotifyEvents = nil
+		for kind, isEnabled := range request.Events {
+			if !isEnabled {
+				user.MutedNotifyEvents = append(user.MutedNotifyEvents, kind)
+			}
+		}
+		user.NotifyDelivery = request.Delivery
+		user.DigestHour = request.DigestHour
+		user.QuietHoursFrom, user.QuietHoursTo = nil, nil
+		if request.QuietHours != nil {
+			user.QuietHoursFrom = &request.QuietHours.From
+			user.QuietHoursTo = &request.QuietHours.To
+		}
+		user.Timezone = request.Timezone
+		if err = uService.Update(r.Context(), user); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error updating user",
+					Err:     err,
+				},
+			}
+		}
+
+		subscribes, err := sService.GetByUser(r.Context(), auth.UserID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting subscribes",
+					Err:     err,
+				},
+			}
+		}
+		muted := make(map[wishlistPkg.ID]bool, len(request.MutedWishlists))
+		for _, wishlistID := range request.MutedWishlists {
+			muted[wishlistID] = true
+		}
+		for _, subscribe := range subscribes {
+			isEnabled := !muted[subscribe.WishlistID]
+			if subscribe.IsNotifyEnabled == isEnabled {
+				continue
+			}
+			err = sService.SetNotifyEnabled(r.Context(), auth.UserID, subscribe.WishlistID, isEnabled)
+			if err != nil {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error updating subscribe",
+						Err:     err,
+					},
+				}
+			}
+		}
+
+		return makePreferencesResult(r.Context(), uService, sService, auth.UserID)
+	}
+}
+
+func validatePreferences(preferences types.NotificationPreferences) error {
+	for kind := range preferences.Events {
+		if !notify.IsValidEventKind(kind) {
+			return fmt.Errorf("unknown event kind %q", kind)
+		}
+	}
+	switch preferences.Delivery {
+	case notify.DeliveryInstant, notify.DeliveryDaily, notify.DeliveryWeekly:
+	default:
+		return fmt.Errorf("unknown delivery %q", preferences.Delivery)
+	}
+	if preferences.DigestHour < 0 || preferences.DigestHour > 23 {
+		return fmt.Errorf("digest hour must be between 0 and 23")
+	}
+	if preferences.QuietHours != nil {
+		if preferences.QuietHours.From < 0 || preferences.QuietHours.From > 23 ||
+			preferences.QuietHours.To < 0 || preferences.QuietHours.To > 23 {
+			return fmt.Errorf("quiet hours must be between 0 and 23")
+		}
+	}
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil || preferences.Timezone == "" {
+		return fmt.Errorf("unknown timezone %q", preferences.Timezone)
+	}
+	return nil
+}
+
+func makePreferencesResult(ctx context.Context, uService userService, sService subscribeSettingsService, userID userPkg.ID) httputil.HandleResult {
+	user, err := uService.Get(ctx, userID)
+	if err != nil {
+		return httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:    httputil.ErrorInternal,
+				Message: "Error getting user",
+				Err:     err,
+			},
+		}
+	}
+	subscribes, err := sService.GetByUser(ctx, userID)
+	if err != nil {
+		return httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:    httputil.ErrorInternal,
+				Message: "Error getting subscribes",
+				Err:     err,
+			},
+		}
+	}
+
+	events := make(map[notify.EventKind]bool, len(notify.EventKinds))
+	for _, kind := range notify.EventKinds {
+		events[kind] = user.IsNotifyEventEnabled(kind)
+	}
+	mutedWishlists := make([]wishlistPkg.ID, 0)
+	for _, subscribe := range subscribes {
+		if !subscribe.IsNotifyEnabled {
+			mutedWishlists = append(mutedWishlists, subscribe.WishlistID)
+		}
+	}
+	var quietHours *types.QuietHours
+	if user.QuietHoursFrom != nil && user.QuietHoursTo != nil {
+		quietHours = &types.QuietHours{
+			From: *user.QuietHoursFrom,
+			To:   *user.QuietHoursTo,
+		}
+	}
+
+	return httputil.HandleResult{
+		Payload: types.NotificationPreferences{
+			Channel:         user.NotifyType,
+			Events:          events,
+			Delivery:        user.NotifyDelivery,
+			DigestHour:      user.DigestHour,
+			QuietHours:      quietHours,
+			Timezone:        user.GetLocation().String(),
+			MutedWishlists:  mutedWishlists,
+			Email:           user.Email,
+			IsEmailVerified: user.IsEmailVerified,
+		},
+		Type: httputil.ResponseTypeJson,
+	}
+}
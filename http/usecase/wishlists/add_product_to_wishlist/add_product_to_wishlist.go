@@ -36,8 +36,10 @@ type imageService interface {
 }
 
 type requestJson struct {
-	Product            types.Product `json:"product"`
-	IsBookingAvailable bool          `json:"is_booking_available,omitempty"`
+	Product            types.Product        `json:"product"`
+	IsBookingAvailable bool                 `json:"is_booking_available,omitempty"`
+	Quantity           uint                 `json:"quantity,omitempty"`
+	Priority           wishlistPkg.Priority `json:"priority,omitempty"`
 }
 
 func MakeAddProductToWishlistUsecase(
@@ -87,6 +89,16 @@ func MakeAddProductToWishlistUsecase(
 			}
 		}
 
+		if request.Priority != "" && !request.Priority.IsValid() {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_priority",
+					Message:  "priority must be must_have or nice_to_have",
+				},
+			}
+		}
+
 		product := &productPkg.Product{
 			Title:       request.Product.Title,
 			Price:       request.Product.PriceFrom,
@@ -134,7 +146,7 @@ func MakeAddProductToWishlistUsecase(
 			}
 		}
 
-		return addItemToWishlist(r.Context(), wishlistID, product.ID, request.IsBookingAvailable, wService)
+		return addItemToWishlist(r.Context(), wishlistID, product.ID, request.IsBookingAvailable, request.Quantity, request.Priority, wService)
 	}
 }
 
@@ -143,6 +155,8 @@ func addItemToWishlist(
 	wishlistID string,
 	productID productPkg.ID,
 	isBookingAvailable bool,
+	quantity uint,
+	priority wishlistPkg.Priority,
 	wService wishlistService,
 ) httputil.HandleResult {
 	item := &wishlistPkg.Item{
@@ -151,7 +165,8 @@ func addItemToWishlist(
 			ProductID:  productID,
 		},
 		IsBookingAvailable: isBookingAvailable,
-		IsBookedBy:         nil,
+		Quantity:           quantity,
+		Priority:           priority,
 	}
 	if err := wService.AddWishlistItem(ctx, item); err != nil {
 		return httputil.HandleResult{
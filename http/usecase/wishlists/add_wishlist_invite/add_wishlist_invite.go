@@ -0,0 +1,102 @@
+package add_wishlist_invite
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	Invite(ctx context.Context, wishlistID wishlistPkg.ID, userID userPkg.ID) error
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+// MakeAddWishlistInviteUsecase lets the owner give a user read access to the wishlist, it is the only way
+// to share a private wishlist
+func MakeAddWishlistInviteUsecase(wService wishlistService, uService userService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		handleResult, valid := wishlists.IsValidWishlistAccess(r.Context(), wService, wishlistID, auth)
+		if !valid {
+			return handleResult
+		}
+
+		userID, ok := vars["userId"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		_, err := uService.Get(r.Context(), userPkg.ID(userID))
+		if err != nil {
+			if errors.Is(err, userPkg.ErrNotFound) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorNotFound,
+						ErrorKey: "user_not_found",
+						Message:  "user not found",
+						Err:      err,
+					},
+				}
+			}
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting user",
+					Err:     err,
+				},
+			}
+		}
+
+		err = wService.Invite(r.Context(), wishlistPkg.ID(wishlistID), userPkg.ID(userID))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error adding invite",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
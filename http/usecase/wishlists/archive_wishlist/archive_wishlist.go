@@ -0,0 +1,72 @@
+package archive_wishlist
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	Archive(ctx context.Context, id wishlistPkg.ID) error
+}
+
+func MakeArchiveWishlistUsecase(wService wishlistService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		handleResult, valid := wishlists.IsValidWishlistAccess(r.Context(), wService, wishlistID, auth)
+		if !valid {
+			return handleResult
+		}
+
+		err := wService.Archive(r.Context(), wishlistPkg.ID(wishlistID))
+		if err != nil {
+			if errors.Is(err, wishlistPkg.ErrDefaultWishlistRemoval) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "default_wishlist",
+						Message:  "default wishlist can't be archived, choose another default first",
+						Err:      err,
+					},
+				}
+			}
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error archiving wishlist",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
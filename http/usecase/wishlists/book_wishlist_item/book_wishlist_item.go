@@ -5,17 +5,19 @@ import (
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
 	"net/http"
+	"time"
 )
 
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
-	GetWishlistItem(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error)
-	BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID) error
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, quantity uint, expiresAt *time.Time) error
 }
 
 func MakeBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase {
@@ -43,7 +45,7 @@ func MakeBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase
 			}
 		}
 
-		handleResult, valid := isValidWishlist(r, wService, wishlistID)
+		_, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, wishlistID)
 		if !valid {
 			return handleResult
 		}
@@ -65,28 +67,82 @@ func MakeBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase
 			ProductID:  productPkg.ID(productId),
 		}
 
-		item, err := wService.GetWishlistItem(r.Context(), itemID)
-		if err != nil && !errors.Is(err, wishlistPkg.ErrItemNotFound) {
+		quantity, handleResult, valid := wishlists.ParseQuantity(r, 1)
+		if !valid {
+			return handleResult
+		}
+
+		expiresAt, handleResult, valid := wishlists.ParseBookingExpiry(r)
+		if !valid {
+			return handleResult
+		}
+
+		err := wService.BookItem(r.Context(), itemID, auth.UserID, quantity, expiresAt)
+		switch {
+		case errors.Is(err, wishlistPkg.ErrInvalidBookingExpiry):
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
-					Type:    httputil.ErrorInternal,
-					Message: "Error getting wishlist item",
-					Err:     err,
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_expires_in_days",
+					Message:  "booking expiry must be in the future",
+					Err:      err,
 				},
 			}
-		}
-		if item.IsBookedBy != nil && item.IsBookedBy != &auth.UserID {
+		case errors.Is(err, wishlistPkg.ErrItemNotFound):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "item not found",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrBookingNotAvailable):
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
 					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_booking_not_available",
+					Message:  "booking of the item isn't available",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemAlreadyBooked), errors.Is(err, wishlistPkg.ErrConcurrentUpdate):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorConflict,
 					ErrorKey: "forbidden_booked_by_another_user",
 					Message:  "item already booked by another user",
-					Err:      nil,
+					Err:      err,
 				},
 			}
-		}
-
-		if err := wService.BookItem(r.Context(), itemID, auth.UserID); err != nil {
+		case errors.Is(err, wishlistPkg.ErrNotEnoughUnits):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_not_enough_units",
+					Message:  "not enough units left to book",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemHasContributions):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_item_has_contributions",
+					Message:  "item is already being gifted by a group",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemFulfilled):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_item_fulfilled",
+					Message:  "item is already received",
+					Err:      err,
+				},
+			}
+		case err != nil:
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
 					Type:    httputil.ErrorInternal,
@@ -99,28 +155,3 @@ func MakeBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase
 		return httputil.HandleResult{}
 	}
 }
-
-func isValidWishlist(r *http.Request, wService wishlistService, wishlistID string) (httputil.HandleResult, bool) {
-	wishlist, err := wService.Get(r.Context(), wishlistPkg.ID(wishlistID))
-	if err != nil && !errors.Is(err, wishlistPkg.ErrNotFound) {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:    httputil.ErrorInternal,
-				Message: "Error getting wishlist",
-				Err:     err,
-			},
-		}, false
-	}
-	if wishlist == nil {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:     httputil.ErrorNotFound,
-				ErrorKey: "not_found",
-				Message:  "incorrect path parameter",
-				Err:      nil,
-			},
-		}, false
-	}
-
-	return httputil.HandleResult{}, true
-}
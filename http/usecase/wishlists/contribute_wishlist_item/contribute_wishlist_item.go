@@ -0,0 +1,197 @@
+package contribute_wishlist_item
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/bojanz/currency"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	Contribute(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, amount currency.Amount, price *currency.Amount) error
+}
+
+type productService interface {
+	Get(ctx context.Context, id productPkg.ID) (*productPkg.Product, error)
+}
+
+type requestJson struct {
+	Amount currency.Amount `json:"amount"`
+}
+
+func MakeContributeWishlistItemUsecase(wService wishlistService, pService productService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		wishlist, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, wishlistID)
+		if !valid {
+			return handleResult
+		}
+
+		productId, ok := vars["productId"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		itemID := wishlistPkg.ItemID{
+			WishlistID: wishlistPkg.ID(wishlistID),
+			ProductID:  productPkg.ID(productId),
+		}
+
+		product, err := pService.Get(r.Context(), itemID.ProductID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting product",
+					Err:     err,
+				},
+			}
+		}
+
+		if wishlist.UserID == auth.UserID {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_own_item",
+					Message:  "owner can't contribute to own item",
+				},
+			}
+		}
+
+		request := requestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+
+		err = wService.Contribute(r.Context(), itemID, auth.UserID, request.Amount, product.Price)
+		switch {
+		case errors.Is(err, wishlistPkg.ErrItemNotFound):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "item not found",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrBookingNotAvailable):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_booking_not_available",
+					Message:  "booking of the item isn't available",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemAlreadyBooked):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_booked_by_another_user",
+					Message:  "item already booked by another user",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemFullyCovered):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_fully_covered",
+					Message:  "item already fully covered by contributions",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemHasNoPrice):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "item_has_no_price",
+					Message:  "contributions need the product price",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrInvalidContribution):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_amount",
+					Message:  "amount must be positive",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrContributionCurrencyMismatch):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "currency_mismatch",
+					Message:  "amount must be in the currency of the product price",
+					Err:      err,
+				},
+			}
+		case errors.Is(err, wishlistPkg.ErrItemFulfilled):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_item_fulfilled",
+					Message:  "item is already received",
+					Err:      err,
+				},
+			}
+		case err != nil:
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error contributing to wishlist item",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
@@ -0,0 +1,144 @@
+package create_wishlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	filePkg "github.com/grulex/go-wishlist/pkg/file"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+type wishlistService interface {
+	Create(ctx context.Context, wishlist *wishlistPkg.Wishlist) error
+}
+
+type fileService interface {
+	UploadPhoto(ctx context.Context, reader io.Reader) ([]filePkg.ImageSize, error)
+}
+
+type imageService interface {
+	Create(ctx context.Context, image *imagePkg.Image) error
+}
+
+type requestJson struct {
+	Wishlist types.Wishlist `json:"wishlist"`
+}
+
+func MakeCreateWishlistUsecase(wService wishlistService, fService fileService, iService imageService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		request := requestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+		if strings.TrimSpace(request.Wishlist.Title) == "" {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "empty_title",
+					Message:  "wishlist title is required",
+				},
+			}
+		}
+
+		wishlist := &wishlistPkg.Wishlist{
+			UserID:         auth.UserID,
+			IsDefault:      request.Wishlist.IsDefault,
+			Title:          request.Wishlist.Title,
+			Description:    request.Wishlist.Description,
+			Visibility:     request.Wishlist.Visibility,
+			IsSurpriseMode: true,
+		}
+		if request.Wishlist.IsSurpriseMode != nil {
+			wishlist.IsSurpriseMode = *request.Wishlist.IsSurpriseMode
+		}
+		if request.Wishlist.OccasionDate != nil {
+			occasionDate, result, ok := wishlists.ParseOccasionDate(*request.Wishlist.OccasionDate)
+			if !ok {
+				return result
+			}
+			wishlist.OccasionDate = occasionDate
+		}
+		if request.Wishlist.IsOccasionYearly != nil {
+			wishlist.IsOccasionYearly = *request.Wishlist.IsOccasionYearly
+		}
+		if request.Wishlist.Avatar != nil {
+			if request.Wishlist.Avatar.ID != "" {
+				wishlist.Avatar = &request.Wishlist.Avatar.ID
+			} else {
+				newImage, result := wishlists.UploadBase64Image(r.Context(), fService, iService, request.Wishlist.Avatar.Src)
+				if result.Error != nil {
+					log.Printf("Error uploading image: %v\n", result.Error)
+					return result
+				}
+				wishlist.Avatar = &newImage.ID
+			}
+		}
+
+		err := wService.Create(r.Context(), wishlist)
+		if errors.Is(err, wishlistPkg.ErrInvalidVisibility) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_visibility",
+					Message:  "visibility must be one of public, link or private",
+					Err:      err,
+				},
+			}
+		}
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error creating wishlist",
+					Err:     err,
+				},
+			}
+		}
+
+		payload := struct {
+			Wishlist types.Wishlist `json:"wishlist"`
+		}{
+			Wishlist: types.Wishlist{
+				ID:             wishlist.ID,
+				IsDefault:      wishlist.IsDefault,
+				Title:          wishlist.Title,
+				Description:    wishlist.Description,
+				IsMyWishlist:   true,
+				Visibility:     wishlist.Visibility,
+				ShareToken:     wishlist.ShareToken,
+				IsSurpriseMode: &wishlist.IsSurpriseMode,
+			},
+		}
+		wishlists.FillOccasion(&payload.Wishlist, wishlist)
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package fulfill_wishlist_item
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	FulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error
+}
+
+func MakeFulfillWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		handleResult, valid := wishlists.IsValidWishlistAccess(r.Context(), wService, wishlistID, auth)
+		if !valid {
+			return handleResult
+		}
+
+		productId, ok := vars["productId"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		itemID := wishlistPkg.ItemID{
+			WishlistID: wishlistPkg.ID(wishlistID),
+			ProductID:  productPkg.ID(productId),
+		}
+
+		err := wService.FulfillItem(r.Context(), itemID)
+		switch {
+		case errors.Is(err, wishlistPkg.ErrItemNotFound):
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "item not found",
+					Err:      err,
+				},
+			}
+		case err != nil:
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error fulfilling wishlist item",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
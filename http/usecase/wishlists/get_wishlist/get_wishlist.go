@@ -3,11 +3,11 @@ package get_wishlist
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/http/httputil"
 	"github.com/grulex/go-wishlist/http/usecase"
 	"github.com/grulex/go-wishlist/http/usecase/types"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
@@ -18,6 +18,7 @@ import (
 
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
 }
 
 type subscribeService interface {
@@ -49,28 +50,13 @@ func MakeGetWishlistUsecase(sService subscribeService, wService wishlistService,
 			}
 		}
 
-		wishlist, err := wService.Get(r.Context(), wishlistPkg.ID(id))
-		if err != nil && !errors.Is(err, wishlistPkg.ErrNotFound) {
-			return httputil.HandleResult{
-				Error: &httputil.HandleError{
-					Type:    httputil.ErrorInternal,
-					Message: "Error getting wishlist",
-					Err:     err,
-				},
-			}
-		}
-
-		if wishlist == nil {
-			return httputil.HandleResult{
-				Error: &httputil.HandleError{
-					Type:     httputil.ErrorNotFound,
-					ErrorKey: "not_found",
-					Message:  fmt.Sprintf("wishlist with id %s not found", id),
-					Err:      err,
-				},
-			}
+		wishlist, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, id)
+		if !valid {
+			return handleResult
 		}
+		isMyWishlist := currentUserID != nil && *currentUserID == wishlist.UserID
 
+		var err error
 		var subscribe *subscribePkg.Subscribe
 		if currentUserID != nil {
 			subscribe, err = sService.Get(r.Context(), *currentUserID, wishlist.ID)
@@ -122,10 +108,18 @@ func MakeGetWishlistUsecase(sService subscribeService, wService wishlistService,
 				Description:  wishlist.Description,
 				IsDefault:    wishlist.IsDefault,
 				Avatar:       avatarAnswer,
-				IsMyWishlist: currentUserID != nil && *currentUserID == wishlist.UserID,
+				IsMyWishlist: isMyWishlist,
+				IsArchived:   wishlist.IsArchived,
+				Visibility:   wishlist.Visibility,
 			},
 			IsSubscribed: subscribe != nil,
 		}
+		wishlists.FillOccasion(&payload.Wishlist, wishlist)
+
+		if isMyWishlist {
+			payload.Wishlist.ShareToken = wishlist.ShareToken
+			payload.Wishlist.IsSurpriseMode = &wishlist.IsSurpriseMode
+		}
 
 		return httputil.HandleResult{
 			Payload: payload,
@@ -0,0 +1,101 @@
+package get_wishlist_invites
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+	"time"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	GetInvites(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Invite, error)
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+type invite struct {
+	UserID    userPkg.ID `json:"user_id"`
+	FullName  string     `json:"full_name"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func MakeGetWishlistInvitesUsecase(wService wishlistService, uService userService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		handleResult, valid := wishlists.IsValidWishlistAccess(r.Context(), wService, wishlistID, auth)
+		if !valid {
+			return handleResult
+		}
+
+		invites, err := wService.GetInvites(r.Context(), wishlistPkg.ID(wishlistID))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting invites",
+					Err:     err,
+				},
+			}
+		}
+
+		invitesAnswer := make([]invite, 0, len(invites))
+		for _, i := range invites {
+			user, err := uService.Get(r.Context(), i.UserID)
+			if err != nil {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error getting invited user",
+						Err:     err,
+					},
+				}
+			}
+			invitesAnswer = append(invitesAnswer, invite{
+				UserID:    i.UserID,
+				FullName:  user.FullName,
+				CreatedAt: i.CreatedAt,
+			})
+		}
+
+		payload := struct {
+			Invites []invite `json:"invites"`
+		}{
+			Invites: invitesAnswer,
+		}
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
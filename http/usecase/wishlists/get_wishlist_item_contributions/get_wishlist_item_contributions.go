@@ -0,0 +1,143 @@
+package get_wishlist_item_contributions
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Contributions, error)
+}
+
+type productService interface {
+	Get(ctx context.Context, id productPkg.ID) (*productPkg.Product, error)
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+func MakeGetWishlistItemContributionsUsecase(wService wishlistService, pService productService, uService userService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		var currentUserID *userPkg.ID
+		auth, ok := authPkg.FromContext(r.Context())
+		if ok {
+			currentUserID = &auth.UserID
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		wishlist, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, wishlistID)
+		if !valid {
+			return handleResult
+		}
+
+		productId, ok := vars["productId"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		itemID := wishlistPkg.ItemID{
+			WishlistID: wishlistPkg.ID(wishlistID),
+			ProductID:  productPkg.ID(productId),
+		}
+
+		product, err := pService.Get(r.Context(), itemID.ProductID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting product",
+					Err:     err,
+				},
+			}
+		}
+
+		if wishlist.IsBookingHiddenFor(currentUserID) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_surprise_mode",
+					Message:  "contributions are hidden from the owner in surprise mode",
+				},
+			}
+		}
+
+		contributions, err := wService.GetContributions(r.Context(), itemID)
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting contributions",
+					Err:     err,
+				},
+			}
+		}
+
+		// the owner sees how much of the wish is covered by the summary, but not who pledged
+		visibleContributions := contributions
+		if currentUserID != nil && *currentUserID == wishlist.UserID {
+			visibleContributions = nil
+		}
+		contributionsAnswer := make([]types.Contribution, 0, len(visibleContributions))
+		for _, c := range visibleContributions {
+			user, err := uService.Get(r.Context(), c.UserID)
+			if err != nil {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error getting contributor",
+						Err:     err,
+					},
+				}
+			}
+			contributionsAnswer = append(contributionsAnswer, types.Contribution{
+				UserID:    c.UserID,
+				FullName:  user.FullName,
+				Amount:    c.Amount,
+				CreatedAt: c.CreatedAt,
+			})
+		}
+
+		payload := struct {
+			Summary       *types.ItemContributions `json:"summary,omitempty"`
+			Contributions []types.Contribution     `json:"contributions"`
+		}{
+			Summary:       wishlists.MakeItemContributions(contributions, product.Price, currentUserID),
+			Contributions: contributionsAnswer,
+		}
+
+		return httputil.HandleResult{
+			Payload: payload,
+			Type:    httputil.ResponseTypeJson,
+		}
+	}
+}
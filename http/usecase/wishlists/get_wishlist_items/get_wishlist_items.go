@@ -6,6 +6,7 @@ import (
 	"github.com/grulex/go-wishlist/http/httputil"
 	"github.com/grulex/go-wishlist/http/usecase"
 	"github.com/grulex/go-wishlist/http/usecase/types"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
@@ -13,10 +14,15 @@ import (
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type wishlistService interface {
-	GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, limit, offset uint) ([]*wishlistPkg.Item, bool, error)
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, order wishlistPkg.ItemsOrder, limit, offset uint) ([]*wishlistPkg.Item, bool, error)
+	GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Contributions, error)
+	GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Bookings, error)
 }
 
 type productService interface {
@@ -27,6 +33,11 @@ type imageService interface {
 	GetMany(ctx context.Context, ids []imagePkg.ID) ([]*imagePkg.Image, error)
 }
 
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
 func MakeGetWishlistItemsUsecase(wService wishlistService, productService productService, iService imageService) httputil.HttpUseCase {
 	return func(r *http.Request) httputil.HandleResult {
 		var currentUserID *userPkg.ID
@@ -35,19 +46,29 @@ func MakeGetWishlistItemsUsecase(wService wishlistService, productService produc
 			currentUserID = &auth.UserID
 		}
 		var err error
-		var limit, offset int64
-		if len(r.URL.Query().Get("limit")) == 0 {
-			limit, err = strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64)
-			if err != nil {
-				limit = 100
+		var limit, offset uint64 = defaultLimit, 0
+		if len(r.URL.Query().Get("limit")) != 0 {
+			limit, err = strconv.ParseUint(r.URL.Query().Get("limit"), 10, 64)
+			if err != nil || limit == 0 || limit > maxLimit {
+				limit = defaultLimit
 			}
 		}
-		if len(r.URL.Query().Get("offset")) == 0 {
-			offset, err = strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+		if len(r.URL.Query().Get("offset")) != 0 {
+			offset, err = strconv.ParseUint(r.URL.Query().Get("offset"), 10, 64)
 			if err != nil {
 				offset = 0
 			}
 		}
+		order := wishlistPkg.ItemsOrder(r.URL.Query().Get("sort"))
+		if order != "" && !order.IsValid() {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_sort",
+					Message:  "unknown sort order of items",
+				},
+			}
+		}
 
 		vars := mux.Vars(r)
 		id, ok := vars["id"]
@@ -62,7 +83,12 @@ func MakeGetWishlistItemsUsecase(wService wishlistService, productService produc
 			}
 		}
 
-		items, hasMore, err := wService.GetWishlistItems(r.Context(), wishlistPkg.ID(id), uint(limit), uint(offset))
+		wishlist, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, id)
+		if !valid {
+			return handleResult
+		}
+
+		items, hasMore, err := wService.GetWishlistItems(r.Context(), wishlistPkg.ID(id), order, uint(limit), uint(offset))
 		if err != nil {
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
@@ -73,6 +99,36 @@ func MakeGetWishlistItemsUsecase(wService wishlistService, productService produc
 			}
 		}
 
+		contributions, err := wService.GetContributionsByWishlist(r.Context(), wishlistPkg.ID(id))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting contributions",
+					Err:     err,
+				},
+			}
+		}
+		contributionsMap := make(map[productPkg.ID]wishlistPkg.Contributions)
+		for _, c := range contributions {
+			contributionsMap[c.ItemID.ProductID] = append(contributionsMap[c.ItemID.ProductID], c)
+		}
+
+		bookings, err := wService.GetBookingsByWishlist(r.Context(), wishlistPkg.ID(id))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error getting bookings",
+					Err:     err,
+				},
+			}
+		}
+		bookingsMap := make(map[productPkg.ID]wishlistPkg.Bookings)
+		for _, b := range bookings {
+			bookingsMap[b.ItemID.ProductID] = append(bookingsMap[b.ItemID.ProductID], b)
+		}
+
 		ids := make([]productPkg.ID, len(items))
 		for i, item := range items {
 			ids[i] = item.ID.ProductID
@@ -114,12 +170,23 @@ func MakeGetWishlistItemsUsecase(wService wishlistService, productService produc
 			imagesMap[image.ID] = image
 		}
 
+		isBookingHidden := wishlist.IsBookingHiddenFor(currentUserID)
 		var resultItems []types.Item
 		for _, item := range items {
 			product := productsMap[item.ID.ProductID]
-			isBookedByCurrentUser := currentUserID != nil &&
-				item.IsBookedBy != nil &&
-				*item.IsBookedBy == *currentUserID
+			itemBookings := bookingsMap[item.ID.ProductID]
+			var bookedByCurrentUser uint
+			var bookingExpiresAt *time.Time
+			if currentUserID != nil {
+				if booking := itemBookings.GetByUser(*currentUserID); booking != nil {
+					bookedByCurrentUser = booking.Quantity
+					bookingExpiresAt = booking.ExpiresAt
+				}
+			}
+			var bookedQuantity uint
+			if !isBookingHidden {
+				bookedQuantity = itemBookings.Booked()
+			}
 			var resImage *types.Image
 			if productsMap[item.ID.ProductID].ImageID != nil {
 				image := imagesMap[*product.ImageID]
@@ -139,11 +206,22 @@ func MakeGetWishlistItemsUsecase(wService wishlistService, productService produc
 					Sizes: sizes,
 				}
 			}
+			var itemContributions *types.ItemContributions
+			if !isBookingHidden {
+				itemContributions = wishlists.MakeItemContributions(contributionsMap[item.ID.ProductID], product.Price, currentUserID)
+			}
 			resultItems = append(resultItems, types.Item{
 				ID:                    item.ID,
 				IsBookingAvailable:    item.IsBookingAvailable,
-				IsBookedByCurrentUser: isBookedByCurrentUser,
-				IsBooked:              item.IsBookedBy != nil,
+				IsBookedByCurrentUser: bookedByCurrentUser > 0,
+				IsBooked:              !isBookingHidden && item.Remaining(itemBookings) == 0,
+				Quantity:              item.Quantity,
+				Priority:              item.Priority,
+				Position:              item.Position,
+				BookedQuantity:        bookedQuantity,
+				BookedByCurrentUser:   bookedByCurrentUser,
+				BookingExpiresAt:      bookingExpiresAt,
+				Contributions:         itemContributions,
 				Product: types.Product{
 					ID:          &item.ID.ProductID,
 					Title:       productsMap[item.ID.ProductID].Title,
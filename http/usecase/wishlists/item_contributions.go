@@ -0,0 +1,37 @@
+package wishlists
+
+import (
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+// MakeItemContributions summarizes the pledges of the item, it returns nil if there is nothing to show
+func MakeItemContributions(contributions wishlistPkg.Contributions, price *currency.Amount, currentUserID *userPkg.ID) *types.ItemContributions {
+	if len(contributions) == 0 || price == nil || !price.IsPositive() {
+		return nil
+	}
+	total, err := contributions.Total(price.CurrencyCode())
+	if err != nil {
+		return nil
+	}
+	progress, err := contributions.Progress(*price)
+	if err != nil {
+		return nil
+	}
+
+	result := &types.ItemContributions{
+		Total:             total,
+		Progress:          progress,
+		IsFullyCovered:    progress >= 100,
+		ContributorsCount: len(contributions),
+	}
+	for _, c := range contributions {
+		if currentUserID != nil && c.UserID == *currentUserID {
+			amount := c.Amount
+			result.CurrentUserAmount = &amount
+		}
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package wishlists
+
+import (
+	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/types"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const occasionDateLayout = "2006-01-02"
+
+// ParseOccasionDate reads the occasion date in the YYYY-MM-DD format, an empty string means no occasion
+func ParseOccasionDate(raw string) (*time.Time, httputil.HandleResult, bool) {
+	if raw == "" {
+		return nil, httputil.HandleResult{}, true
+	}
+	date, err := time.Parse(occasionDateLayout, raw)
+	if err != nil {
+		return nil, httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:     httputil.ErrorBadData,
+				ErrorKey: "invalid_occasion_date",
+				Message:  "occasion_date must be a date in the YYYY-MM-DD format",
+				Err:      err,
+			},
+		}, false
+	}
+	return &date, httputil.HandleResult{}, true
+}
+
+// FillOccasion sets the occasion fields of the response, the next occurrence is counted from now
+func FillOccasion(response *types.Wishlist, wishlist *wishlistPkg.Wishlist) {
+	if wishlist.OccasionDate == nil {
+		return
+	}
+	occasionDate := wishlist.OccasionDate.Format(occasionDateLayout)
+	isYearly := wishlist.IsOccasionYearly
+	response.OccasionDate = &occasionDate
+	response.IsOccasionYearly = &isYearly
+
+	now := time.Now()
+	next := wishlist.NextOccasion(now)
+	if next == nil {
+		return
+	}
+	nextDate := next.Format(occasionDateLayout)
+	daysLeft := wishlistPkg.DaysUntil(now, *next)
+	response.NextOccasionDate = &nextDate
+	response.DaysUntilOccasion = &daysLeft
+}
@@ -2,9 +2,9 @@ package subscribe_wishlist
 
 import (
 	"context"
-	"errors"
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
@@ -13,6 +13,8 @@ import (
 
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+	AcceptInvite(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID, token string) error
 }
 
 type subscribeService interface {
@@ -44,12 +46,23 @@ func MakeSubscribeWishlistUsecase(wService wishlistService, sService subscribeSe
 			}
 		}
 
-		handleResult, valid := isValidWishlist(r, wService, wishlistID)
+		wishlist, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, wishlistID)
 		if !valid {
 			return handleResult
 		}
 
-		err := sService.Subscribe(r.Context(), auth.UserID, wishlistPkg.ID(wishlistID))
+		err := wService.AcceptInvite(r.Context(), wishlist, auth.UserID, r.URL.Query().Get("token"))
+		if err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error accepting wishlist invite",
+					Err:     err,
+				},
+			}
+		}
+
+		err = sService.Subscribe(r.Context(), auth.UserID, wishlistPkg.ID(wishlistID))
 		if err != nil {
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
@@ -63,28 +76,3 @@ func MakeSubscribeWishlistUsecase(wService wishlistService, sService subscribeSe
 		return httputil.HandleResult{}
 	}
 }
-
-func isValidWishlist(r *http.Request, wService wishlistService, wishlistID string) (httputil.HandleResult, bool) {
-	wishlist, err := wService.Get(r.Context(), wishlistPkg.ID(wishlistID))
-	if err != nil && !errors.Is(err, wishlistPkg.ErrNotFound) {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:    httputil.ErrorInternal,
-				Message: "Error getting wishlist",
-				Err:     err,
-			},
-		}, false
-	}
-	if wishlist == nil {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:     httputil.ErrorNotFound,
-				ErrorKey: "not_found",
-				Message:  "incorrect path parameter",
-				Err:      nil,
-			},
-		}, false
-	}
-
-	return httputil.HandleResult{}, true
-}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/http/httputil"
+	"github.com/grulex/go-wishlist/http/usecase/wishlists"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	"github.com/grulex/go-wishlist/pkg/user"
@@ -14,7 +15,8 @@ import (
 
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
-	UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID) error
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *user.ID, token string) (bool, error)
+	UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, quantity uint) error
 }
 
 func MakeUnBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCase {
@@ -42,7 +44,7 @@ func MakeUnBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCas
 			}
 		}
 
-		handleResult, valid := isValidWishlist(r, wService, wishlistID)
+		_, handleResult, valid := wishlists.IsValidWishlistReadAccess(r, wService, wishlistID)
 		if !valid {
 			return handleResult
 		}
@@ -64,17 +66,24 @@ func MakeUnBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCas
 			ProductID:  productPkg.ID(productId),
 		}
 
-		if err := wService.UnBookItem(r.Context(), itemID, auth.UserID); err != nil {
-			if errors.Is(err, wishlistPkg.ErrItemBookedByAnotherUser) {
-				return httputil.HandleResult{
-					Error: &httputil.HandleError{
-						Type:     httputil.ErrorForbidden,
-						ErrorKey: "forbidden_booked_by_another_user",
-						Message:  "item booked by another user",
-						Err:      nil,
-					},
-				}
+		// without the quantity the whole booking is released
+		quantity, handleResult, valid := wishlists.ParseQuantity(r, 0)
+		if !valid {
+			return handleResult
+		}
+
+		err := wService.UnBookItem(r.Context(), itemID, auth.UserID, quantity)
+		if errors.Is(err, wishlistPkg.ErrItemFulfilled) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_item_fulfilled",
+					Message:  "item is already received",
+					Err:      err,
+				},
 			}
+		}
+		if err != nil {
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
 					Type:    httputil.ErrorInternal,
@@ -87,28 +96,3 @@ func MakeUnBookWishlistItemUsecase(wService wishlistService) httputil.HttpUseCas
 		return httputil.HandleResult{}
 	}
 }
-
-func isValidWishlist(r *http.Request, wService wishlistService, wishlistID string) (httputil.HandleResult, bool) {
-	wishlist, err := wService.Get(r.Context(), wishlistPkg.ID(wishlistID))
-	if err != nil && !errors.Is(err, wishlistPkg.ErrNotFound) {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:    httputil.ErrorInternal,
-				Message: "Error getting wishlist",
-				Err:     err,
-			},
-		}, false
-	}
-	if wishlist == nil {
-		return httputil.HandleResult{
-			Error: &httputil.HandleError{
-				Type:     httputil.ErrorNotFound,
-				ErrorKey: "not_found",
-				Message:  "incorrect path parameter",
-				Err:      nil,
-			},
-		}, false
-	}
-
-	return httputil.HandleResult{}, true
-}
@@ -0,0 +1,83 @@
+package update_subscribe_wishlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/grulex/go-wishlist/http/httputil"
+	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+)
+
+type subscribeService interface {
+	SetNotifyEnabled(ctx context.Context, userID userPkg.ID, wishlistID wishlistPkg.ID, isEnabled bool) error
+}
+
+type requestJson struct {
+	IsNotifyEnabled bool `json:"is_notify_enabled"`
+}
+
+func MakeUpdateSubscribeWishlistUsecase(sService subscribeService) httputil.HttpUseCase {
+	return func(r *http.Request) httputil.HandleResult {
+		auth, ok := authPkg.FromContext(r.Context())
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Message: "Unauthorized",
+					Type:    httputil.ErrorBadAuth,
+				},
+			}
+		}
+
+		vars := mux.Vars(r)
+		wishlistID, ok := vars["id"]
+		if !ok {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorNotFound,
+					ErrorKey: "not_found",
+					Message:  "incorrect path parameter",
+					Err:      nil,
+				},
+			}
+		}
+
+		request := requestJson{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorBadData,
+					Message: "invalid json body",
+					Err:     err,
+				},
+			}
+		}
+
+		err := sService.SetNotifyEnabled(r.Context(), auth.UserID, wishlistPkg.ID(wishlistID), request.IsNotifyEnabled)
+		if err != nil {
+			if errors.Is(err, subscribePkg.ErrNotFound) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorNotFound,
+						ErrorKey: "not_subscribed",
+						Message:  "you are not subscribed to this wishlist",
+						Err:      err,
+					},
+				}
+			}
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error updating subscribe",
+					Err:     err,
+				},
+			}
+		}
+
+		return httputil.HandleResult{}
+	}
+}
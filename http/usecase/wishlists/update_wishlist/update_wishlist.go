@@ -97,8 +97,33 @@ func MakeUpdateWishlistUsecase(wService wishlistService, fService fileService, i
 
 		wishlist.Title = request.Wishlist.Title
 		wishlist.Description = request.Wishlist.Description
-		wishlist.IsDefault = request.Wishlist.IsDefault
+		if request.Wishlist.Visibility != "" {
+			wishlist.Visibility = request.Wishlist.Visibility
+		}
+		if request.Wishlist.IsSurpriseMode != nil {
+			wishlist.IsSurpriseMode = *request.Wishlist.IsSurpriseMode
+		}
+		if request.Wishlist.OccasionDate != nil {
+			occasionDate, result, ok := wishlists.ParseOccasionDate(*request.Wishlist.OccasionDate)
+			if !ok {
+				return result
+			}
+			wishlist.OccasionDate = occasionDate
+		}
+		if request.Wishlist.IsOccasionYearly != nil {
+			wishlist.IsOccasionYearly = *request.Wishlist.IsOccasionYearly
+		}
 		err = wService.Update(r.Context(), wishlist)
+		if errors.Is(err, wishlistPkg.ErrInvalidVisibility) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorBadData,
+					ErrorKey: "invalid_visibility",
+					Message:  "visibility must be one of public, link or private",
+					Err:      err,
+				},
+			}
+		}
 		if err != nil {
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
@@ -3,6 +3,7 @@ package update_wishlist_item
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/gorilla/mux"
 	"github.com/grulex/go-wishlist/http/httputil"
 	"github.com/grulex/go-wishlist/http/usecase/types"
@@ -18,14 +19,18 @@ import (
 )
 
 type requestJson struct {
-	Product            types.Product `json:"product"`
-	IsBookingAvailable bool          `json:"is_booking_available,omitempty"`
+	Product            types.Product        `json:"product"`
+	IsBookingAvailable bool                 `json:"is_booking_available,omitempty"`
+	Quantity           uint                 `json:"quantity,omitempty"`
+	Priority           wishlistPkg.Priority `json:"priority,omitempty"`
 }
 
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
 	SetBookingAvailabilityForItem(ctx context.Context, itemID wishlistPkg.ItemID, isAvailable bool) error
 	GetWishlistItem(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error)
+	SetItemQuantity(ctx context.Context, itemID wishlistPkg.ItemID, quantity uint) error
+	SetItemPriority(ctx context.Context, itemID wishlistPkg.ItemID, priority wishlistPkg.Priority) error
 }
 
 type productService interface {
@@ -104,7 +109,18 @@ func MakeUpdateWishlistItemUsecase(
 			}
 		}
 		item.IsBookingAvailable = jsonRequest.IsBookingAvailable
-		if err := wService.SetBookingAvailabilityForItem(r.Context(), itemID, jsonRequest.IsBookingAvailable); err != nil {
+		err = wService.SetBookingAvailabilityForItem(r.Context(), itemID, jsonRequest.IsBookingAvailable)
+		if errors.Is(err, wishlistPkg.ErrItemFulfilled) {
+			return httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:     httputil.ErrorForbidden,
+					ErrorKey: "forbidden_item_fulfilled",
+					Message:  "item is already received",
+					Err:      err,
+				},
+			}
+		}
+		if err != nil {
 			return httputil.HandleResult{
 				Error: &httputil.HandleError{
 					Type:    httputil.ErrorInternal,
@@ -114,6 +130,52 @@ func MakeUpdateWishlistItemUsecase(
 			}
 		}
 
+		if jsonRequest.Quantity > 0 {
+			err = wService.SetItemQuantity(r.Context(), itemID, jsonRequest.Quantity)
+			if errors.Is(err, wishlistPkg.ErrQuantityBelowBooked) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "quantity_below_booked",
+						Message:  "quantity can't be less than the units already booked",
+						Err:      err,
+					},
+				}
+			}
+			if err != nil {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error setting item quantity",
+						Err:     err,
+					},
+				}
+			}
+		}
+
+		if jsonRequest.Priority != "" {
+			err = wService.SetItemPriority(r.Context(), itemID, jsonRequest.Priority)
+			if errors.Is(err, wishlistPkg.ErrInvalidPriority) {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:     httputil.ErrorBadData,
+						ErrorKey: "invalid_priority",
+						Message:  "priority must be must_have or nice_to_have",
+						Err:      err,
+					},
+				}
+			}
+			if err != nil {
+				return httputil.HandleResult{
+					Error: &httputil.HandleError{
+						Type:    httputil.ErrorInternal,
+						Message: "Error setting item priority",
+						Err:     err,
+					},
+				}
+			}
+		}
+
 		product, err := pService.Get(r.Context(), productPkg.ID(productID))
 		if err != nil {
 			return httputil.HandleResult{
@@ -3,11 +3,19 @@ package wishlists
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/grulex/go-wishlist/http/httputil"
 	authPkg "github.com/grulex/go-wishlist/pkg/auth"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"net/http"
+	"strconv"
+	"time"
 )
 
+// maxBookingExpiryDays limits how long a booking with an expiry may last
+const maxBookingExpiryDays = 365
+
 type wishlistService interface {
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
 }
@@ -46,3 +54,94 @@ func IsValidWishlistAccess(ctx context.Context, wService wishlistService, wishli
 
 	return httputil.HandleResult{}, true
 }
+
+type wishlistReadService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *userPkg.ID, token string) (bool, error)
+}
+
+// IsValidWishlistReadAccess checks the visibility of the wishlist for the current user, the share token is taken
+// from the "token" query parameter. Hidden wishlists look like missing ones.
+func IsValidWishlistReadAccess(r *http.Request, wService wishlistReadService, wishlistID string) (*wishlistPkg.Wishlist, httputil.HandleResult, bool) {
+	wishlist, err := wService.Get(r.Context(), wishlistPkg.ID(wishlistID))
+	if err != nil && !errors.Is(err, wishlistPkg.ErrNotFound) {
+		return nil, httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:    httputil.ErrorInternal,
+				Message: "Error getting wishlist",
+				Err:     err,
+			},
+		}, false
+	}
+
+	var currentUserID *userPkg.ID
+	if auth, ok := authPkg.FromContext(r.Context()); ok {
+		currentUserID = &auth.UserID
+	}
+	canRead := false
+	if wishlist != nil {
+		canRead, err = wService.CanRead(r.Context(), wishlist, currentUserID, r.URL.Query().Get("token"))
+		if err != nil {
+			return nil, httputil.HandleResult{
+				Error: &httputil.HandleError{
+					Type:    httputil.ErrorInternal,
+					Message: "Error checking wishlist access",
+					Err:     err,
+				},
+			}, false
+		}
+	}
+	if !canRead {
+		return nil, httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:     httputil.ErrorNotFound,
+				ErrorKey: "not_found",
+				Message:  fmt.Sprintf("wishlist with id %s not found", wishlistID),
+				Err:      nil,
+			},
+		}, false
+	}
+
+	return wishlist, httputil.HandleResult{}, true
+}
+
+// ParseQuantity reads the optional quantity query parameter, defaultQuantity is used when it's absent
+func ParseQuantity(r *http.Request, defaultQuantity uint) (uint, httputil.HandleResult, bool) {
+	rawQuantity := r.URL.Query().Get("quantity")
+	if rawQuantity == "" {
+		return defaultQuantity, httputil.HandleResult{}, true
+	}
+	quantity, err := strconv.ParseUint(rawQuantity, 10, 32)
+	if err != nil || quantity == 0 {
+		return 0, httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:     httputil.ErrorBadData,
+				ErrorKey: "invalid_quantity",
+				Message:  "quantity must be a positive number",
+				Err:      err,
+			},
+		}, false
+	}
+	return uint(quantity), httputil.HandleResult{}, true
+}
+
+// ParseBookingExpiry reads the optional expires_in_days query parameter, nil means the booking never expires
+func ParseBookingExpiry(r *http.Request) (*time.Time, httputil.HandleResult, bool) {
+	rawDays := r.URL.Query().Get("expires_in_days")
+	if rawDays == "" {
+		return nil, httputil.HandleResult{}, true
+	}
+	days, err := strconv.ParseUint(rawDays, 10, 16)
+	if err != nil || days == 0 || days > maxBookingExpiryDays {
+		return nil, httputil.HandleResult{
+			Error: &httputil.HandleError{
+				Type:     httputil.ErrorBadData,
+				ErrorKey: "invalid_expires_in_days",
+				Message:  fmt.Sprintf("expires_in_days must be between 1 and %d", maxBookingExpiryDays),
+				Err:      err,
+			},
+		}, false
+	}
+	expiresAt := time.Now().Add(time.Duration(days) * 24 * time.Hour)
+	return &expiresAt, httputil.HandleResult{}, true
+}
@@ -0,0 +1,32 @@
+package miniapp
+
+import (
+	"encoding/base64"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+func MakeLinkToItem(miniAppUrl string, wishlistID wishlistPkg.ID, productID productPkg.ID) string {
+	miniAppInternalRoute := "/wishlists/" + string(wishlistID) + "/items/" + string(productID)
+
+	queryBase64 := base64.StdEncoding.EncodeToString([]byte(miniAppInternalRoute))
+	return miniAppUrl + "?startapp=-" + queryBase64
+}
+
+func MakeLinkToWishlist(miniAppUrl string, wishlistID wishlistPkg.ID) string {
+	return miniAppUrl + "?startapp=" + string(wishlistID)
+}
+
+func MakeSharedLinkToWishlist(miniAppUrl string, wishlistID wishlistPkg.ID, shareToken string) string {
+	miniAppInternalRoute := "/wishlists/" + string(wishlistID) + "?token=" + shareToken
+
+	queryBase64 := base64.StdEncoding.EncodeToString([]byte(miniAppInternalRoute))
+	return miniAppUrl + "?startapp=-" + queryBase64
+}
+
+func MakeLinkToGifts(miniAppUrl string) string {
+	miniAppInternalRoute := "/profile/gifts"
+
+	queryBase64 := base64.StdEncoding.EncodeToString([]byte(miniAppInternalRoute))
+	return miniAppUrl + "?startapp=-" + queryBase64
+}
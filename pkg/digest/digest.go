@@ -0,0 +1,30 @@
+package digest
+
+import (
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/pkg/product"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+type ID string
+type EntryType string
+
+const (
+	EntryTypeNewItem      EntryType = "new_item"
+	EntryTypeRemovedItem  EntryType = "removed_item"
+	EntryTypePriceChanged EntryType = "price_changed"
+)
+
+// Entry is a change in a followed wishlist waiting to be sent to the user in the next digest
+type Entry struct {
+	ID         ID
+	UserID     user.ID
+	WishlistID wishlist.ID
+	ProductID  product.ID
+	Type       EntryType
+	OldPrice   *currency.Amount
+	NewPrice   *currency.Amount
+	CreatedAt  time.Time
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"github.com/google/uuid"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	"time"
+)
+
+type storage interface {
+	Add(ctx context.Context, entry *digestPkg.Entry) error
+	GetUserIDs(ctx context.Context) ([]userPkg.ID, error)
+	GetByUser(ctx context.Context, userID userPkg.ID) ([]*digestPkg.Entry, error)
+	DeleteByUser(ctx context.Context, userID userPkg.ID, before time.Time) error
+}
+
+type Service struct {
+	storage storage
+}
+
+func NewDigestService(storage storage) *Service {
+	return &Service{
+		storage: storage,
+	}
+}
+
+func (s *Service) Add(ctx context.Context, entry *digestPkg.Entry) error {
+	if entry.ID == "" {
+		entry.ID = digestPkg.ID(uuid.NewString())
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now().UTC()
+	}
+	return s.storage.Add(ctx, entry)
+}
+
+// GetUserIDs returns users having pending digest entries
+func (s *Service) GetUserIDs(ctx context.Context) ([]userPkg.ID, error) {
+	return s.storage.GetUserIDs(ctx)
+}
+
+func (s *Service) GetByUser(ctx context.Context, userID userPkg.ID) ([]*digestPkg.Entry, error) {
+	return s.storage.GetByUser(ctx, userID)
+}
+
+// DeleteByUser removes entries created before the given time, so entries added while the digest was sent are kept
+func (s *Service) DeleteByUser(ctx context.Context, userID userPkg.ID, before time.Time) error {
+	return s.storage.DeleteByUser(ctx, userID, before)
+}
@@ -0,0 +1,63 @@
+package inmemory
+
+import (
+	"context"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"sync"
+	"time"
+)
+
+type Storage struct {
+	entries map[user.ID][]*digestPkg.Entry
+	lock    *sync.RWMutex
+}
+
+func NewDigestInMemory() *Storage {
+	return &Storage{
+		entries: map[user.ID][]*digestPkg.Entry{},
+		lock:    &sync.RWMutex{},
+	}
+}
+
+func (s *Storage) Add(_ context.Context, entry *digestPkg.Entry) error {
+	s.lock.Lock()
+	s.entries[entry.UserID] = append(s.entries[entry.UserID], entry)
+	s.lock.Unlock()
+	return nil
+}
+
+func (s *Storage) GetUserIDs(_ context.Context) ([]user.ID, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	userIDs := make([]user.ID, 0, len(s.entries))
+	for userID := range s.entries {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func (s *Storage) GetByUser(_ context.Context, userID user.ID) ([]*digestPkg.Entry, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	entries := make([]*digestPkg.Entry, len(s.entries[userID]))
+	copy(entries, s.entries[userID])
+	return entries, nil
+}
+
+func (s *Storage) DeleteByUser(_ context.Context, userID user.ID, before time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var kept []*digestPkg.Entry
+	for _, entry := range s.entries[userID] {
+		if !entry.CreatedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.entries, userID)
+		return nil
+	}
+	s.entries[userID] = kept
+	return nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"github.com/bojanz/currency"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
+	"github.com/grulex/go-wishlist/pkg/product"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type entryPersistent struct {
+	ID         string           `db:"id"`
+	UserID     string           `db:"user_id"`
+	WishlistID string           `db:"wishlist_id"`
+	ProductID  string           `db:"product_id"`
+	Type       string           `db:"type"`
+	OldPrice   *currency.Amount `db:"old_price"`
+	NewPrice   *currency.Amount `db:"new_price"`
+	CreatedAt  time.Time        `db:"created_at"`
+}
+
+func (p entryPersistent) ToEntry() *digestPkg.Entry {
+	return &digestPkg.Entry{
+		ID:         digestPkg.ID(p.ID),
+		UserID:     user.ID(p.UserID),
+		WishlistID: wishlist.ID(p.WishlistID),
+		ProductID:  product.ID(p.ProductID),
+		Type:       digestPkg.EntryType(p.Type),
+		OldPrice:   p.OldPrice,
+		NewPrice:   p.NewPrice,
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+func NewDigestStorage(db *sqlx.DB) *Storage {
+	return &Storage{db: db}
+}
+
+func (s *Storage) Add(ctx context.Context, entry *digestPkg.Entry) error {
+	query := `INSERT INTO digest_entry (
+		id,
+		user_id,
+		wishlist_id,
+		product_id,
+		type,
+		old_price,
+		new_price,
+		created_at
+	) VALUES (
+		:id,
+		:user_id,
+		:wishlist_id,
+		:product_id,
+		:type,
+		:old_price,
+		:new_price,
+		:created_at
+	)`
+	_, err := s.db.NamedExecContext(ctx, query, entryPersistent{
+		ID:         string(entry.ID),
+		UserID:     string(entry.UserID),
+		WishlistID: string(entry.WishlistID),
+		ProductID:  string(entry.ProductID),
+		Type:       string(entry.Type),
+		OldPrice:   entry.OldPrice,
+		NewPrice:   entry.NewPrice,
+		CreatedAt:  entry.CreatedAt,
+	})
+	return err
+}
+
+func (s *Storage) GetUserIDs(ctx context.Context) ([]user.ID, error) {
+	query := `SELECT DISTINCT user_id FROM digest_entry`
+	userIDs := make([]user.ID, 0)
+	err := s.db.SelectContext(ctx, &userIDs, query)
+	return userIDs, err
+}
+
+func (s *Storage) GetByUser(ctx context.Context, userID user.ID) ([]*digestPkg.Entry, error) {
+	query := `SELECT * FROM digest_entry WHERE user_id = $1 ORDER BY created_at`
+	entriesPersistent := make([]*entryPersistent, 0)
+	err := s.db.SelectContext(ctx, &entriesPersistent, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*digestPkg.Entry, 0, len(entriesPersistent))
+	for _, p := range entriesPersistent {
+		entries = append(entries, p.ToEntry())
+	}
+	return entries, nil
+}
+
+func (s *Storage) DeleteByUser(ctx context.Context, userID user.ID, before time.Time) error {
+	query := `DELETE FROM digest_entry WHERE user_id = $1 AND created_at < $2`
+	_, err := s.db.ExecContext(ctx, query, userID, before)
+	return err
+}
@@ -0,0 +1,166 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	productEvents "github.com/grulex/go-wishlist/pkg/events/product"
+	"github.com/grulex/go-wishlist/pkg/events/wish"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+// subscriptionName identifies the handlers of this subscriber in the retry state and dead letters, don't change it
+const subscriptionName = "digest"
+
+type eventManager interface {
+	Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler)
+}
+
+type digestService interface {
+	Add(ctx context.Context, entry *digestPkg.Entry) error
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	GetItemsByProductID(ctx context.Context, productID productPkg.ID) ([]*wishlistPkg.Item, error)
+	CanNotify(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID) (bool, error)
+}
+
+type subscribeService interface {
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*subscribePkg.Subscribe, error)
+}
+
+var entryKinds = map[digestPkg.EntryType]notify.EventKind{
+	digestPkg.EntryTypeNewItem:      notify.EventKindNewItems,
+	digestPkg.EntryTypeRemovedItem:  notify.EventKindRemovedItems,
+	digestPkg.EntryTypePriceChanged: notify.EventKindPriceChanges,
+}
+
+// Subscriber collects changes of followed wishlists for users who prefer digests over instant notifications
+type Subscriber struct {
+	digestService    digestService
+	userService      userService
+	wishlistService  wishlistService
+	subscribeService subscribeService
+}
+
+func NewSubscriberForDigest(
+	digestService digestService,
+	userService userService,
+	wishlistService wishlistService,
+	subscribeService subscribeService,
+) *Subscriber {
+	return &Subscriber{
+		digestService:    digestService,
+		userService:      userService,
+		wishlistService:  wishlistService,
+		subscribeService: subscribeService,
+	}
+}
+
+func (s *Subscriber) Subscribe(manager eventManager) {
+	manager.Subscribe(wish.EventWishItemAdded, subscriptionName, s.onWishItemChanged(digestPkg.EntryTypeNewItem))
+	manager.Subscribe(wish.EventWishItemRemoved, subscriptionName, s.onWishItemChanged(digestPkg.EntryTypeRemovedItem))
+	manager.Subscribe(productEvents.EventProductPriceChanged, subscriptionName, s.onProductPriceChanged())
+}
+
+func (s *Subscriber) onWishItemChanged(entryType digestPkg.EntryType) eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var itemPayload wish.ItemPayload
+		err := json.Unmarshal(payload, &itemPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		wishlist, err := s.wishlistService.Get(ctx, itemPayload.ItemID.WishlistID)
+		if err != nil {
+			return err
+		}
+		return s.addEntries(ctx, wishlist, digestPkg.Entry{
+			WishlistID: itemPayload.ItemID.WishlistID,
+			ProductID:  itemPayload.ItemID.ProductID,
+			Type:       entryType,
+			CreatedAt:  itemPayload.EventAt,
+		})
+	}
+}
+
+func (s *Subscriber) onProductPriceChanged() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var pricePayload productEvents.PricePayload
+		err := json.Unmarshal(payload, &pricePayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		items, err := s.wishlistService.GetItemsByProductID(ctx, pricePayload.ProductID)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.IsFulfilled() {
+				continue
+			}
+			wishlist, err := s.wishlistService.Get(ctx, item.ID.WishlistID)
+			if err != nil {
+				return err
+			}
+			err = s.addEntries(ctx, wishlist, digestPkg.Entry{
+				WishlistID: item.ID.WishlistID,
+				ProductID:  item.ID.ProductID,
+				Type:       digestPkg.EntryTypePriceChanged,
+				OldPrice:   pricePayload.OldPrice,
+				NewPrice:   pricePayload.NewPrice,
+				CreatedAt:  pricePayload.EventAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addEntries copies the entry for every subscriber of the wishlist with digest delivery
+func (s *Subscriber) addEntries(ctx context.Context, wishlist *wishlistPkg.Wishlist, entry digestPkg.Entry) error {
+	subscribes, err := s.subscribeService.GetByWishlist(ctx, entry.WishlistID)
+	if err != nil {
+		return err
+	}
+	for _, subscribe := range subscribes {
+		if !subscribe.IsNotifyEnabled || subscribe.UserID == wishlist.UserID {
+			continue
+		}
+		canNotify, err := s.wishlistService.CanNotify(ctx, wishlist, subscribe.UserID)
+		if err != nil {
+			return err
+		}
+		if !canNotify {
+			continue
+		}
+		user, err := s.userService.Get(ctx, subscribe.UserID)
+		if err != nil {
+			return err
+		}
+		if !user.IsDigestDelivery() || !user.IsNotifyEventEnabled(entryKinds[entry.Type]) {
+			continue
+		}
+
+		userEntry := entry
+		userEntry.UserID = subscribe.UserID
+		err = s.digestService.Add(ctx, &userEntry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -24,7 +24,12 @@ type EventHandler func(ctx context.Context, payload json.RawMessage) error
 type EventManager interface {
 	Publish(ctx context.Context, event Event) error
 	PublishMany(ctx context.Context, events ...Event) error
-	Subscribe(eventName EventName, handler EventHandler)
+	// Subscribe registers the handler under a name unique for the event, which must not change between releases:
+	// pending retries and dead letters refer to it
+	Subscribe(eventName EventName, name string, handler EventHandler)
+	SubscribeWithPolicy(eventName EventName, name string, handler EventHandler, policy RetryPolicy)
+	GetDeadLetters(ctx context.Context) ([]*DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id DeadLetterID) error
 	StartHandling(ctx context.Context) error
 	Stop(ctx context.Context) error
 }
@@ -4,25 +4,51 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"log"
+	"sort"
 	"sync"
+	"time"
 )
 
 type storageEvent struct {
 	Name    eventmanager.EventName
+	Key     string
 	Payload json.RawMessage
+	// Attempts and HandledBy keep the retry state between the attempts, like the outbox row does
+	Attempts  int
+	HandledBy map[eventmanager.SubscriptionID]bool
 }
+
+// EventManager handles the events in memory. A failed event is handed back to the handling loop after the backoff
+// instead of waiting in the worker, so the other keys of the worker go on. The later events with the same key
+// wait for the retried one to keep the publish order.
 type EventManager struct {
-	handlers   map[eventmanager.EventName][]eventmanager.EventHandler
-	eventsChan chan storageEvent
-	mu         *sync.RWMutex
+	handlers      map[eventmanager.EventName][]eventmanager.Subscription
+	eventsChan    chan storageEvent
+	retriesChan   chan storageEvent
+	done          chan struct{}
+	deadLetters   map[eventmanager.DeadLetterID]*eventmanager.DeadLetter
+	waiting       map[string][]storageEvent
+	workers       int
+	mu            *sync.RWMutex
+	deadLettersMu *sync.RWMutex
+	waitingMu     *sync.Mutex
 }
 
-func NewEventManager(bufferSize int) *EventManager {
+func NewEventManager(bufferSize, workers int) *EventManager {
 	return &EventManager{
-		handlers:   make(map[eventmanager.EventName][]eventmanager.EventHandler),
-		eventsChan: make(chan storageEvent, bufferSize),
-		mu:         &sync.RWMutex{},
+		handlers:      make(map[eventmanager.EventName][]eventmanager.Subscription),
+		eventsChan:    make(chan storageEvent, bufferSize),
+		retriesChan:   make(chan storageEvent),
+		done:          make(chan struct{}),
+		deadLetters:   make(map[eventmanager.DeadLetterID]*eventmanager.DeadLetter),
+		waiting:       make(map[string][]storageEvent),
+		workers:       workers,
+		mu:            &sync.RWMutex{},
+		deadLettersMu: &sync.RWMutex{},
+		waitingMu:     &sync.Mutex{},
 	}
 }
 
@@ -33,6 +59,7 @@ func (m EventManager) Publish(_ context.Context, event eventmanager.Event) error
 	}
 	m.eventsChan <- storageEvent{
 		Name:    event.GetName(),
+		Key:     eventmanager.GetEventKey(event),
 		Payload: jsonPayload,
 	}
 	return nil
@@ -48,32 +75,199 @@ func (m EventManager) PublishMany(ctx context.Context, events ...eventmanager.Ev
 	return nil
 }
 
-func (m EventManager) Subscribe(eventName eventmanager.EventName, handler eventmanager.EventHandler) {
+func (m EventManager) Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler) {
+	m.SubscribeWithPolicy(eventName, name, handler, eventmanager.DefaultRetryPolicy)
+}
+
+func (m EventManager) SubscribeWithPolicy(
+	eventName eventmanager.EventName,
+	name string,
+	handler eventmanager.EventHandler,
+	policy eventmanager.RetryPolicy,
+) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	m.handlers[eventName] = append(m.handlers[eventName], handler)
+	subscription := eventmanager.NewSubscription(eventName, name, handler, policy)
+	m.handlers[eventName] = eventmanager.AddSubscription(m.handlers[eventName], subscription)
 }
 
+// StartHandling can be called once, the pending retries are dropped when it returns
 func (m EventManager) StartHandling(ctx context.Context) error {
+	pool := eventmanager.NewWorkerPool(m.workers, cap(m.eventsChan))
+	pool.Start()
+	defer pool.Stop()
+	defer close(m.done)
 	for {
+		var event storageEvent
+		var ok bool
 		select {
-		case event := <-m.eventsChan:
-			m.mu.RLock()
-			handlers, ok := m.handlers[event.Name]
-			m.mu.RUnlock()
+		case event, ok = <-m.eventsChan:
 			if !ok {
-				continue
-			}
-			for _, handler := range handlers {
-				err := handler(context.Background(), event.Payload)
-				if err != nil {
-					fmt.Println(err)
-				}
+				return nil
 			}
+		case event = <-m.retriesChan:
 		case <-ctx.Done():
 			return nil
 		}
+		pool.Submit(event.Key, func() {
+			m.handleInOrder(ctx, event)
+		})
+	}
+}
+
+// handleInOrder handles the event unless an earlier event with the same key is waiting for a retry,
+// then the event is queued after it. The queued events are handled when the retried one is done.
+func (m EventManager) handleInOrder(ctx context.Context, event storageEvent) {
+	if event.Key != "" && event.Attempts == 0 {
+		m.waitingMu.Lock()
+		if waiting, ok := m.waiting[event.Key]; ok {
+			m.waiting[event.Key] = append(waiting, event)
+			m.waitingMu.Unlock()
+			return
+		}
+		m.waitingMu.Unlock()
+	}
+
+	for {
+		retryAfter, isRetry := m.handle(ctx, &event)
+		if isRetry {
+			m.scheduleRetry(event, retryAfter)
+			return
+		}
+		if event.Key == "" {
+			return
+		}
+
+		m.waitingMu.Lock()
+		waiting := m.waiting[event.Key]
+		if len(waiting) == 0 {
+			delete(m.waiting, event.Key)
+			m.waitingMu.Unlock()
+			return
+		}
+		event, m.waiting[event.Key] = waiting[0], waiting[1:]
+		m.waitingMu.Unlock()
+	}
+}
+
+// handle calls the subscriptions which haven't handled the event yet. It returns the delay of the retry
+// if any subscription failed and may try again, the others failed for good are put to the dead letters.
+func (m EventManager) handle(ctx context.Context, event *storageEvent) (time.Duration, bool) {
+	m.mu.RLock()
+	subscriptions := m.handlers[event.Name]
+	m.mu.RUnlock()
+
+	event.Attempts++
+	var retryAfter time.Duration
+	isRetry := false
+	for _, subscription := range subscriptions {
+		if event.HandledBy[subscription.ID] {
+			continue
+		}
+		err := subscription.CallOnce(ctx, event.Payload)
+		if err != nil && subscription.Policy.ShouldRetry(event.Attempts, err) {
+			backoff := subscription.Policy.Backoff(event.Attempts)
+			if !isRetry || backoff < retryAfter {
+				retryAfter = backoff
+			}
+			isRetry = true
+			continue
+		}
+		if err != nil {
+			m.addDeadLetter(*event, subscription.ID, event.Attempts, err)
+		}
+		if event.HandledBy == nil {
+			event.HandledBy = make(map[eventmanager.SubscriptionID]bool)
+		}
+		event.HandledBy[subscription.ID] = true
+	}
+	return retryAfter, isRetry
+}
+
+// scheduleRetry hands the event back to the handling loop after the delay. The later events with the same key
+// are kept waiting meanwhile.
+func (m EventManager) scheduleRetry(event storageEvent, after time.Duration) {
+	if event.Key != "" {
+		m.waitingMu.Lock()
+		if _, ok := m.waiting[event.Key]; !ok {
+			m.waiting[event.Key] = nil
+		}
+		m.waitingMu.Unlock()
+	}
+	time.AfterFunc(after, func() {
+		select {
+		case m.retriesChan <- event:
+		case <-m.done:
+		}
+	})
+}
+
+func (m EventManager) addDeadLetter(event storageEvent, subscriptionID eventmanager.SubscriptionID, attempts int, err error) {
+	log.Printf("event %s failed after %d attempts: %v\n", event.Name, attempts, err)
+	deadLetter := &eventmanager.DeadLetter{
+		ID:             eventmanager.DeadLetterID(uuid.NewString()),
+		EventName:      event.Name,
+		SubscriptionID: subscriptionID,
+		Payload:        event.Payload,
+		Error:          err.Error(),
+		Attempts:       attempts,
+		FailedAt:       time.Now().UTC(),
+	}
+	m.deadLettersMu.Lock()
+	m.deadLetters[deadLetter.ID] = deadLetter
+	m.deadLettersMu.Unlock()
+}
+
+func (m EventManager) GetDeadLetters(_ context.Context) ([]*eventmanager.DeadLetter, error) {
+	m.deadLettersMu.RLock()
+	deadLetters := make([]*eventmanager.DeadLetter, 0, len(m.deadLetters))
+	for _, deadLetter := range m.deadLetters {
+		deadLetters = append(deadLetters, deadLetter)
+	}
+	m.deadLettersMu.RUnlock()
+
+	sort.Slice(deadLetters, func(i, j int) bool {
+		return deadLetters[i].FailedAt.Before(deadLetters[j].FailedAt)
+	})
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter calls the failed subscription once more. The dead letter is removed on success.
+func (m EventManager) ReplayDeadLetter(ctx context.Context, id eventmanager.DeadLetterID) error {
+	m.deadLettersMu.RLock()
+	deadLetter, ok := m.deadLetters[id]
+	m.deadLettersMu.RUnlock()
+	if !ok {
+		return eventmanager.ErrDeadLetterNotFound
+	}
+
+	subscription, ok := m.getSubscription(deadLetter.EventName, deadLetter.SubscriptionID)
+	if !ok {
+		return eventmanager.ErrSubscriptionNotFound
+	}
+
+	err := subscription.CallOnce(ctx, deadLetter.Payload)
+	m.deadLettersMu.Lock()
+	defer m.deadLettersMu.Unlock()
+	if err != nil {
+		deadLetter.Attempts++
+		deadLetter.Error = err.Error()
+		deadLetter.FailedAt = time.Now().UTC()
+		return err
+	}
+	delete(m.deadLetters, id)
+	return nil
+}
+
+func (m EventManager) getSubscription(eventName eventmanager.EventName, id eventmanager.SubscriptionID) (eventmanager.Subscription, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, subscription := range m.handlers[eventName] {
+		if subscription.ID == id {
+			return subscription, true
+		}
 	}
+	return eventmanager.Subscription{}, false
 }
 
 func (m EventManager) Stop(_ context.Context) error {
@@ -0,0 +1,221 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+)
+
+const testEventName eventmanager.EventName = "test"
+
+var errTemporary = errors.New("temporary failure")
+
+var testPolicy = eventmanager.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 10 * time.Millisecond,
+	MaxBackoff:     50 * time.Millisecond,
+	HandlerTimeout: time.Second,
+}
+
+type testPayload struct {
+	Key string
+	Seq int
+}
+
+type testEvent struct {
+	payload testPayload
+}
+
+func (e testEvent) GetName() eventmanager.EventName {
+	return testEventName
+}
+
+func (e testEvent) GetPayload() eventmanager.Payload {
+	return e.payload
+}
+
+func (e testEvent) GetKey() string {
+	return e.payload.Key
+}
+
+// recorder keeps the payloads in the order of the successful handler calls
+type recorder struct {
+	mu       sync.Mutex
+	payloads []testPayload
+	calls    map[int]int
+	handled  chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{calls: map[int]int{}, handled: make(chan struct{}, 100)}
+}
+
+// handler fails the first fails[seq] calls of the event with the sequence number
+func (r *recorder) handler(fails map[int]int, err error) eventmanager.EventHandler {
+	return func(_ context.Context, raw json.RawMessage) error {
+		var payload testPayload
+		if json.Unmarshal(raw, &payload) != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+		r.mu.Lock()
+		r.calls[payload.Seq]++
+		failed := r.calls[payload.Seq] <= fails[payload.Seq]
+		if !failed {
+			r.payloads = append(r.payloads, payload)
+		}
+		r.mu.Unlock()
+		r.handled <- struct{}{}
+		if failed {
+			return err
+		}
+		return nil
+	}
+}
+
+func (r *recorder) waitForCalls(t *testing.T, count int) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		select {
+		case <-r.handled:
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d handler calls, got %d", count, i)
+		}
+	}
+}
+
+func startManager(t *testing.T, workers int, subscribe func(m *EventManager)) *EventManager {
+	t.Helper()
+	m := NewEventManager(10, workers)
+	subscribe(m)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		<-m.done
+	})
+	go func() {
+		_ = m.StartHandling(ctx)
+	}()
+	return m
+}
+
+func publish(t *testing.T, m *EventManager, payloads ...testPayload) {
+	t.Helper()
+	for _, payload := range payloads {
+		if err := m.Publish(context.Background(), testEvent{payload: payload}); err != nil {
+			t.Fatalf("failed to publish: %v", err)
+		}
+	}
+}
+
+func TestRetriedEventKeepsKeyOrder(t *testing.T) {
+	r := newRecorder()
+	m := startManager(t, 1, func(m *EventManager) {
+		m.SubscribeWithPolicy(testEventName, "test", r.handler(map[int]int{1: 2}, errTemporary), testPolicy)
+	})
+
+	publish(t, m,
+		testPayload{Key: "a", Seq: 1},
+		testPayload{Key: "a", Seq: 2},
+		testPayload{Key: "b", Seq: 3},
+		testPayload{Key: "a", Seq: 4},
+	)
+	r.waitForCalls(t, 6)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the event of the other key doesn't wait for the retries on the same worker
+	expected := []int{3, 1, 2, 4}
+	if len(r.payloads) != len(expected) {
+		t.Fatalf("expected %d handled events, got %v", len(expected), r.payloads)
+	}
+	for i, seq := range expected {
+		if r.payloads[i].Seq != seq {
+			t.Fatalf("expected the order %v, got %v", expected, r.payloads)
+		}
+	}
+	if r.calls[1] != 3 {
+		t.Fatalf("expected 3 calls of the failing event, got %d", r.calls[1])
+	}
+}
+
+func TestRetryCallsOnlyFailedSubscription(t *testing.T) {
+	failing, succeeding := newRecorder(), newRecorder()
+	m := startManager(t, 2, func(m *EventManager) {
+		m.SubscribeWithPolicy(testEventName, "failing", failing.handler(map[int]int{1: 1}, errTemporary), testPolicy)
+		m.SubscribeWithPolicy(testEventName, "succeeding", succeeding.handler(nil, nil), testPolicy)
+	})
+
+	publish(t, m, testPayload{Seq: 1})
+	failing.waitForCalls(t, 2)
+	succeeding.waitForCalls(t, 1)
+
+	select {
+	case <-succeeding.handled:
+		t.Fatal("the succeeded subscription was called again by the retry")
+	case <-time.After(50 * time.Millisecond):
+	}
+	deadLetters, _ := m.GetDeadLetters(context.Background())
+	if len(deadLetters) != 0 {
+		t.Fatalf("expected no dead letters, got %d", len(deadLetters))
+	}
+}
+
+func TestFailedEventGoesToDeadLetters(t *testing.T) {
+	r := newRecorder()
+	invalid := newRecorder()
+	m := startManager(t, 2, func(m *EventManager) {
+		m.SubscribeWithPolicy(testEventName, "test", r.handler(map[int]int{1: 4}, errTemporary), testPolicy)
+		m.SubscribeWithPolicy(testEventName, "invalid", invalid.handler(map[int]int{1: 1}, eventmanager.ErrInvalidPayload), testPolicy)
+	})
+
+	publish(t, m, testPayload{Seq: 1})
+	r.waitForCalls(t, testPolicy.MaxAttempts)
+	invalid.waitForCalls(t, 1)
+
+	var deadLetters []*eventmanager.DeadLetter
+	deadline := time.Now().Add(time.Second)
+	for len(deadLetters) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+		deadLetters, _ = m.GetDeadLetters(context.Background())
+	}
+	if len(deadLetters) != 2 {
+		t.Fatalf("expected 2 dead letters, got %d", len(deadLetters))
+	}
+	attempts := map[eventmanager.SubscriptionID]int{}
+	for _, deadLetter := range deadLetters {
+		attempts[deadLetter.SubscriptionID] = deadLetter.Attempts
+	}
+	// the invalid payload isn't retried
+	if attempts["test/invalid"] != 1 || attempts["test/test"] != testPolicy.MaxAttempts {
+		t.Fatalf("unexpected attempts of the dead letters: %v", attempts)
+	}
+
+	var testLetter *eventmanager.DeadLetter
+	for _, deadLetter := range deadLetters {
+		if deadLetter.SubscriptionID == "test/test" {
+			testLetter = deadLetter
+		}
+	}
+	// the handler fails one more time and succeeds after it
+	if err := m.ReplayDeadLetter(context.Background(), testLetter.ID); !errors.Is(err, errTemporary) {
+		t.Fatalf("expected the replay to fail, got %v", err)
+	}
+	if testLetter.Attempts != testPolicy.MaxAttempts+1 {
+		t.Fatalf("expected the failed replay to count the attempt, got %d", testLetter.Attempts)
+	}
+	if err := m.ReplayDeadLetter(context.Background(), testLetter.ID); err != nil {
+		t.Fatalf("failed to replay: %v", err)
+	}
+	if err := m.ReplayDeadLetter(context.Background(), testLetter.ID); !errors.Is(err, eventmanager.ErrDeadLetterNotFound) {
+		t.Fatalf("expected the replayed dead letter to be removed, got %v", err)
+	}
+	deadLetters, _ = m.GetDeadLetters(context.Background())
+	if len(deadLetters) != 1 || deadLetters[0].SubscriptionID != "test/invalid" {
+		t.Fatalf("expected only the invalid dead letter to stay, got %v", deadLetters)
+	}
+}
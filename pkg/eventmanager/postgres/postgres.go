@@ -0,0 +1,433 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/grulex/go-wishlist/db"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/jmoiron/sqlx"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+type eventPersistent struct {
+	ID            int64      `db:"id"`
+	Name          string     `db:"name"`
+	Key           string     `db:"event_key"`
+	Payload       string     `db:"payload"`
+	CreatedAt     time.Time  `db:"created_at"`
+	ProcessedAt   *time.Time `db:"processed_at"`
+	Attempts      int        `db:"attempts"`
+	NextAttemptAt *time.Time `db:"next_attempt_at"`
+	HandledBy     string     `db:"handled_by"`
+}
+
+// handleResult is the outcome of one attempt to handle the event by its pending subscriptions
+type handleResult struct {
+	event       *eventPersistent
+	handledBy   []string
+	deadLetters []*deadLetterPersistent
+	// retryAt is set when some subscriptions failed and will be retried
+	retryAt *time.Time
+	// isSkipped is set when an earlier event of the same key failed in the batch
+	isSkipped bool
+}
+
+// claimLease hides the claimed events from the other instances while they are handled.
+// The events of an instance stopped in the middle of a batch are handled again after it.
+const claimLease = time.Minute * 10
+
+type deadLetterPersistent struct {
+	ID             string    `db:"id"`
+	EventName      string    `db:"event_name"`
+	SubscriptionID string    `db:"subscription_id"`
+	Payload        string    `db:"payload"`
+	Error          string    `db:"error"`
+	Attempts       int       `db:"attempts"`
+	FailedAt       time.Time `db:"failed_at"`
+}
+
+func (d deadLetterPersistent) ToDeadLetter() *eventmanager.DeadLetter {
+	return &eventmanager.DeadLetter{
+		ID:             eventmanager.DeadLetterID(d.ID),
+		EventName:      eventmanager.EventName(d.EventName),
+		SubscriptionID: eventmanager.SubscriptionID(d.SubscriptionID),
+		Payload:        json.RawMessage(d.Payload),
+		Error:          d.Error,
+		Attempts:       d.Attempts,
+		FailedAt:       d.FailedAt,
+	}
+}
+
+// EventManager stores published events in the event_outbox table, so they survive restarts.
+// Several instances may handle the same outbox concurrently: rows are claimed with FOR UPDATE SKIP LOCKED
+// and leased for claimLease.
+// Failed subscriptions are retried by later batches: the row keeps the attempts, the time of the next attempt
+// and the subscriptions which have already handled the event.
+type EventManager struct {
+	db           *sqlx.DB
+	handlers     map[eventmanager.EventName][]eventmanager.Subscription
+	batchSize    int
+	pollInterval time.Duration
+	workers      int
+	stop         chan struct{}
+	stopOnce     *sync.Once
+	mu           *sync.RWMutex
+}
+
+func NewEventManager(db *sqlx.DB, batchSize int, pollInterval time.Duration, workers int) *EventManager {
+	return &EventManager{
+		db:           db,
+		handlers:     make(map[eventmanager.EventName][]eventmanager.Subscription),
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		workers:      workers,
+		stop:         make(chan struct{}),
+		stopOnce:     &sync.Once{},
+		mu:           &sync.RWMutex{},
+	}
+}
+
+func (m *EventManager) Publish(ctx context.Context, event eventmanager.Event) error {
+	return m.PublishMany(ctx, event)
+}
+
+// PublishMany writes the events to the outbox. It joins the transaction from the context (see db.InTransaction),
+// so the events are stored only if the state change they describe is committed.
+func (m *EventManager) PublishMany(ctx context.Context, events ...eventmanager.Event) error {
+	return db.InTransaction(ctx, m.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, event := range events {
+			err := m.publish(ctx, tx, event)
+			if err != nil {
+				return fmt.Errorf("publish event: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (m *EventManager) publish(ctx context.Context, tx *sqlx.Tx, event eventmanager.Event) error {
+	jsonPayload, err := json.Marshal(event.GetPayload())
+	if err != nil {
+		return fmt.Errorf("marshal event payload: %w", err)
+	}
+
+	query := `INSERT INTO event_outbox (
+		name,
+		event_key,
+		payload,
+		created_at
+	) VALUES (
+		:name,
+		:event_key,
+		:payload,
+		:created_at
+	)`
+	_, err = tx.NamedExecContext(ctx, query, eventPersistent{
+		Name:      string(event.GetName()),
+		Key:       eventmanager.GetEventKey(event),
+		Payload:   string(jsonPayload),
+		CreatedAt: time.Now().UTC(),
+	})
+	return err
+}
+
+func (m *EventManager) Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler) {
+	m.SubscribeWithPolicy(eventName, name, handler, eventmanager.DefaultRetryPolicy)
+}
+
+func (m *EventManager) SubscribeWithPolicy(
+	eventName eventmanager.EventName,
+	name string,
+	handler eventmanager.EventHandler,
+	policy eventmanager.RetryPolicy,
+) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subscription := eventmanager.NewSubscription(eventName, name, handler, policy)
+	m.handlers[eventName] = eventmanager.AddSubscription(m.handlers[eventName], subscription)
+}
+
+func (m *EventManager) StartHandling(ctx context.Context) error {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		handled, err := m.handleBatch(ctx)
+		if err != nil {
+			log.Println(err)
+		}
+		if handled == m.batchSize {
+			// the outbox may have more events, don't wait for the next tick
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-m.stop:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// handleBatch claims due events, then calls the handlers outside of any transaction: a slow handler
+// doesn't keep the rows locked. Each result is saved in its own short transaction.
+func (m *EventManager) handleBatch(ctx context.Context) (int, error) {
+	events, err := m.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	// events of the batch are handled concurrently, events with the same key keep their order.
+	// Ordering between instances is not guaranteed: they may claim events of the same key in parallel.
+	pool := eventmanager.NewWorkerPool(m.workers, len(events))
+	pool.Start()
+	results := make([]*handleResult, 0, len(events))
+	blockedKeys := make(map[string]bool)
+	resultsMu := &sync.Mutex{}
+	for _, event := range events {
+		event := event
+		pool.Submit(event.Key, func() {
+			resultsMu.Lock()
+			isBlocked := event.Key != "" && blockedKeys[event.Key]
+			resultsMu.Unlock()
+
+			// a blocked event must not overtake the earlier event of its key, which is retried later
+			result := &handleResult{event: event, isSkipped: true}
+			if !isBlocked {
+				result = m.handle(ctx, event)
+			}
+
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			if result.retryAt != nil && event.Key != "" {
+				blockedKeys[event.Key] = true
+			}
+			results = append(results, result)
+		})
+	}
+	pool.Stop()
+
+	var lastErr error
+	for _, result := range results {
+		err = m.saveResult(ctx, result)
+		if err != nil {
+			lastErr = fmt.Errorf("save result of event %d: %w", result.event.ID, err)
+		}
+	}
+	return len(events), lastErr
+}
+
+// claim hides the due events from the other instances for claimLease and commits at once.
+// An event waits while an earlier event with the same key is waiting for its retry or is being handled.
+func (m *EventManager) claim(ctx context.Context) ([]*eventPersistent, error) {
+	query := `UPDATE event_outbox SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT o.id FROM event_outbox o
+			WHERE o.processed_at IS NULL
+				AND (o.next_attempt_at IS NULL OR o.next_attempt_at <= $1)
+				AND NOT EXISTS (
+					SELECT 1 FROM event_outbox w
+					WHERE w.event_key <> '' AND w.event_key = o.event_key AND w.id < o.id
+						AND w.processed_at IS NULL AND w.next_attempt_at > $1
+				)
+			ORDER BY o.id
+			LIMIT $3
+			FOR UPDATE OF o SKIP LOCKED
+		)
+		RETURNING *`
+	now := time.Now().UTC()
+	events := make([]*eventPersistent, 0, m.batchSize)
+	err := m.db.SelectContext(ctx, &events, query, now, now.Add(claimLease), m.batchSize)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// handle calls once the subscriptions which haven't handled the event yet. Failed subscriptions are retried
+// after the policy backoff, the ones out of attempts go to the dead letters.
+func (m *EventManager) handle(ctx context.Context, event *eventPersistent) *handleResult {
+	m.mu.RLock()
+	subscriptions := m.handlers[eventmanager.EventName(event.Name)]
+	m.mu.RUnlock()
+
+	result := &handleResult{event: event}
+	_ = json.Unmarshal([]byte(event.HandledBy), &result.handledBy)
+	isHandled := make(map[string]bool, len(result.handledBy))
+	for _, id := range result.handledBy {
+		isHandled[id] = true
+	}
+
+	attempt := event.Attempts + 1
+	now := time.Now().UTC()
+	for _, subscription := range subscriptions {
+		if isHandled[string(subscription.ID)] {
+			continue
+		}
+		err := subscription.CallOnce(ctx, json.RawMessage(event.Payload))
+		if err != nil && subscription.Policy.ShouldRetry(attempt, err) {
+			retryAt := now.Add(subscription.Policy.Backoff(attempt))
+			if result.retryAt == nil || retryAt.Before(*result.retryAt) {
+				result.retryAt = &retryAt
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("event %s failed after %d attempts: %v\n", event.Name, attempt, err)
+			result.deadLetters = append(result.deadLetters, &deadLetterPersistent{
+				ID:             uuid.NewString(),
+				EventName:      event.Name,
+				SubscriptionID: string(subscription.ID),
+				Payload:        event.Payload,
+				Error:          err.Error(),
+				Attempts:       attempt,
+				FailedAt:       now,
+			})
+		}
+		result.handledBy = append(result.handledBy, string(subscription.ID))
+	}
+	return result
+}
+
+func (m *EventManager) saveResult(ctx context.Context, result *handleResult) error {
+	if result.isSkipped {
+		// released to wait for the earlier event of the key
+		query := `UPDATE event_outbox SET next_attempt_at = NULL WHERE id = $1`
+		_, err := m.db.ExecContext(ctx, query, result.event.ID)
+		return err
+	}
+
+	handledBy, err := json.Marshal(result.handledBy)
+	if err != nil {
+		return err
+	}
+	return db.InTransaction(ctx, m.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, deadLetter := range result.deadLetters {
+			err := m.addDeadLetter(ctx, tx, deadLetter)
+			if err != nil {
+				return err
+			}
+		}
+
+		if result.retryAt == nil {
+			query := `UPDATE event_outbox SET processed_at = $1, next_attempt_at = NULL, handled_by = $2 WHERE id = $3`
+			_, err := tx.ExecContext(ctx, query, time.Now().UTC(), string(handledBy), result.event.ID)
+			return err
+		}
+		query := `UPDATE event_outbox SET attempts = attempts + 1, next_attempt_at = $1, handled_by = $2 WHERE id = $3`
+		_, err := tx.ExecContext(ctx, query, *result.retryAt, string(handledBy), result.event.ID)
+		return err
+	})
+}
+
+func (m *EventManager) addDeadLetter(ctx context.Context, tx *sqlx.Tx, deadLetter *deadLetterPersistent) error {
+	query := `INSERT INTO event_dead_letter (
+		id,
+		event_name,
+		subscription_id,
+		payload,
+		error,
+		attempts,
+		failed_at
+	) VALUES (
+		:id,
+		:event_name,
+		:subscription_id,
+		:payload,
+		:error,
+		:attempts,
+		:failed_at
+	)`
+	_, err := tx.NamedExecContext(ctx, query, deadLetter)
+	return err
+}
+
+func (m *EventManager) GetDeadLetters(ctx context.Context) ([]*eventmanager.DeadLetter, error) {
+	query := `SELECT * FROM event_dead_letter ORDER BY failed_at`
+	deadLettersPersistent := make([]*deadLetterPersistent, 0)
+	err := m.db.SelectContext(ctx, &deadLettersPersistent, query)
+	if err != nil {
+		return nil, err
+	}
+	deadLetters := make([]*eventmanager.DeadLetter, 0, len(deadLettersPersistent))
+	for _, d := range deadLettersPersistent {
+		deadLetters = append(deadLetters, d.ToDeadLetter())
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter calls the failed subscription once more. The dead letter is taken from the table in a short
+// transaction, so no transaction is open while the handler runs, and it is put back if the handler fails again.
+func (m *EventManager) ReplayDeadLetter(ctx context.Context, id eventmanager.DeadLetterID) error {
+	deadLetter := &deadLetterPersistent{}
+	var subscription eventmanager.Subscription
+	err := db.InTransaction(ctx, m.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		query := `SELECT * FROM event_dead_letter WHERE id = $1 FOR UPDATE SKIP LOCKED`
+		err := tx.GetContext(ctx, deadLetter, query, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return eventmanager.ErrDeadLetterNotFound
+			}
+			return err
+		}
+
+		var ok bool
+		subscription, ok = m.getSubscription(eventmanager.EventName(deadLetter.EventName), eventmanager.SubscriptionID(deadLetter.SubscriptionID))
+		if !ok {
+			return eventmanager.ErrSubscriptionNotFound
+		}
+
+		query = `DELETE FROM event_dead_letter WHERE id = $1`
+		_, err = tx.ExecContext(ctx, query, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	handleErr := subscription.CallOnce(ctx, json.RawMessage(deadLetter.Payload))
+	if handleErr == nil {
+		return nil
+	}
+
+	deadLetter.Attempts++
+	deadLetter.Error = handleErr.Error()
+	deadLetter.FailedAt = time.Now().UTC()
+	// the letter is put back even if the replay was canceled
+	err = db.InTransaction(context.WithoutCancel(ctx), m.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		return m.addDeadLetter(ctx, tx, deadLetter)
+	})
+	if err != nil {
+		return errors.Join(handleErr, fmt.Errorf("put back dead letter %s: %w", id, err))
+	}
+	return handleErr
+}
+
+func (m *EventManager) getSubscription(eventName eventmanager.EventName, id eventmanager.SubscriptionID) (eventmanager.Subscription, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, subscription := range m.handlers[eventName] {
+		if subscription.ID == id {
+			return subscription, true
+		}
+	}
+	return eventmanager.Subscription{}, false
+}
+
+func (m *EventManager) Stop(_ context.Context) error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	return nil
+}
@@ -0,0 +1,98 @@
+package eventmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	HandlerTimeout time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	HandlerTimeout: time.Second * 30,
+}
+
+// Backoff returns the delay before the next attempt, doubling it after every failed attempt.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// ShouldRetry tells whether the failed attempt is worth repeating. Invalid payloads never are.
+func (p RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	return !errors.Is(err, ErrInvalidPayload) && attempt < p.MaxAttempts
+}
+
+type SubscriptionID string
+
+type Subscription struct {
+	ID      SubscriptionID
+	Handler EventHandler
+	Policy  RetryPolicy
+}
+
+// NewSubscription identifies the subscription by the event name and the explicit subscription name,
+// so the retry state and dead letters find the same handler after a restart or a reordering of subscriptions.
+func NewSubscription(eventName EventName, name string, handler EventHandler, policy RetryPolicy) Subscription {
+	return Subscription{
+		ID:      SubscriptionID(fmt.Sprintf("%s/%s", eventName, name)),
+		Handler: handler,
+		Policy:  policy,
+	}
+}
+
+// AddSubscription appends the subscription, a missing or repeated name is a programming error
+func AddSubscription(subscriptions []Subscription, subscription Subscription) []Subscription {
+	if strings.HasSuffix(string(subscription.ID), "/") {
+		panic("eventmanager: subscription name is required")
+	}
+	for _, s := range subscriptions {
+		if s.ID == subscription.ID {
+			panic(fmt.Sprintf("eventmanager: duplicate subscription %s", subscription.ID))
+		}
+	}
+	return append(subscriptions, subscription)
+}
+
+// CallOnce runs the handler with the policy timeout. The managers keep the retry state between the attempts
+// and repeat the call after the backoff without blocking the other events.
+func (s Subscription) CallOnce(ctx context.Context, payload json.RawMessage) error {
+	if s.Policy.HandlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Policy.HandlerTimeout)
+		defer cancel()
+	}
+	return s.Handler(ctx, payload)
+}
+
+type DeadLetterID string
+
+type DeadLetter struct {
+	ID             DeadLetterID
+	EventName      EventName
+	SubscriptionID SubscriptionID
+	Payload        json.RawMessage
+	Error          string
+	Attempts       int
+	FailedAt       time.Time
+}
@@ -0,0 +1,76 @@
+package eventmanager
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// KeyedEvent is an event which must be handled in publish order relative to other events with the same key.
+type KeyedEvent interface {
+	Event
+	GetKey() string
+}
+
+func GetEventKey(event Event) string {
+	if keyed, ok := event.(KeyedEvent); ok {
+		return keyed.GetKey()
+	}
+	return ""
+}
+
+// WorkerPool runs tasks concurrently. Tasks with the same non-empty key always go to the same worker,
+// so they are executed one by one in submit order. Tasks without a key are spread over the workers.
+type WorkerPool struct {
+	queues []chan func()
+	next   *atomic.Uint32
+	wg     *sync.WaitGroup
+}
+
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	queues := make([]chan func(), workers)
+	for i := range queues {
+		queues[i] = make(chan func(), queueSize)
+	}
+	return &WorkerPool{
+		queues: queues,
+		next:   &atomic.Uint32{},
+		wg:     &sync.WaitGroup{},
+	}
+}
+
+func (p *WorkerPool) Start() {
+	for _, queue := range p.queues {
+		p.wg.Add(1)
+		go func(queue chan func()) {
+			defer p.wg.Done()
+			for task := range queue {
+				task()
+			}
+		}(queue)
+	}
+}
+
+func (p *WorkerPool) Submit(key string, task func()) {
+	p.queues[p.queueIndex(key)] <- task
+}
+
+// Stop waits until all submitted tasks are done. The pool can't be used after that.
+func (p *WorkerPool) Stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+func (p *WorkerPool) queueIndex(key string) int {
+	if key == "" {
+		return int(p.next.Add(1) % uint32(len(p.queues)))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
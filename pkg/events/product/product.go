@@ -0,0 +1,41 @@
+package product
+
+import (
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	"time"
+)
+
+const (
+	EventProductPriceChanged eventmanager.EventName = "product.price.changed"
+)
+
+func NewPriceChangedEvent(payload PricePayload) eventmanager.Event {
+	return event{name: EventProductPriceChanged, payload: payload}
+}
+
+type PricePayload struct {
+	ProductID productPkg.ID
+	OldPrice  *currency.Amount
+	NewPrice  *currency.Amount
+	EventAt   time.Time
+}
+
+type event struct {
+	name    eventmanager.EventName
+	payload PricePayload
+}
+
+func (e event) GetName() eventmanager.EventName {
+	return e.name
+}
+
+func (e event) GetPayload() eventmanager.Payload {
+	return e.payload
+}
+
+// GetKey keeps price changes of the same product in order
+func (e event) GetKey() string {
+	return string(e.payload.ProductID)
+}
@@ -0,0 +1,45 @@
+package subscription
+
+import (
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const (
+	EventSubscriptionCreated eventmanager.EventName = "subscription.created"
+	EventSubscriptionRemoved eventmanager.EventName = "subscription.removed"
+)
+
+func NewCreatedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventSubscriptionCreated, payload: payload}
+}
+
+func NewRemovedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventSubscriptionRemoved, payload: payload}
+}
+
+type Payload struct {
+	UserID     user.ID
+	WishlistID wishlist.ID
+	EventAt    time.Time
+}
+
+type event struct {
+	name    eventmanager.EventName
+	payload Payload
+}
+
+func (e event) GetName() eventmanager.EventName {
+	return e.name
+}
+
+func (e event) GetPayload() eventmanager.Payload {
+	return e.payload
+}
+
+// GetKey keeps subscribe and unsubscribe of the same user and wishlist in order
+func (e event) GetKey() string {
+	return string(e.payload.UserID) + "/" + string(e.payload.WishlistID)
+}
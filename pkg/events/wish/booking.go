@@ -8,34 +8,39 @@ import (
 )
 
 const (
-	EventWishBookingUpdate eventmanager.EventName = "wish.booking.update"
+	EventWishBookingUpdate   eventmanager.EventName = "wish.booking.update"
+	EventWishBookingExpiring eventmanager.EventName = "wish.booking.expiring"
 )
 
 func NewBookingUpdateEvent(payload BookingPayload) eventmanager.Event {
-	return event{
-		name:    EventWishBookingUpdate,
-		payload: payload,
-	}
+	return newItemEvent(EventWishBookingUpdate, payload.ItemID, payload)
 }
 
+// BookingPayload describes a change of one user's booking. OldBookedBy is nil for a new booking,
+// NewBookedBy is nil for a released one, both are set when the booked quantity changes.
 type BookingPayload struct {
 	ItemID      wishlist.ItemID
 	WishOwner   user.ID
 	OldBookedBy *user.ID
 	NewBookedBy *user.ID
-	EventBy     user.ID
-	EventAt     time.Time
+	OldQuantity uint
+	NewQuantity uint
+	// IsExpired is set when the booking was released because of its expiry
+	IsExpired bool
+	EventBy   user.ID
+	EventAt   time.Time
 }
 
-type event struct {
-	name    eventmanager.EventName
-	payload BookingPayload
+func NewBookingExpiringEvent(payload BookingExpiringPayload) eventmanager.Event {
+	return newItemEvent(EventWishBookingExpiring, payload.ItemID, payload)
 }
 
-func (e event) GetName() eventmanager.EventName {
-	return e.name
-}
-
-func (e event) GetPayload() eventmanager.Payload {
-	return e.payload
+// BookingExpiringPayload reminds the booker that the booking will be released soon
+type BookingExpiringPayload struct {
+	ItemID    wishlist.ItemID
+	WishOwner user.ID
+	BookedBy  user.ID
+	Quantity  uint
+	ExpiresAt time.Time
+	EventAt   time.Time
 }
@@ -0,0 +1,30 @@
+package wish
+
+import (
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const (
+	EventWishContributionUpdate eventmanager.EventName = "wish.contribution.update"
+)
+
+func NewContributionUpdateEvent(payload ContributionPayload) eventmanager.Event {
+	return newItemEvent(EventWishContributionUpdate, payload.ItemID, payload)
+}
+
+// ContributionPayload describes a pledge change. OldAmount is nil for a new pledge, NewAmount is nil for a withdrawn one.
+type ContributionPayload struct {
+	ItemID          wishlist.ItemID
+	WishOwner       user.ID
+	Contributor     user.ID
+	OldAmount       *currency.Amount
+	NewAmount       *currency.Amount
+	Progress        uint
+	IsFullyCovered  bool
+	WasFullyCovered bool
+	EventAt         time.Time
+}
@@ -0,0 +1,33 @@
+package wish
+
+import (
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+type event struct {
+	name    eventmanager.EventName
+	key     string
+	payload eventmanager.Payload
+}
+
+func newItemEvent(name eventmanager.EventName, itemID wishlist.ItemID, payload eventmanager.Payload) eventmanager.Event {
+	return event{
+		name:    name,
+		key:     string(itemID.WishlistID) + "/" + string(itemID.ProductID),
+		payload: payload,
+	}
+}
+
+func (e event) GetName() eventmanager.EventName {
+	return e.name
+}
+
+func (e event) GetPayload() eventmanager.Payload {
+	return e.payload
+}
+
+// GetKey keeps events of the same item in order
+func (e event) GetKey() string {
+	return e.key
+}
@@ -0,0 +1,30 @@
+package wish
+
+import (
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const (
+	EventWishItemFulfilled eventmanager.EventName = "wish.item.fulfilled"
+	EventWishItemThanked   eventmanager.EventName = "wish.item.thanked"
+)
+
+func NewItemFulfilledEvent(payload GiftPayload) eventmanager.Event {
+	return newItemEvent(EventWishItemFulfilled, payload.ItemID, payload)
+}
+
+func NewItemThankedEvent(payload GiftPayload) eventmanager.Event {
+	return newItemEvent(EventWishItemThanked, payload.ItemID, payload)
+}
+
+// GiftPayload describes a received item and the users who gave it. Message is set for thanks only.
+type GiftPayload struct {
+	ItemID    wishlist.ItemID
+	WishOwner user.ID
+	Givers    []user.ID
+	Message   string
+	EventAt   time.Time
+}
@@ -0,0 +1,39 @@
+package wish
+
+import (
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const (
+	EventWishItemAdded   eventmanager.EventName = "wish.item.added"
+	EventWishItemRemoved eventmanager.EventName = "wish.item.removed"
+	EventWishItemUpdated eventmanager.EventName = "wish.item.updated"
+)
+
+func NewItemAddedEvent(payload ItemPayload) eventmanager.Event {
+	return newItemEvent(EventWishItemAdded, payload.ItemID, payload)
+}
+
+func NewItemRemovedEvent(payload ItemPayload) eventmanager.Event {
+	return newItemEvent(EventWishItemRemoved, payload.ItemID, payload)
+}
+
+func NewItemUpdatedEvent(payload ItemUpdatePayload) eventmanager.Event {
+	return newItemEvent(EventWishItemUpdated, payload.ItemID, payload)
+}
+
+type ItemPayload struct {
+	ItemID    wishlist.ItemID
+	WishOwner user.ID
+	EventAt   time.Time
+}
+
+type ItemUpdatePayload struct {
+	ItemID             wishlist.ItemID
+	WishOwner          user.ID
+	IsBookingAvailable bool
+	EventAt            time.Time
+}
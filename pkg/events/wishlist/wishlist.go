@@ -0,0 +1,75 @@
+package wishlist
+
+import (
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+const (
+	EventWishlistCreated  eventmanager.EventName = "wishlist.created"
+	EventWishlistUpdated  eventmanager.EventName = "wishlist.updated"
+	EventWishlistArchived eventmanager.EventName = "wishlist.archived"
+	EventWishlistRestored eventmanager.EventName = "wishlist.restored"
+	EventWishlistDeleted  eventmanager.EventName = "wishlist.deleted"
+	// EventWishlistOccasionUpcoming is published once per occurrence when the occasion is close
+	EventWishlistOccasionUpcoming eventmanager.EventName = "wishlist.occasion.upcoming"
+)
+
+func NewCreatedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventWishlistCreated, wishlistID: payload.WishlistID, payload: payload}
+}
+
+func NewUpdatedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventWishlistUpdated, wishlistID: payload.WishlistID, payload: payload}
+}
+
+func NewArchivedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventWishlistArchived, wishlistID: payload.WishlistID, payload: payload}
+}
+
+func NewRestoredEvent(payload Payload) eventmanager.Event {
+	return event{name: EventWishlistRestored, wishlistID: payload.WishlistID, payload: payload}
+}
+
+func NewDeletedEvent(payload Payload) eventmanager.Event {
+	return event{name: EventWishlistDeleted, wishlistID: payload.WishlistID, payload: payload}
+}
+
+func NewOccasionUpcomingEvent(payload OccasionPayload) eventmanager.Event {
+	return event{name: EventWishlistOccasionUpcoming, wishlistID: payload.WishlistID, payload: payload}
+}
+
+type Payload struct {
+	WishlistID wishlistPkg.ID
+	WishOwner  user.ID
+	EventAt    time.Time
+}
+
+type OccasionPayload struct {
+	WishlistID   wishlistPkg.ID
+	WishOwner    user.ID
+	OccasionDate time.Time
+	DaysLeft     int
+	EventAt      time.Time
+}
+
+type event struct {
+	name       eventmanager.EventName
+	wishlistID wishlistPkg.ID
+	payload    eventmanager.Payload
+}
+
+func (e event) GetName() eventmanager.EventName {
+	return e.name
+}
+
+func (e event) GetPayload() eventmanager.Payload {
+	return e.payload
+}
+
+// GetKey keeps events of the same wishlist in order
+func (e event) GetKey() string {
+	return string(e.wishlistID)
+}
@@ -54,6 +54,14 @@ func (l *Link) Base64() string {
 	return base64.StdEncoding.EncodeToString([]byte(str))
 }
 
+// Url returns a public url of the file served by the images endpoint of the api on baseUrl
+func (l *Link) Url(baseUrl string) string {
+	if l.StorageType == StorageTypeRemoteLink {
+		return string(l.ID)
+	}
+	return baseUrl + "/api/images/" + l.Base64()
+}
+
 type ImageSize struct {
 	Width  uint
 	Height uint
@@ -0,0 +1,15 @@
+package newitem
+
+import (
+	"github.com/grulex/go-wishlist/pkg/product"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+// Pending is an item added to a wishlist, waiting to be announced to the subscribers together
+// with the other items added shortly after
+type Pending struct {
+	WishlistID wishlist.ID
+	ProductID  product.ID
+	CreatedAt  time.Time
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"time"
+)
+
+type storage interface {
+	Add(ctx context.Context, item *newitemPkg.Pending) error
+	GetWishlistIDs(ctx context.Context, addedBefore time.Time) ([]wishlistPkg.ID, error)
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*newitemPkg.Pending, error)
+	DeleteByWishlist(ctx context.Context, wishlistID wishlistPkg.ID, before time.Time) error
+}
+
+type Service struct {
+	storage storage
+}
+
+func NewNewItemService(storage storage) *Service {
+	return &Service{
+		storage: storage,
+	}
+}
+
+// Add keeps the first addition time of the item, so a redelivered event doesn't postpone the announcement
+func (s *Service) Add(ctx context.Context, item *newitemPkg.Pending) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now().UTC()
+	}
+	return s.storage.Add(ctx, item)
+}
+
+// GetWishlistIDs returns wishlists having pending items added before the given time
+func (s *Service) GetWishlistIDs(ctx context.Context, addedBefore time.Time) ([]wishlistPkg.ID, error) {
+	return s.storage.GetWishlistIDs(ctx, addedBefore)
+}
+
+func (s *Service) GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*newitemPkg.Pending, error) {
+	return s.storage.GetByWishlist(ctx, wishlistID)
+}
+
+// DeleteByWishlist removes items added before the given time, so items added while announcing are kept
+func (s *Service) DeleteByWishlist(ctx context.Context, wishlistID wishlistPkg.ID, before time.Time) error {
+	return s.storage.DeleteByWishlist(ctx, wishlistID, before)
+}
@@ -0,0 +1,81 @@
+package inmemory
+
+import (
+	"context"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Storage struct {
+	items map[wishlist.ID][]*newitemPkg.Pending
+	lock  *sync.RWMutex
+}
+
+func NewNewItemInMemory() *Storage {
+	return &Storage{
+		items: map[wishlist.ID][]*newitemPkg.Pending{},
+		lock:  &sync.RWMutex{},
+	}
+}
+
+func (s *Storage) Add(_ context.Context, item *newitemPkg.Pending) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, i := range s.items[item.WishlistID] {
+		if i.ProductID == item.ProductID {
+			return nil
+		}
+	}
+	itemCopy := *item
+	s.items[item.WishlistID] = append(s.items[item.WishlistID], &itemCopy)
+	return nil
+}
+
+func (s *Storage) GetWishlistIDs(_ context.Context, addedBefore time.Time) ([]wishlist.ID, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	wishlistIDs := make([]wishlist.ID, 0, len(s.items))
+	for wishlistID, items := range s.items {
+		for _, item := range items {
+			if item.CreatedAt.Before(addedBefore) {
+				wishlistIDs = append(wishlistIDs, wishlistID)
+				break
+			}
+		}
+	}
+	return wishlistIDs, nil
+}
+
+func (s *Storage) GetByWishlist(_ context.Context, wishlistID wishlist.ID) ([]*newitemPkg.Pending, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	items := make([]*newitemPkg.Pending, 0, len(s.items[wishlistID]))
+	for _, item := range s.items[wishlistID] {
+		itemCopy := *item
+		items = append(items, &itemCopy)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].CreatedAt.Before(items[j].CreatedAt)
+	})
+	return items, nil
+}
+
+func (s *Storage) DeleteByWishlist(_ context.Context, wishlistID wishlist.ID, before time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var kept []*newitemPkg.Pending
+	for _, item := range s.items[wishlistID] {
+		if !item.CreatedAt.Before(before) {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == 0 {
+		delete(s.items, wishlistID)
+		return nil
+	}
+	s.items[wishlistID] = kept
+	return nil
+}
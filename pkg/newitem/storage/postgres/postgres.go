@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	"github.com/grulex/go-wishlist/pkg/product"
+	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type pendingPersistent struct {
+	WishlistID string    `db:"wishlist_id"`
+	ProductID  string    `db:"product_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (p pendingPersistent) ToPending() *newitemPkg.Pending {
+	return &newitemPkg.Pending{
+		WishlistID: wishlist.ID(p.WishlistID),
+		ProductID:  product.ID(p.ProductID),
+		CreatedAt:  p.CreatedAt,
+	}
+}
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+func NewNewItemStorage(db *sqlx.DB) *Storage {
+	return &Storage{db: db}
+}
+
+func (s *Storage) Add(ctx context.Context, item *newitemPkg.Pending) error {
+	query := `INSERT INTO new_item_pending (
+		wishlist_id,
+		product_id,
+		created_at
+	) VALUES (
+		:wishlist_id,
+		:product_id,
+		:created_at
+	) ON CONFLICT (wishlist_id, product_id) DO NOTHING`
+	_, err := s.db.NamedExecContext(ctx, query, pendingPersistent{
+		WishlistID: string(item.WishlistID),
+		ProductID:  string(item.ProductID),
+		CreatedAt:  item.CreatedAt,
+	})
+	return err
+}
+
+func (s *Storage) GetWishlistIDs(ctx context.Context, addedBefore time.Time) ([]wishlist.ID, error) {
+	query := `SELECT DISTINCT wishlist_id FROM new_item_pending WHERE created_at < $1`
+	wishlistIDs := make([]wishlist.ID, 0)
+	err := s.db.SelectContext(ctx, &wishlistIDs, query, addedBefore)
+	return wishlistIDs, err
+}
+
+func (s *Storage) GetByWishlist(ctx context.Context, wishlistID wishlist.ID) ([]*newitemPkg.Pending, error) {
+	query := `SELECT * FROM new_item_pending WHERE wishlist_id = $1 ORDER BY created_at`
+	itemsPersistent := make([]*pendingPersistent, 0)
+	err := s.db.SelectContext(ctx, &itemsPersistent, query, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*newitemPkg.Pending, 0, len(itemsPersistent))
+	for _, p := range itemsPersistent {
+		items = append(items, p.ToPending())
+	}
+	return items, nil
+}
+
+func (s *Storage) DeleteByWishlist(ctx context.Context, wishlistID wishlist.ID, before time.Time) error {
+	query := `DELETE FROM new_item_pending WHERE wishlist_id = $1 AND created_at < $2`
+	_, err := s.db.ExecContext(ctx, query, wishlistID, before)
+	return err
+}
@@ -0,0 +1,223 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/miniapp"
+	digestPkg "github.com/grulex/go-wishlist/pkg/digest"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// maxPartLength keeps a digest message within the Telegram limit of 4096 UTF-16 code units, leaving room for the heading
+const maxPartLength = 3800
+
+type digestService interface {
+	GetUserIDs(ctx context.Context) ([]userPkg.ID, error)
+	GetByUser(ctx context.Context, userID userPkg.ID) ([]*digestPkg.Entry, error)
+	DeleteByUser(ctx context.Context, userID userPkg.ID, before time.Time) error
+}
+
+type notifyService interface {
+	Notify(ctx context.Context, userID userPkg.ID, kind notify.EventKind, key string, params ...any) error
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+}
+
+type productService interface {
+	GetMany(ctx context.Context, ids []productPkg.ID) ([]*productPkg.Product, error)
+}
+
+type Sender struct {
+	digestService   digestService
+	notifyService   notifyService
+	userService     userService
+	wishlistService wishlistService
+	productService  productService
+	miniAppUrl      string
+}
+
+func NewDigestSender(
+	digestService digestService,
+	notifyService notifyService,
+	userService userService,
+	wishlistService wishlistService,
+	productService productService,
+	miniAppUrl string,
+) *Sender {
+	return &Sender{
+		digestService:   digestService,
+		notifyService:   notifyService,
+		userService:     userService,
+		wishlistService: wishlistService,
+		productService:  productService,
+		miniAppUrl:      miniAppUrl,
+	}
+}
+
+// SendDue sends digests to users whose digest hour is the given one. It fits scheduler.Job and is meant to run hourly.
+func (s *Sender) SendDue(ctx context.Context, at time.Time) error {
+	userIDs, err := s.digestService.GetUserIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, userID := range userIDs {
+		user, err := s.userService.Get(ctx, userID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !user.IsDigestDue(at) {
+			continue
+		}
+		err = s.send(ctx, user, at)
+		if err != nil {
+			lastErr = fmt.Errorf("send digest to user %s: %w", userID, err)
+		}
+	}
+	return lastErr
+}
+
+func (s *Sender) send(ctx context.Context, user *userPkg.User, at time.Time) error {
+	entries, err := s.digestService.GetByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	entries = filterBefore(entries, at)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	parts, err := s.makeDigestParts(ctx, entries)
+	if err != nil {
+		return err
+	}
+	key := "notify_digest_daily"
+	if user.NotifyDelivery == notify.DeliveryWeekly {
+		key = "notify_digest_weekly"
+	}
+	for i, part := range parts {
+		if i > 0 {
+			key = "notify_digest_continued"
+		}
+		err = s.notifyService.Notify(ctx, user.ID, notify.EventKindDigest, key, part)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.digestService.DeleteByUser(ctx, user.ID, at)
+}
+
+func filterBefore(entries []*digestPkg.Entry, at time.Time) []*digestPkg.Entry {
+	filtered := make([]*digestPkg.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(at) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// makeDigestParts groups entries by wishlist: "+" for new wishes, "−" for removed ones and the price change for the rest.
+// The text is split into parts of at most maxPartLength, each part is sent as a separate message.
+func (s *Sender) makeDigestParts(ctx context.Context, entries []*digestPkg.Entry) ([]string, error) {
+	productIDs := make([]productPkg.ID, 0, len(entries))
+	wishlistIDs := make([]wishlistPkg.ID, 0)
+	entriesByWishlist := make(map[wishlistPkg.ID][]*digestPkg.Entry)
+	for _, entry := range entries {
+		productIDs = append(productIDs, entry.ProductID)
+		if _, ok := entriesByWishlist[entry.WishlistID]; !ok {
+			wishlistIDs = append(wishlistIDs, entry.WishlistID)
+		}
+		entriesByWishlist[entry.WishlistID] = append(entriesByWishlist[entry.WishlistID], entry)
+	}
+
+	products, err := s.productService.GetMany(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	productsByID := make(map[productPkg.ID]*productPkg.Product, len(products))
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	parts := make([]string, 0, 1)
+	current := ""
+	appendBlock := func(block, separator string) {
+		if current != "" && textLength(current)+textLength(separator)+textLength(block) > maxPartLength {
+			parts = append(parts, current)
+			current = ""
+		}
+		if current != "" {
+			current += separator
+		}
+		current += block
+	}
+	for _, wishlistID := range wishlistIDs {
+		wishlist, err := s.wishlistService.Get(ctx, wishlistID)
+		if err != nil {
+			return nil, err
+		}
+
+		lines := []string{fmt.Sprintf("*[%s](%s)*", notify.EscapeMarkdownLinkText(wishlist.Title), miniapp.MakeLinkToWishlist(s.miniAppUrl, wishlistID))}
+		for _, entry := range entriesByWishlist[wishlistID] {
+			product, ok := productsByID[entry.ProductID]
+			if !ok {
+				continue
+			}
+			link := miniapp.MakeLinkToItem(s.miniAppUrl, wishlistID, product.ID)
+			switch entry.Type {
+			case digestPkg.EntryTypeNewItem:
+				lines = append(lines, fmt.Sprintf("+ [%s](%s)", notify.EscapeMarkdownLinkText(product.Title), link))
+			case digestPkg.EntryTypeRemovedItem:
+				lines = append(lines, fmt.Sprintf("− %s", notify.EscapeMarkdown(product.Title)))
+			case digestPkg.EntryTypePriceChanged:
+				lines = append(lines, fmt.Sprintf("• [%s](%s): %s → %s", notify.EscapeMarkdownLinkText(product.Title), link,
+					formatPrice(entry.OldPrice), formatPrice(entry.NewPrice)))
+			}
+		}
+		if len(lines) == 1 {
+			continue
+		}
+		if block := strings.Join(lines, "\n"); textLength(block) <= maxPartLength {
+			appendBlock(block, "\n\n")
+			continue
+		}
+		// a wishlist with too many changes is split by lines, titles are short enough to fit a line into a part
+		appendBlock(lines[0], "\n\n")
+		for _, line := range lines[1:] {
+			appendBlock(line, "\n")
+		}
+	}
+	if current != "" {
+		parts = append(parts, current)
+	}
+	return parts, nil
+}
+
+// textLength counts the text the way Telegram limits it, in UTF-16 code units
+func textLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+func formatPrice(price *currency.Amount) string {
+	if price == nil {
+		return "—"
+	}
+	return price.String()
+}
@@ -0,0 +1,225 @@
+package newitems
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/grulex/go-wishlist/miniapp"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"strings"
+	"time"
+)
+
+const (
+	maxItemsInMessage = 10
+	// maxTitleLength keeps ten titles within the photo caption limit together with the message text
+	maxTitleLength = 64
+)
+
+type newItemService interface {
+	GetWishlistIDs(ctx context.Context, addedBefore time.Time) ([]wishlistPkg.ID, error)
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*newitemPkg.Pending, error)
+	DeleteByWishlist(ctx context.Context, wishlistID wishlistPkg.ID, before time.Time) error
+}
+
+type notifyService interface {
+	NotifyWithImage(ctx context.Context, userID userPkg.ID, kind notify.EventKind, imageUrl string, key string, params ...any) error
+}
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanNotify(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID) (bool, error)
+}
+
+type subscribeService interface {
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*subscribePkg.Subscribe, error)
+}
+
+type productService interface {
+	GetMany(ctx context.Context, ids []productPkg.ID) ([]*productPkg.Product, error)
+}
+
+type imageService interface {
+	Get(ctx context.Context, id imagePkg.ID) (*imagePkg.Image, error)
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+type Sender struct {
+	newItemService   newItemService
+	notifyService    notifyService
+	wishlistService  wishlistService
+	subscribeService subscribeService
+	productService   productService
+	imageService     imageService
+	userService      userService
+	miniAppUrl       string
+	apiUrl           string
+	window           time.Duration
+}
+
+func NewNewItemsSender(
+	newItemService newItemService,
+	notifyService notifyService,
+	wishlistService wishlistService,
+	subscribeService subscribeService,
+	productService productService,
+	imageService imageService,
+	userService userService,
+	miniAppUrl string,
+	apiUrl string,
+	window time.Duration,
+) *Sender {
+	return &Sender{
+		newItemService:   newItemService,
+		notifyService:    notifyService,
+		wishlistService:  wishlistService,
+		subscribeService: subscribeService,
+		productService:   productService,
+		imageService:     imageService,
+		userService:      userService,
+		miniAppUrl:       miniAppUrl,
+		apiUrl:           apiUrl,
+		window:           window,
+	}
+}
+
+// SendDue announces the items of the wishlists whose first pending item was added at least the window ago,
+// so a burst of additions results in a single notification. It fits scheduler.Job.
+func (s *Sender) SendDue(ctx context.Context, at time.Time) error {
+	wishlistIDs, err := s.newItemService.GetWishlistIDs(ctx, at.Add(-s.window))
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, wishlistID := range wishlistIDs {
+		err = s.send(ctx, wishlistID, at)
+		if err != nil {
+			lastErr = fmt.Errorf("announce new items of wishlist %s: %w", wishlistID, err)
+		}
+	}
+	return lastErr
+}
+
+// send keeps the pending items if the message can't be prepared, so the next run retries.
+// Once it is sent to anyone, the items are removed and the failed subscribers are only reported.
+func (s *Sender) send(ctx context.Context, wishlistID wishlistPkg.ID, at time.Time) error {
+	wishlist, err := s.wishlistService.Get(ctx, wishlistID)
+	if errors.Is(err, wishlistPkg.ErrNotFound) {
+		return s.newItemService.DeleteByWishlist(ctx, wishlistID, at)
+	}
+	if err != nil {
+		return err
+	}
+	items, err := s.newItemService.GetByWishlist(ctx, wishlistID)
+	if err != nil {
+		return err
+	}
+	productIDs := make([]productPkg.ID, 0, len(items))
+	for _, item := range items {
+		if item.CreatedAt.Before(at) {
+			productIDs = append(productIDs, item.ProductID)
+		}
+	}
+	if len(productIDs) == 0 {
+		return nil
+	}
+	products, err := s.productService.GetMany(ctx, productIDs)
+	if err != nil {
+		return err
+	}
+	subscribes, err := s.subscribeService.GetByWishlist(ctx, wishlistID)
+	if err != nil {
+		return err
+	}
+	imageUrl, err := s.getImageUrl(ctx, products)
+	if err != nil {
+		return err
+	}
+
+	var sendErrs []error
+	if len(products) > 0 {
+		list := s.makeItemsList(wishlistID, products)
+		wishlistLink := miniapp.MakeLinkToWishlist(s.miniAppUrl, wishlistID)
+		for _, subscribe := range subscribes {
+			err = s.notifySubscriber(ctx, wishlist, subscribe, imageUrl, wishlistLink, list)
+			if err != nil {
+				sendErrs = append(sendErrs, fmt.Errorf("notify user %s: %w", subscribe.UserID, err))
+			}
+		}
+	}
+
+	err = s.newItemService.DeleteByWishlist(ctx, wishlistID, at)
+	if err != nil {
+		return err
+	}
+	return errors.Join(sendErrs...)
+}
+
+func (s *Sender) notifySubscriber(
+	ctx context.Context,
+	wishlist *wishlistPkg.Wishlist,
+	subscribe *subscribePkg.Subscribe,
+	imageUrl, wishlistLink, list string,
+) error {
+	if !subscribe.IsNotifyEnabled || subscribe.UserID == wishlist.UserID {
+		return nil
+	}
+	canNotify, err := s.wishlistService.CanNotify(ctx, wishlist, subscribe.UserID)
+	if err != nil || !canNotify {
+		return err
+	}
+	user, err := s.userService.Get(ctx, subscribe.UserID)
+	if err != nil {
+		return err
+	}
+	if user.IsDigestDelivery() {
+		// collected by the digest subscriber instead
+		return nil
+	}
+	return s.notifyService.NotifyWithImage(ctx, subscribe.UserID, notify.EventKindNewItems, imageUrl, "notify_wishlist_new_items",
+		notify.EscapeMarkdownLinkText(wishlist.Title), wishlistLink, list)
+}
+
+func (s *Sender) makeItemsList(wishlistID wishlistPkg.ID, products []*productPkg.Product) string {
+	lines := make([]string, 0, maxItemsInMessage+1)
+	for i, product := range products {
+		if i == maxItemsInMessage {
+			break
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, wishlistID, product.ID)
+		lines = append(lines, fmt.Sprintf("• [%s](%s)", notify.EscapeMarkdownLinkText(truncate(product.Title, maxTitleLength)), link))
+	}
+	if len(products) > len(lines) {
+		lines = append(lines, fmt.Sprintf("• … +%d", len(products)-len(lines)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Sender) getImageUrl(ctx context.Context, products []*productPkg.Product) (string, error) {
+	if len(products) == 0 || products[0].ImageID == nil {
+		return "", nil
+	}
+	image, err := s.imageService.Get(ctx, *products[0].ImageID)
+	if err != nil || image == nil {
+		return "", err
+	}
+	return image.FileLink.Url(s.apiUrl), nil
+}
+
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+}
@@ -1,7 +1,88 @@
 package notify
 
+import (
+	"errors"
+	"strings"
+)
+
+var ErrSenderNotDefined = errors.New("notify sender not defined")
+
+// ErrChannelUnavailable is returned by senders when the channel is gone for good, e.g. the user blocked the bot
+var ErrChannelUnavailable = errors.New("notify channel unavailable")
+
 type Type string
 
 const (
 	TypeTelegram Type = "telegram"
+	TypeEmail    Type = "email"
+)
+
+// Delivery defines how changes of followed wishlists are delivered
+type Delivery string
+
+const (
+	DeliveryInstant Delivery = "instant"
+	DeliveryDaily   Delivery = "daily"
+	DeliveryWeekly  Delivery = "weekly"
+)
+
+const DefaultDigestHour = 9
+
+// EventKind groups notifications, so the user can turn each group off
+type EventKind string
+
+const (
+	EventKindBooking      EventKind = "booking"
+	EventKindNewItems     EventKind = "new_items"
+	EventKindRemovedItems EventKind = "removed_items"
+	EventKindPriceChanges EventKind = "price_changes"
+	EventKindOccasions    EventKind = "occasions"
+	// EventKindDigest is controlled by the delivery setting only and can't be muted
+	EventKindDigest EventKind = "digest"
 )
+
+// EventKinds are the kinds the user is able to mute
+var EventKinds = []EventKind{
+	EventKindBooking,
+	EventKindNewItems,
+	EventKindRemovedItems,
+	EventKindPriceChanges,
+	EventKindOccasions,
+}
+
+func IsValidEventKind(kind EventKind) bool {
+	for _, k := range EventKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+type Message struct {
+	Type      Type
+	ChannelID string
+	Text      string
+	ImageUrl  string
+	Language  string
+	// IsSilent asks the sender to deliver the message without sound, e.g. during quiet hours
+	IsSilent bool
+}
+
+var markdownEscaper = strings.NewReplacer("_", `\_`, "*", `\*`, "`", "\\`", "[", `\[`)
+
+// EscapeMarkdown escapes the user's text, e.g. a name or a title, interpolated into the Markdown of a message,
+// so its characters are shown as they are and don't break the markup
+func EscapeMarkdown(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// linkTextStripper removes the brackets, Markdown has no escaping for them inside the text of a link
+var linkTextStripper = strings.NewReplacer("[", "", "]", "", "(", "", ")", "")
+
+// EscapeMarkdownLinkText prepares the user's text for the text of a link, e.g. the title in "[%s](%s)".
+// The link text ends at the first "]", so the brackets are removed for the text not to close it early
+// and put a link of its own into the message.
+func EscapeMarkdownLinkText(text string) string {
+	return EscapeMarkdown(linkTextStripper.Replace(text))
+}
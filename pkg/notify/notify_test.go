@@ -0,0 +1,47 @@
+package notify
+
+import "testing"
+
+func TestEscapeMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		escaped  string
+		linkText string
+	}{
+		{
+			name:     "plain text",
+			text:     "Lego set",
+			escaped:  "Lego set",
+			linkText: "Lego set",
+		},
+		{
+			name:     "markup characters",
+			text:     "my_wish *bold* `code` [x",
+			escaped:  "my\\_wish \\*bold\\* \\`code\\` \\[x",
+			linkText: "my\\_wish \\*bold\\* \\`code\\` x",
+		},
+		{
+			name:     "link injection",
+			text:     "x](https://evil.example)",
+			escaped:  "x](https://evil.example)",
+			linkText: "xhttps://evil.example",
+		},
+		{
+			name:     "parentheses",
+			text:     "Kettle (white)",
+			escaped:  "Kettle (white)",
+			linkText: "Kettle white",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeMarkdown(tt.text); got != tt.escaped {
+				t.Errorf("EscapeMarkdown(%q) = %q, expected %q", tt.text, got, tt.escaped)
+			}
+			if got := EscapeMarkdownLinkText(tt.text); got != tt.linkText {
+				t.Errorf("EscapeMarkdownLinkText(%q) = %q, expected %q", tt.text, got, tt.linkText)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	"github.com/grulex/go-wishlist/translate"
+	htmlTemplate "html/template"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	textTemplate "text/template"
+	"time"
+)
+
+// smtpTimeout limits the whole conversation with the SMTP server, from dialing to QUIT
+var smtpTimeout = 30 * time.Second
+
+//go:embed templates
+var templates embed.FS
+
+var (
+	markdownLink = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownBold = regexp.MustCompile(`\*([^*\n]+)\*`)
+	// the characters escaped by notify.EscapeMarkdown are hidden from the conversion and restored after it
+	markdownEscaped   = strings.NewReplacer(`\_`, "\uE000", `\*`, "\uE001", "\\`", "\uE002", `\[`, "\uE003")
+	markdownUnescaped = strings.NewReplacer("\uE000", "_", "\uE001", "*", "\uE002", "`", "\uE003", "[")
+)
+
+type Config struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+type Sender struct {
+	config       Config
+	translator   *translate.Translator
+	htmlTemplate *htmlTemplate.Template
+	textTemplate *textTemplate.Template
+}
+
+func NewEmailSender(config Config, translator *translate.Translator) *Sender {
+	return &Sender{
+		config:       config,
+		translator:   translator,
+		htmlTemplate: htmlTemplate.Must(htmlTemplate.ParseFS(templates, "templates/message.html")),
+		textTemplate: textTemplate.Must(textTemplate.ParseFS(templates, "templates/message.txt")),
+	}
+}
+
+type templateData struct {
+	Language string
+	Subject  string
+	Body     any
+	ImageUrl string
+	Footer   string
+}
+
+// Send renders the markdown text of the message into the html and plain text parts of the email
+func (s Sender) Send(ctx context.Context, message notify.Message) error {
+	data := templateData{
+		Language: message.Language,
+		Subject:  s.translator.Translate(message.Language, "email_subject"),
+		ImageUrl: message.ImageUrl,
+		Footer:   s.translator.Translate(message.Language, "email_footer"),
+	}
+
+	data.Body = htmlTemplate.HTML(markdownToHtml(message.Text))
+	htmlBody := &bytes.Buffer{}
+	if err := s.htmlTemplate.Execute(htmlBody, data); err != nil {
+		return err
+	}
+	data.Body = markdownToText(message.Text)
+	textBody := &bytes.Buffer{}
+	if err := s.textTemplate.Execute(textBody, data); err != nil {
+		return err
+	}
+
+	body, err := s.makeMessage(message.ChannelID, data.Subject, textBody.Bytes(), htmlBody.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = s.sendMail(ctx, message.ChannelID, body)
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code == 550 {
+		// mailbox doesn't exist
+		return fmt.Errorf("%w: %s", notify.ErrChannelUnavailable, smtpErr.Msg)
+	}
+	return err
+}
+
+func (s Sender) GetType() notify.Type {
+	return notify.TypeEmail
+}
+
+// sendMail does what smtp.SendMail does, but gives up on the context cancellation and after smtpTimeout
+func (s Sender) sendMail(ctx context.Context, to string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.config.Host, strconv.Itoa(s.config.Port)))
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err = conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	// closing the connection interrupts the command in progress when the context is canceled
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	err = s.talk(conn, to, body)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+func (s Sender) talk(conn net.Conn, to string, body []byte) error {
+	client, err := smtp.NewClient(conn, s.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func(client *smtp.Client) {
+		_ = client.Close()
+	}(client)
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.config.Host}); err != nil {
+			return err
+		}
+	}
+	if s.config.User != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("smtp: server doesn't support AUTH")
+		}
+		if err = client.Auth(smtp.PlainAuth("", s.config.User, s.config.Password, s.config.Host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(s.config.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(body); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s Sender) makeMessage(to, subject string, textBody, htmlBody []byte) ([]byte, error) {
+	boundaryBytes := make([]byte, 12)
+	if _, err := rand.Read(boundaryBytes); err != nil {
+		return nil, err
+	}
+	boundary := hex.EncodeToString(boundaryBytes)
+
+	message := &bytes.Buffer{}
+	message.WriteString("From: " + s.config.From + "\r\n")
+	message.WriteString("To: " + to + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: multipart/alternative; boundary=" + boundary + "\r\n\r\n")
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain", textBody},
+		{"text/html", htmlBody},
+	} {
+		message.WriteString("--" + boundary + "\r\n")
+		message.WriteString("Content-Type: " + part.contentType + "; charset=utf-8\r\n")
+		message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		writer := quotedprintable.NewWriter(message)
+		if _, err := writer.Write(part.body); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		message.WriteString("\r\n")
+	}
+	message.WriteString("--" + boundary + "--\r\n")
+	return message.Bytes(), nil
+}
+
+// markdownToHtml supports the subset used in the translations: links, bold text and line breaks
+func markdownToHtml(text string) string {
+	text = htmlTemplate.HTMLEscapeString(markdownEscaped.Replace(text))
+	text = markdownLink.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	text = markdownBold.ReplaceAllString(text, `<b>$1</b>`)
+	return markdownUnescaped.Replace(strings.ReplaceAll(text, "\n", "<br>\n"))
+}
+
+func markdownToText(text string) string {
+	text = markdownLink.ReplaceAllString(markdownEscaped.Replace(text), `$1 ($2)`)
+	return markdownUnescaped.Replace(markdownBold.ReplaceAllString(text, `$1`))
+}
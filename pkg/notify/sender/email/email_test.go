@@ -0,0 +1,214 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grulex/go-wishlist/pkg/notify"
+	"github.com/grulex/go-wishlist/translate"
+)
+
+type received struct {
+	from string
+	to   string
+	data string
+}
+
+// startSmtpServer accepts one connection and talks plain SMTP without STARTTLS and AUTH.
+// A silent server accepts the connection, but never answers.
+func startSmtpServer(t *testing.T, silent bool) (Config, <-chan received) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	messages := make(chan received, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = conn.Close()
+		}()
+		if silent {
+			_, _ = io.Copy(io.Discard, conn)
+			return
+		}
+
+		text := textproto.NewConn(conn)
+		var message received
+		_ = text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"):
+				_ = text.PrintfLine("250-localhost")
+				_ = text.PrintfLine("250 8BITMIME")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				message.from = address(line)
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				message.to = address(line)
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 go ahead")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				message.data = string(data)
+				_ = text.PrintfLine("250 OK")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 bye")
+				messages <- message
+				return
+			default:
+				_ = text.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return Config{Host: host, Port: portNumber, From: "wishlist@example.com"}, messages
+}
+
+// address takes the path in angle brackets out of the MAIL and RCPT commands
+func address(command string) string {
+	start, end := strings.Index(command, "<"), strings.Index(command, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return command[start+1 : end]
+}
+
+func readParts(t *testing.T, data string) map[string]string {
+	t.Helper()
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to read the message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected a multipart/alternative message, got %q (%v)", mediaType, err)
+	}
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("failed to read a part: %v", err)
+		}
+		if encoding := part.Header.Get("Content-Transfer-Encoding"); encoding != "quoted-printable" {
+			t.Fatalf("unexpected transfer encoding %q", encoding)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("failed to decode a part: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(body)
+	}
+	return parts
+}
+
+func TestSendDeliversHtmlAndTextParts(t *testing.T) {
+	config, messages := startSmtpServer(t, false)
+	sender := NewEmailSender(config, translate.NewTranslator("en"))
+
+	err := sender.Send(context.Background(), notify.Message{
+		ChannelID: "user@example.com",
+		Text:      "*Bike* was booked, see [the wishlist](https://example.com/w/1)\nsnake\\_case",
+		Language:  "en",
+	})
+	if err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+
+	var message received
+	select {
+	case message = <-messages:
+	case <-time.After(time.Second):
+		t.Fatal("server didn't receive the message")
+	}
+	if message.from != "wishlist@example.com" || message.to != "user@example.com" {
+		t.Fatalf("unexpected envelope from %q to %q", message.from, message.to)
+	}
+
+	parts := readParts(t, message.data)
+	html, text := parts["text/html"], parts["text/plain"]
+	for _, expected := range []string{"<b>Bike</b>", `<a href="https://example.com/w/1">the wishlist</a>`, "</a><br>", "News from your wishlists"} {
+		if !strings.Contains(html, expected) {
+			t.Fatalf("expected %q in the html part:\n%s", expected, html)
+		}
+	}
+	for _, expected := range []string{"Bike was booked, see the wishlist (https://example.com/w/1)", "snake_case"} {
+		if !strings.Contains(text, expected) {
+			t.Fatalf("expected %q in the text part:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "*") || strings.Contains(text, "<b>") {
+		t.Fatalf("expected no markup in the text part:\n%s", text)
+	}
+}
+
+func TestSendGivesUpAfterTimeout(t *testing.T) {
+	defaultTimeout := smtpTimeout
+	smtpTimeout = 100 * time.Millisecond
+	t.Cleanup(func() {
+		smtpTimeout = defaultTimeout
+	})
+	config, _ := startSmtpServer(t, true)
+	sender := NewEmailSender(config, translate.NewTranslator("en"))
+
+	start := time.Now()
+	err := sender.Send(context.Background(), notify.Message{ChannelID: "user@example.com", Text: "text", Language: "en"})
+	// the connection deadline may fire a moment before the context one
+	if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected to give up after the timeout, took %v", elapsed)
+	}
+}
+
+func TestSendStopsOnContextCancellation(t *testing.T) {
+	config, _ := startSmtpServer(t, true)
+	sender := NewEmailSender(config, translate.NewTranslator("en"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	err := sender.Send(ctx, notify.Message{ChannelID: "user@example.com", Text: "text", Language: "en"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected to stop on the cancellation, took %v", elapsed)
+	}
+}
@@ -0,0 +1,33 @@
+package inmemory
+
+import (
+	"context"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	"sync"
+)
+
+type Sender struct {
+	Messages   []notify.Message
+	Lock       *sync.RWMutex
+	notifyType notify.Type
+}
+
+// NewSenderInMemory creates a fake sender which keeps all sent messages instead of delivering them.
+func NewSenderInMemory(notifyType notify.Type) *Sender {
+	return &Sender{
+		Messages:   []notify.Message{},
+		Lock:       &sync.RWMutex{},
+		notifyType: notifyType,
+	}
+}
+
+func (s *Sender) Send(_ context.Context, message notify.Message) error {
+	s.Lock.Lock()
+	s.Messages = append(s.Messages, message)
+	s.Lock.Unlock()
+	return nil
+}
+
+func (s *Sender) GetType() notify.Type {
+	return s.notifyType
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	"net/http"
+	"strconv"
+	"unicode/utf16"
+)
+
+// maxCaptionLength is the Telegram limit of a photo caption in UTF-16 code units
+const maxCaptionLength = 1024
+
+type Sender struct {
+	tgBot *tgbotapi.BotAPI
+}
+
+func NewTelegramSender(token string) *Sender {
+	tgBot, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		panic(err)
+	}
+	return &Sender{
+		tgBot: tgBot,
+	}
+}
+
+func (s Sender) Send(_ context.Context, message notify.Message) error {
+	chatID, err := strconv.ParseInt(message.ChannelID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	// a longer caption is rejected, cutting it could break the markup, so it goes as a text message
+	if message.ImageUrl != "" && len(utf16.Encode([]rune(message.Text))) <= maxCaptionLength {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileURL(message.ImageUrl))
+		photo.Caption = message.Text
+		photo.ParseMode = tgbotapi.ModeMarkdown
+		photo.DisableNotification = message.IsSilent
+		_, err = s.tgBot.Send(photo)
+		return wrapSendError(err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, message.Text)
+	msg.ParseMode = tgbotapi.ModeMarkdown
+	msg.DisableWebPagePreview = true
+	msg.DisableNotification = message.IsSilent
+	_, err = s.tgBot.Send(msg)
+	return wrapSendError(err)
+}
+
+func wrapSendError(err error) error {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.Code == http.StatusForbidden {
+		// "bot was blocked by the user", "user is deactivated" and so on
+		return fmt.Errorf("%w: %s", notify.ErrChannelUnavailable, tgErr.Message)
+	}
+	return err
+}
+
+func (s Sender) GetType() notify.Type {
+	return notify.TypeTelegram
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	"github.com/grulex/go-wishlist/translate"
+	"time"
+)
+
+type Sender interface {
+	Send(ctx context.Context, message notify.Message) error
+	GetType() notify.Type
+}
+
+type userService interface {
+	Get(ctx context.Context, userID userPkg.ID) (*userPkg.User, error)
+	Update(ctx context.Context, user *userPkg.User) error
+}
+
+type Service struct {
+	senders     map[notify.Type]Sender
+	userService userService
+	translator  *translate.Translator
+}
+
+func NewNotifyService(senders []Sender, userService userService, translator *translate.Translator) *Service {
+	sendersMap := make(map[notify.Type]Sender, len(senders))
+	for _, s := range senders {
+		sendersMap[s.GetType()] = s
+	}
+
+	return &Service{
+		senders:     sendersMap,
+		userService: userService,
+		translator:  translator,
+	}
+}
+
+// Notify renders the translation key in the user's language and sends it to the user's notify channel.
+// Users without a notify channel or with the event kind muted are silently skipped.
+func (s *Service) Notify(ctx context.Context, userID userPkg.ID, kind notify.EventKind, key string, params ...any) error {
+	return s.NotifyWithImage(ctx, userID, kind, "", key, params...)
+}
+
+// NotifyWithImage works like Notify, attaching the image by its public url to the message.
+func (s *Service) NotifyWithImage(ctx context.Context, userID userPkg.ID, kind notify.EventKind, imageUrl string, key string, params ...any) error {
+	user, err := s.userService.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	channelID, ok := user.GetNotifyChannelID()
+	if !ok {
+		return nil
+	}
+	if !user.IsNotifyEventEnabled(kind) {
+		return nil
+	}
+
+	sender, ok := s.senders[*user.NotifyType]
+	if !ok {
+		return notify.ErrSenderNotDefined
+	}
+
+	err = sender.Send(ctx, notify.Message{
+		Type:      *user.NotifyType,
+		ChannelID: channelID,
+		Text:      s.translator.Translate(string(user.Language), key, params...),
+		ImageUrl:  imageUrl,
+		Language:  string(user.Language),
+		IsSilent:  user.IsQuietAt(time.Now()),
+	})
+	if errors.Is(err, notify.ErrChannelUnavailable) {
+		// stop messaging a dead channel, it is restored when the user comes back
+		if *user.NotifyType == notify.TypeEmail {
+			user.IsEmailVerified = false
+		} else {
+			user.NotifyChannelID = nil
+		}
+		user.NotifyType = nil
+		return s.userService.Update(ctx, user)
+	}
+	return err
+}
+
+// NotifyChannel sends the message to the given channel regardless of the user's notify settings,
+// e.g. a verification code to an address which isn't verified yet.
+func (s *Service) NotifyChannel(ctx context.Context, userID userPkg.ID, notifyType notify.Type, channelID string, key string, params ...any) error {
+	user, err := s.userService.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	sender, ok := s.senders[notifyType]
+	if !ok {
+		return notify.ErrSenderNotDefined
+	}
+
+	return sender.Send(ctx, notify.Message{
+		Type:      notifyType,
+		ChannelID: channelID,
+		Text:      s.translator.Translate(string(user.Language), key, params...),
+		Language:  string(user.Language),
+	})
+}
@@ -0,0 +1,31 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grulex/go-wishlist/miniapp"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/events/wish"
+	"github.com/grulex/go-wishlist/pkg/notify"
+)
+
+const bookingExpiryLayout = "02.01.2006 15:04 UTC"
+
+func (s *Subscriber) onWishBookingExpiring() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var expiringPayload wish.BookingExpiringPayload
+		err := json.Unmarshal(payload, &expiringPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		product, err := s.productService.Get(ctx, expiringPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, expiringPayload.ItemID.WishlistID, product.ID)
+
+		return s.notifyService.Notify(ctx, expiringPayload.BookedBy, notify.EventKindBooking, "notify_wish_booking_expiring",
+			notify.EscapeMarkdownLinkText(product.Title), link, expiringPayload.ExpiresAt.UTC().Format(bookingExpiryLayout))
+	}
+}
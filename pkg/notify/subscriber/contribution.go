@@ -0,0 +1,77 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grulex/go-wishlist/miniapp"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/events/wish"
+	"github.com/grulex/go-wishlist/pkg/notify"
+)
+
+func (s *Subscriber) onWishContributionUpdate() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var contributionPayload wish.ContributionPayload
+		err := json.Unmarshal(payload, &contributionPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		product, err := s.productService.Get(ctx, contributionPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, contributionPayload.ItemID.WishlistID, product.ID)
+
+		if contributionPayload.NewAmount != nil {
+			err = s.notifyService.Notify(ctx, contributionPayload.Contributor, notify.EventKindBooking, "notify_wish_contributed_by_you",
+				contributionPayload.NewAmount.String(), notify.EscapeMarkdownLinkText(product.Title), link, contributionPayload.Progress)
+			if err != nil {
+				return err
+			}
+		}
+
+		if contributionPayload.IsFullyCovered == contributionPayload.WasFullyCovered {
+			return nil
+		}
+
+		contributions, err := s.wishlistService.GetContributions(ctx, contributionPayload.ItemID)
+		if err != nil {
+			return err
+		}
+		for _, contribution := range contributions {
+			if contributionPayload.IsFullyCovered {
+				s.notifyRecipient(ctx, contribution.UserID, notify.EventKindBooking, "notify_wish_fully_covered_for_contributor", notify.EscapeMarkdownLinkText(product.Title), link)
+			} else {
+				s.notifyRecipient(ctx, contribution.UserID, notify.EventKindBooking, "notify_wish_coverage_lost_for_contributor", notify.EscapeMarkdownLinkText(product.Title), link, contributionPayload.Progress)
+			}
+		}
+
+		return nil
+	}
+}
+
+// onWishContributionUpdateForOwner tells the owner that the wish is fully covered, unless it is a surprise
+func (s *Subscriber) onWishContributionUpdateForOwner() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var contributionPayload wish.ContributionPayload
+		err := json.Unmarshal(payload, &contributionPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+		if !contributionPayload.IsFullyCovered || contributionPayload.WasFullyCovered {
+			return nil
+		}
+
+		wishlist, err := s.wishlistService.Get(ctx, contributionPayload.ItemID.WishlistID)
+		if err != nil || wishlist.IsSurpriseMode {
+			return err
+		}
+		product, err := s.productService.Get(ctx, contributionPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, contributionPayload.ItemID.WishlistID, product.ID)
+		return s.notifyService.Notify(ctx, contributionPayload.WishOwner, notify.EventKindBooking, "notify_wish_fully_covered_for_owner", notify.EscapeMarkdownLinkText(product.Title), link)
+	}
+}
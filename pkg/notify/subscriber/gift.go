@@ -0,0 +1,112 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grulex/go-wishlist/miniapp"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/events/wish"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	"strings"
+)
+
+// onWishItemFulfilled tells the givers that the owner has received their gift
+func (s *Subscriber) onWishItemFulfilled() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var giftPayload wish.GiftPayload
+		err := json.Unmarshal(payload, &giftPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+		givers := excludeUser(giftPayload.Givers, giftPayload.WishOwner)
+		if len(givers) == 0 {
+			return nil
+		}
+
+		product, err := s.productService.Get(ctx, giftPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		owner, err := s.userService.Get(ctx, giftPayload.WishOwner)
+		if err != nil {
+			return err
+		}
+		for _, giverID := range givers {
+			s.notifyRecipient(ctx, giverID, notify.EventKindBooking, "notify_gift_received_for_giver",
+				notify.EscapeMarkdown(owner.FullName), notify.EscapeMarkdown(product.Title))
+		}
+		return nil
+	}
+}
+
+// onWishItemFulfilledForOwner reminds the owner to thank the givers
+func (s *Subscriber) onWishItemFulfilledForOwner() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var giftPayload wish.GiftPayload
+		err := json.Unmarshal(payload, &giftPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+		givers := excludeUser(giftPayload.Givers, giftPayload.WishOwner)
+		if len(givers) == 0 {
+			return nil
+		}
+
+		product, err := s.productService.Get(ctx, giftPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		giverNames := make([]string, 0, len(givers))
+		for _, giverID := range givers {
+			giver, err := s.userService.Get(ctx, giverID)
+			if err != nil {
+				return err
+			}
+			giverNames = append(giverNames, notify.EscapeMarkdown(giver.FullName))
+		}
+
+		link := miniapp.MakeLinkToGifts(s.miniAppUrl)
+		return s.notifyService.Notify(ctx, giftPayload.WishOwner, notify.EventKindBooking, "notify_gift_received_for_owner",
+			notify.EscapeMarkdown(product.Title), strings.Join(giverNames, ", "), link)
+	}
+}
+
+func (s *Subscriber) onWishItemThanked() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var giftPayload wish.GiftPayload
+		err := json.Unmarshal(payload, &giftPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		product, err := s.productService.Get(ctx, giftPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		owner, err := s.userService.Get(ctx, giftPayload.WishOwner)
+		if err != nil {
+			return err
+		}
+		for _, giverID := range excludeUser(giftPayload.Givers, giftPayload.WishOwner) {
+			if giftPayload.Message == "" {
+				s.notifyRecipient(ctx, giverID, notify.EventKindBooking, "notify_gift_thanks",
+					notify.EscapeMarkdown(owner.FullName), notify.EscapeMarkdown(product.Title))
+			} else {
+				s.notifyRecipient(ctx, giverID, notify.EventKindBooking, "notify_gift_thanks_with_message",
+					notify.EscapeMarkdown(owner.FullName), notify.EscapeMarkdown(product.Title), notify.EscapeMarkdown(giftPayload.Message))
+			}
+		}
+		return nil
+	}
+}
+
+func excludeUser(userIDs []userPkg.ID, excluded userPkg.ID) []userPkg.ID {
+	result := make([]userPkg.ID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if userID != excluded {
+			result = append(result, userID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/events/wish"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+)
+
+// onWishItemAdded stores the item until the new items sender announces it with the others added meanwhile
+func (s *Subscriber) onWishItemAdded() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var itemPayload wish.ItemPayload
+		err := json.Unmarshal(payload, &itemPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		return s.newItemService.Add(ctx, &newitemPkg.Pending{
+			WishlistID: itemPayload.ItemID.WishlistID,
+			ProductID:  itemPayload.ItemID.ProductID,
+			CreatedAt:  itemPayload.EventAt,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/miniapp"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	wishlistEvents "github.com/grulex/go-wishlist/pkg/events/wishlist"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+const (
+	occasionDateLayout = "02.01.2006"
+	occasionItemsLimit = 1000
+)
+
+// onWishlistOccasionUpcoming reminds the subscribers about the occasion, digest readers included,
+// since the reminder is useless once the date has passed
+func (s *Subscriber) onWishlistOccasionUpcoming() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var occasionPayload wishlistEvents.OccasionPayload
+		err := json.Unmarshal(payload, &occasionPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		wishlist, err := s.wishlistService.Get(ctx, occasionPayload.WishlistID)
+		if err != nil {
+			return err
+		}
+		unbooked, err := s.countUnbookedItems(ctx, wishlist.ID)
+		if err != nil {
+			return err
+		}
+		subscribes, err := s.subscribeService.GetByWishlist(ctx, wishlist.ID)
+		if err != nil {
+			return err
+		}
+
+		link := miniapp.MakeLinkToWishlist(s.miniAppUrl, wishlist.ID)
+		date := occasionPayload.OccasionDate.UTC().Format(occasionDateLayout)
+		for _, subscribe := range subscribes {
+			if !subscribe.IsNotifyEnabled || subscribe.UserID == wishlist.UserID {
+				continue
+			}
+			canNotify, err := s.wishlistService.CanNotify(ctx, wishlist, subscribe.UserID)
+			if err != nil {
+				fmt.Printf("check access of user %s to wishlist %s failed: %v\n", subscribe.UserID, wishlist.ID, err)
+				continue
+			}
+			if !canNotify {
+				continue
+			}
+			s.notifyRecipient(ctx, subscribe.UserID, notify.EventKindOccasions, "notify_wishlist_occasion_upcoming",
+				notify.EscapeMarkdownLinkText(wishlist.Title), link, date, occasionPayload.DaysLeft, unbooked)
+		}
+		return nil
+	}
+}
+
+func (s *Subscriber) countUnbookedItems(ctx context.Context, wishlistID wishlistPkg.ID) (int, error) {
+	items, _, err := s.wishlistService.GetWishlistItems(ctx, wishlistID, wishlistPkg.OrderByPosition, occasionItemsLimit, 0)
+	if err != nil {
+		return 0, err
+	}
+	bookings, err := s.wishlistService.GetBookingsByWishlist(ctx, wishlistID)
+	if err != nil {
+		return 0, err
+	}
+	bookingsByItem := make(map[wishlistPkg.ItemID]wishlistPkg.Bookings, len(items))
+	for _, booking := range bookings {
+		bookingsByItem[booking.ItemID] = append(bookingsByItem[booking.ItemID], booking)
+	}
+	contributions, err := s.wishlistService.GetContributionsByWishlist(ctx, wishlistID)
+	if err != nil {
+		return 0, err
+	}
+	contributionsByItem := make(map[wishlistPkg.ItemID]wishlistPkg.Contributions, len(items))
+	for _, contribution := range contributions {
+		contributionsByItem[contribution.ItemID] = append(contributionsByItem[contribution.ItemID], contribution)
+	}
+	pricesByProduct, err := s.getPrices(ctx, items, contributionsByItem)
+	if err != nil {
+		return 0, err
+	}
+
+	unbooked := 0
+	for _, item := range items {
+		if !item.IsBookingAvailable || item.Remaining(bookingsByItem[item.ID]) == 0 {
+			continue
+		}
+		// an item paid for by the contributors is as good as booked
+		if price, ok := pricesByProduct[item.ID.ProductID]; ok {
+			isCovered, err := contributionsByItem[item.ID].IsFullyCovered(*price)
+			if err == nil && isCovered {
+				continue
+			}
+		}
+		unbooked++
+	}
+	return unbooked, nil
+}
+
+// getPrices returns the prices of the items having contributions
+func (s *Subscriber) getPrices(
+	ctx context.Context,
+	items []*wishlistPkg.Item,
+	contributionsByItem map[wishlistPkg.ItemID]wishlistPkg.Contributions,
+) (map[productPkg.ID]*currency.Amount, error) {
+	productIDs := make([]productPkg.ID, 0, len(contributionsByItem))
+	for _, item := range items {
+		if len(contributionsByItem[item.ID]) > 0 {
+			productIDs = append(productIDs, item.ID.ProductID)
+		}
+	}
+	prices := make(map[productPkg.ID]*currency.Amount, len(productIDs))
+	if len(productIDs) == 0 {
+		return prices, nil
+	}
+	products, err := s.productService.GetMany(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, product := range products {
+		if product.Price != nil {
+			prices[product.ID] = product.Price
+		}
+	}
+	return prices, nil
+}
@@ -4,26 +4,131 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/grulex/go-wishlist/miniapp"
 	"github.com/grulex/go-wishlist/pkg/eventmanager"
 	"github.com/grulex/go-wishlist/pkg/events/wish"
+	wishlistEvents "github.com/grulex/go-wishlist/pkg/events/wishlist"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	newitemPkg "github.com/grulex/go-wishlist/pkg/newitem"
+	"github.com/grulex/go-wishlist/pkg/notify"
+	productPkg "github.com/grulex/go-wishlist/pkg/product"
+	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
+	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
 )
 
+// subscriptionName identifies the handlers of this subscriber in the retry state and dead letters, don't change it
+const subscriptionName = "notify"
+
+// ownerSubscriptionName is the name of the handlers notifying the wishlist owner of the events notifying someone else too
+const ownerSubscriptionName = "notify-owner"
+
 type eventManager interface {
-	Subscribe(eventName eventmanager.EventName, handler eventmanager.EventHandler)
+	Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler)
+}
+
+type notifyService interface {
+	Notify(ctx context.Context, userID userPkg.ID, kind notify.EventKind, key string, params ...any) error
+	NotifyWithImage(ctx context.Context, userID userPkg.ID, kind notify.EventKind, imageUrl string, key string, params ...any) error
+}
+
+type productService interface {
+	Get(ctx context.Context, id productPkg.ID) (*productPkg.Product, error)
+	GetMany(ctx context.Context, ids []productPkg.ID) ([]*productPkg.Product, error)
+}
+
+type wishlistService interface {
+	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
+	CanNotify(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID userPkg.ID) (bool, error)
+	GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Contributions, error)
+	GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Contributions, error)
+	GetWishlistItems(
+		ctx context.Context,
+		wishlistID wishlistPkg.ID,
+		order wishlistPkg.ItemsOrder,
+		limit, offset uint,
+	) ([]*wishlistPkg.Item, bool, error)
+	GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Bookings, error)
+}
+
+type subscribeService interface {
+	GetByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*subscribePkg.Subscribe, error)
+}
+
+type newItemService interface {
+	Add(ctx context.Context, item *newitemPkg.Pending) error
+}
+
+type userService interface {
+	Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
+}
+
+type imageService interface {
+	Get(ctx context.Context, id imagePkg.ID) (*imagePkg.Image, error)
 }
 
 type Subscriber struct {
+	notifyService    notifyService
+	productService   productService
+	wishlistService  wishlistService
+	subscribeService subscribeService
+	imageService     imageService
+	userService      userService
+	newItemService   newItemService
+	miniAppUrl       string
+	apiUrl           string
 }
 
-func NewSubscriberForNotify() *Subscriber {
-	return &Subscriber{}
+// notifyRecipient sends a message of a fan-out to one recipient. A failure is logged and skipped, since returning it
+// would retry the whole event and repeat the message to the recipients notified already.
+func (s *Subscriber) notifyRecipient(ctx context.Context, userID userPkg.ID, kind notify.EventKind, key string, params ...any) {
+	err := s.notifyService.Notify(ctx, userID, kind, key, params...)
+	if err != nil {
+		fmt.Printf("notify user %s with %s failed: %v\n", userID, key, err)
+	}
+}
+
+func NewSubscriberForNotify(
+	notifyService notifyService,
+	productService productService,
+	wishlistService wishlistService,
+	subscribeService subscribeService,
+	imageService imageService,
+	userService userService,
+	newItemService newItemService,
+	miniAppUrl string,
+	apiUrl string,
+) *Subscriber {
+	return &Subscriber{
+		notifyService:    notifyService,
+		productService:   productService,
+		wishlistService:  wishlistService,
+		subscribeService: subscribeService,
+		imageService:     imageService,
+		userService:      userService,
+		newItemService:   newItemService,
+		miniAppUrl:       miniAppUrl,
+		apiUrl:           apiUrl,
+	}
 }
 
 func (s *Subscriber) Subscribe(manager eventManager) {
-	manager.Subscribe(wish.EventWishBookingUpdate, s.onWishBookingUpdate())
+	// the owner is notified by a separate subscription, so a retry of one of them doesn't repeat
+	// the messages already delivered by the other
+	manager.Subscribe(wish.EventWishBookingUpdate, subscriptionName, s.onWishBookingUpdateForBooker())
+	manager.Subscribe(wish.EventWishBookingUpdate, ownerSubscriptionName, s.onWishBookingUpdateForOwner())
+	manager.Subscribe(wish.EventWishItemAdded, subscriptionName, s.onWishItemAdded())
+	manager.Subscribe(wish.EventWishContributionUpdate, subscriptionName, s.onWishContributionUpdate())
+	manager.Subscribe(wish.EventWishContributionUpdate, ownerSubscriptionName, s.onWishContributionUpdateForOwner())
+	manager.Subscribe(wish.EventWishBookingExpiring, subscriptionName, s.onWishBookingExpiring())
+	manager.Subscribe(wish.EventWishItemFulfilled, subscriptionName, s.onWishItemFulfilled())
+	manager.Subscribe(wish.EventWishItemFulfilled, ownerSubscriptionName, s.onWishItemFulfilledForOwner())
+	manager.Subscribe(wish.EventWishItemThanked, subscriptionName, s.onWishItemThanked())
+	manager.Subscribe(wishlistEvents.EventWishlistOccasionUpcoming, subscriptionName, s.onWishlistOccasionUpcoming())
 }
 
-func (s *Subscriber) onWishBookingUpdate() eventmanager.EventHandler {
+// onWishBookingUpdateForBooker notifies the user whose booking was made, changed or cancelled
+func (s *Subscriber) onWishBookingUpdateForBooker() eventmanager.EventHandler {
 	return func(ctx context.Context, payload json.RawMessage) error {
 		var bookingPayload wish.BookingPayload
 		err := json.Unmarshal(payload, &bookingPayload)
@@ -31,27 +136,79 @@ func (s *Subscriber) onWishBookingUpdate() eventmanager.EventHandler {
 			return eventmanager.ErrInvalidPayload
 		}
 
-		if bookingPayload.NewBookedBy != nil {
-			// handle booking
-			if *bookingPayload.NewBookedBy != bookingPayload.WishOwner {
-				// notify initiator and owner
-			}
-		} else if bookingPayload.OldBookedBy != nil {
-			// handle unbooking
-			if bookingPayload.EventBy == bookingPayload.WishOwner && bookingPayload.EventBy == *bookingPayload.OldBookedBy {
+		var recipient userPkg.ID
+		var key string
+		switch {
+		case bookingPayload.NewBookedBy != nil:
+			// handle booking and changes of the booked quantity
+			if bookingPayload.OldBookedBy != nil && bookingPayload.NewQuantity < bookingPayload.OldQuantity {
 				return nil
 			}
-			if *bookingPayload.OldBookedBy != bookingPayload.WishOwner {
-				// notify old booker about unbooking by owner
-			}
-			if bookingPayload.EventBy == *bookingPayload.OldBookedBy {
+			if *bookingPayload.NewBookedBy == bookingPayload.WishOwner {
 				return nil
 			}
+			recipient, key = *bookingPayload.NewBookedBy, "notify_wish_booked_by_you"
+			if bookingPayload.NewQuantity > 1 {
+				key = "notify_wish_units_booked_by_you"
+			}
+		case bookingPayload.OldBookedBy == nil:
+			return nil
+		case bookingPayload.IsExpired:
+			recipient, key = *bookingPayload.OldBookedBy, "notify_wish_booking_expired"
+		case *bookingPayload.OldBookedBy != bookingPayload.WishOwner && bookingPayload.EventBy == bookingPayload.WishOwner:
+			recipient, key = *bookingPayload.OldBookedBy, "notify_wish_unbooked_by_owner"
+		default:
+			return nil
+		}
+
+		product, err := s.productService.Get(ctx, bookingPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, bookingPayload.ItemID.WishlistID, product.ID)
+		if key == "notify_wish_units_booked_by_you" {
+			return s.notifyService.Notify(ctx, recipient, notify.EventKindBooking, key,
+				bookingPayload.NewQuantity, notify.EscapeMarkdownLinkText(product.Title), link)
+		}
+		return s.notifyService.Notify(ctx, recipient, notify.EventKindBooking, key, notify.EscapeMarkdownLinkText(product.Title), link)
+	}
+}
 
+// onWishBookingUpdateForOwner tells the owner that a wish was booked or became available again,
+// unless the surprise mode hides the bookings from the owner
+func (s *Subscriber) onWishBookingUpdateForOwner() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var bookingPayload wish.BookingPayload
+		err := json.Unmarshal(payload, &bookingPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
 		}
 
-		fmt.Printf("Call onWishBookingUpdate %+v \n", bookingPayload)
+		var key string
+		switch {
+		case bookingPayload.NewBookedBy != nil:
+			// only the first booking of the wish is announced
+			if bookingPayload.OldBookedBy != nil || *bookingPayload.NewBookedBy == bookingPayload.WishOwner {
+				return nil
+			}
+			key = "notify_wish_booked_for_owner"
+		case bookingPayload.OldBookedBy == nil || *bookingPayload.OldBookedBy == bookingPayload.WishOwner:
+			return nil
+		case bookingPayload.IsExpired || bookingPayload.EventBy == *bookingPayload.OldBookedBy:
+			key = "notify_wish_unbooked_for_owner"
+		default:
+			return nil
+		}
 
-		return nil
+		wishlist, err := s.wishlistService.Get(ctx, bookingPayload.ItemID.WishlistID)
+		if err != nil || wishlist.IsSurpriseMode {
+			return err
+		}
+		product, err := s.productService.Get(ctx, bookingPayload.ItemID.ProductID)
+		if err != nil {
+			return err
+		}
+		link := miniapp.MakeLinkToItem(s.miniAppUrl, bookingPayload.ItemID.WishlistID, product.ID)
+		return s.notifyService.Notify(ctx, bookingPayload.WishOwner, notify.EventKindBooking, key, notify.EscapeMarkdownLinkText(product.Title), link)
 	}
 }
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"github.com/bojanz/currency"
 	"github.com/google/uuid"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	productEvents "github.com/grulex/go-wishlist/pkg/events/product"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	"time"
 )
@@ -13,13 +16,19 @@ type storage interface {
 	GetMany(ctx context.Context, ids []productPkg.ID) ([]*productPkg.Product, error)
 }
 
+type eventManager interface {
+	Publish(ctx context.Context, event eventmanager.Event) error
+}
+
 type Service struct {
-	storage storage
+	storage      storage
+	eventManager eventManager
 }
 
-func NewProductService(storage storage) *Service {
+func NewProductService(storage storage, manager eventManager) *Service {
 	return &Service{
-		storage: storage,
+		storage:      storage,
+		eventManager: manager,
 	}
 }
 
@@ -39,6 +48,37 @@ func (s *Service) GetMany(ctx context.Context, ids []productPkg.ID) ([]*productP
 }
 
 func (s *Service) Update(ctx context.Context, product *productPkg.Product) error {
+	old, err := s.storage.Get(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+	// in-memory storage returns the same pointer, so the price must be copied before the update
+	var oldPrice *currency.Amount
+	if old.Price != nil {
+		price := *old.Price
+		oldPrice = &price
+	}
+
 	product.UpdatedAt = time.Now().UTC()
-	return s.storage.Upsert(ctx, product)
+	err = s.storage.Upsert(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	if isSamePrice(oldPrice, product.Price) {
+		return nil
+	}
+	return s.eventManager.Publish(ctx, productEvents.NewPriceChangedEvent(productEvents.PricePayload{
+		ProductID: product.ID,
+		OldPrice:  oldPrice,
+		NewPrice:  product.Price,
+		EventAt:   product.UpdatedAt,
+	}))
+}
+
+func isSamePrice(a, b *currency.Amount) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
 }
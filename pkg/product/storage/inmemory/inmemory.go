@@ -20,30 +20,33 @@ func NewProductInMemory() *Storage {
 
 func (s *Storage) Upsert(_ context.Context, p *product.Product) error {
 	s.Lock.Lock()
-	s.products[p.ID] = p
+	stored := *p
+	s.products[p.ID] = &stored
 	s.Lock.Unlock()
 	return nil
 }
 
 func (s *Storage) Get(_ context.Context, id product.ID) (*product.Product, error) {
 	s.Lock.RLock()
+	defer s.Lock.RUnlock()
 	p, ok := s.products[id]
 	if !ok {
 		return nil, product.ErrNotFound
 	}
-	s.Lock.RUnlock()
-	return p, nil
+	productCopy := *p
+	return &productCopy, nil
 }
 
 func (s *Storage) GetMany(_ context.Context, ids []product.ID) (products []*product.Product, err error) {
 	s.Lock.RLock()
+	defer s.Lock.RUnlock()
 	for _, id := range ids {
 		p, ok := s.products[id]
 		if !ok {
 			return nil, product.ErrNotFound
 		}
-		products = append(products, p)
+		productCopy := *p
+		products = append(products, &productCopy)
 	}
-	s.Lock.RUnlock()
 	return products, nil
 }
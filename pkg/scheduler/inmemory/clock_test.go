@@ -0,0 +1,109 @@
+package inmemory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grulex/go-wishlist/pkg/scheduler"
+)
+
+var start = time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+
+func isFired(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case at := <-ch:
+		return at, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// waitForWaiters blocks until the given number of timers is set on the clock
+func waitForWaiters(t *testing.T, c *Clock, count int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		waiting := len(c.waiters)
+		c.mu.Unlock()
+		if waiting >= count {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d timers on the clock", count)
+}
+
+func TestClockAfter(t *testing.T) {
+	c := NewClock(start)
+
+	immediate := c.After(0)
+	if at, ok := isFired(immediate); !ok || !at.Equal(start) {
+		t.Fatalf("expected a timer without delay to fire at once at %v, got %v (fired: %v)", start, at, ok)
+	}
+
+	minute := c.After(time.Minute)
+	hour := c.After(time.Hour)
+	if _, ok := isFired(minute); ok {
+		t.Fatal("timer fired before the clock moved")
+	}
+
+	c.Advance(59 * time.Second)
+	if _, ok := isFired(minute); ok {
+		t.Fatal("timer fired before its time")
+	}
+
+	c.Advance(time.Second)
+	if at, ok := isFired(minute); !ok || !at.Equal(start.Add(time.Minute)) {
+		t.Fatalf("expected the minute timer to fire at %v, got %v (fired: %v)", start.Add(time.Minute), at, ok)
+	}
+	if _, ok := isFired(hour); ok {
+		t.Fatal("hour timer fired too early")
+	}
+
+	c.Advance(2 * time.Hour)
+	if at, ok := isFired(hour); !ok || !at.Equal(start.Add(2*time.Hour+time.Minute)) {
+		t.Fatalf("expected the overdue hour timer to fire with the current time, got %v (fired: %v)", at, ok)
+	}
+	if !c.Now().Equal(start.Add(2*time.Hour + time.Minute)) {
+		t.Fatalf("unexpected time %v", c.Now())
+	}
+}
+
+func TestSchedulerRunsJobsAtIntervalBoundaries(t *testing.T) {
+	c := NewClock(start)
+	s := scheduler.NewScheduler(c)
+	runs := make(chan time.Time)
+	s.Every("test", time.Hour, func(_ context.Context, runAt time.Time) error {
+		runs <- runAt
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		_ = s.Start(context.Background())
+		close(done)
+	}()
+
+	for hour := 11; hour <= 12; hour++ {
+		waitForWaiters(t, c, 1)
+		c.Advance(time.Hour)
+		select {
+		case runAt := <-runs:
+			expected := time.Date(2024, 3, 1, hour, 0, 0, 0, time.UTC)
+			if !runAt.Equal(expected) {
+				t.Fatalf("expected the run at %v, got %v", expected, runAt)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("job didn't run at %d:00", hour)
+		}
+	}
+
+	_ = s.Stop(context.Background())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler didn't stop")
+	}
+}
@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type RealClock struct{}
+
+func (RealClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Job receives the planned run time, which may be a little earlier than the actual clock time.
+type Job func(ctx context.Context, runAt time.Time) error
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+type Scheduler struct {
+	clock    Clock
+	jobs     []scheduledJob
+	stop     chan struct{}
+	stopOnce *sync.Once
+	wg       *sync.WaitGroup
+}
+
+func NewScheduler(clock Clock) *Scheduler {
+	return &Scheduler{
+		clock:    clock,
+		stop:     make(chan struct{}),
+		stopOnce: &sync.Once{},
+		wg:       &sync.WaitGroup{},
+	}
+}
+
+// Every runs the job at every interval boundary, e.g. at the start of every hour for time.Hour.
+// Must be called before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, job Job) {
+	s.jobs = append(s.jobs, scheduledJob{
+		name:     name,
+		interval: interval,
+		job:      job,
+	})
+}
+
+func (s *Scheduler) Start(ctx context.Context) error {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j scheduledJob) {
+			defer s.wg.Done()
+			s.run(ctx, j)
+		}(j)
+	}
+	s.wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context, j scheduledJob) {
+	for {
+		now := s.clock.Now()
+		runAt := now.Truncate(j.interval).Add(j.interval)
+		select {
+		case <-s.clock.After(runAt.Sub(now)):
+		case <-s.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		if err := j.job(ctx, runAt); err != nil {
+			fmt.Printf("scheduled job %s failed: %v\n", j.name, err)
+		}
+	}
+}
+
+func (s *Scheduler) Stop(_ context.Context) error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
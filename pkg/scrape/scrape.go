@@ -0,0 +1,120 @@
+package scrape
+
+import (
+	"errors"
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/pkg/image"
+	urlPkg "net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("scrape result not found")
+	ErrInvalidUrl = errors.New("url must be a http or https link")
+	// ErrScrapeFailed is returned for the cached failure until it expires
+	ErrScrapeFailed = errors.New("page scrape failed")
+)
+
+// Result is the preview of a shop page, it prefills the wish created by the link
+type Result struct {
+	// Url is the normalized one, it's the key of the cache
+	Url          string
+	Title        string
+	Description  string
+	Images       []string
+	Price        *currency.Amount
+	Brand        string
+	Availability string
+	SKU          string
+	// ImageID is the uploaded first image, it's shared by the wishes added by the same link
+	ImageID *image.ID
+	// Error is set for the failed scrapes, they are cached for a shorter time
+	Error     string
+	ScrapedAt time.Time
+}
+
+func (r Result) IsFailed() bool {
+	return r.Error != ""
+}
+
+// trackingParams are dropped from the urls, they differ for the same product shared by different people
+var trackingParams = map[string]bool{
+	"gclid":     true,
+	"fbclid":    true,
+	"yclid":     true,
+	"msclkid":   true,
+	"igshid":    true,
+	"mc_cid":    true,
+	"mc_eid":    true,
+	"_openstat": true,
+	"spm":       true,
+}
+
+// siteTrackingParams are dropped only on the sites using them for tracking, elsewhere the same names may
+// address the page content. The site is matched by any label of the host but the last one,
+// e.g. amazon matches www.amazon.co.uk.
+var siteTrackingParams = map[string]map[string]bool{
+	"amazon":  {"ref": true, "ref_": true},
+	"youtube": {"si": true},
+	"youtu":   {"si": true},
+	"spotify": {"si": true},
+}
+
+func isSiteTrackingParam(host, key string) bool {
+	labels := strings.Split(host, ".")
+	for _, label := range labels[:len(labels)-1] {
+		if siteTrackingParams[label][key] {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeUrl lowercases the scheme and the host, drops the default port, the fragment and the tracking
+// parameters and sorts the rest of the query. The "#!" fragments are kept since they address the page content.
+func NormalizeUrl(raw string) (string, error) {
+	url, err := urlPkg.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", ErrInvalidUrl
+	}
+	url.Scheme = strings.ToLower(url.Scheme)
+	if (url.Scheme != "http" && url.Scheme != "https") || url.Hostname() == "" {
+		return "", ErrInvalidUrl
+	}
+
+	host := strings.ToLower(url.Hostname())
+	port := url.Port()
+	if (url.Scheme == "http" && port == "80") || (url.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	url.Host = host
+	url.User = nil
+	if url.Path == "/" {
+		url.Path = ""
+		url.RawPath = ""
+	}
+	if !strings.HasPrefix(url.Fragment, "!") {
+		url.Fragment = ""
+		url.RawFragment = ""
+	}
+
+	query := url.Query()
+	for key := range query {
+		lowerKey := strings.ToLower(key)
+		if trackingParams[lowerKey] || strings.HasPrefix(lowerKey, "utm_") || isSiteTrackingParam(host, lowerKey) {
+			query.Del(key)
+		}
+	}
+	// Encode sorts the parameters by key
+	url.RawQuery = query.Encode()
+	url.ForceQuery = false
+
+	return url.String(), nil
+}
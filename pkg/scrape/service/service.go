@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bojanz/currency"
+	"github.com/grulex/go-wishlist/fetcher"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
+	"github.com/grulex/go-wishlist/scrapper"
+	"time"
+)
+
+// maxScrapeRedirects limits the refetches by the canonical urls of the page
+const maxScrapeRedirects = 5
+
+type storage interface {
+	Get(ctx context.Context, url string) (*scrapePkg.Result, error)
+	Upsert(ctx context.Context, result *scrapePkg.Result) error
+	Delete(ctx context.Context, url string) error
+	DeleteScrapedBefore(ctx context.Context, before time.Time) error
+}
+
+// Service is the cache in front of the scrapper. The results are kept for ttl, the failures for failureTTL,
+// so a broken or blocking shop isn't requested on every paste either.
+type Service struct {
+	storage    storage
+	ttl        time.Duration
+	failureTTL time.Duration
+}
+
+func NewScrapeService(storage storage, ttl, failureTTL time.Duration) *Service {
+	return &Service{
+		storage:    storage,
+		ttl:        ttl,
+		failureTTL: failureTTL,
+	}
+}
+
+// Scrape returns the preview of the page by the normalized url, a cached failure is returned as ErrScrapeFailed
+func (s *Service) Scrape(ctx context.Context, rawUrl string) (*scrapePkg.Result, error) {
+	url, err := scrapePkg.NormalizeUrl(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := s.storage.Get(ctx, url)
+	if err != nil && !errors.Is(err, scrapePkg.ErrNotFound) {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if cached != nil && !s.isExpired(cached, now) {
+		if cached.IsFailed() {
+			return nil, fmt.Errorf("%w: %s", scrapePkg.ErrScrapeFailed, cached.Error)
+		}
+		return cached, nil
+	}
+
+	doc, err := scrapper.Scrape(ctx, url, maxScrapeRedirects)
+	if err != nil {
+		if isCacheableFailure(ctx, err) {
+			failure := &scrapePkg.Result{
+				Url:       url,
+				Error:     err.Error(),
+				ScrapedAt: now,
+			}
+			if upsertErr := s.storage.Upsert(ctx, failure); upsertErr != nil {
+				return nil, errors.Join(err, upsertErr)
+			}
+		}
+		return nil, err
+	}
+
+	result := &scrapePkg.Result{
+		Url:          url,
+		Title:        doc.Preview.Title,
+		Description:  doc.Preview.Description,
+		Images:       doc.Preview.Images,
+		Price:        makePrice(doc.Preview.Price, doc.Preview.Currency),
+		Brand:        doc.Preview.Brand,
+		Availability: doc.Preview.Availability,
+		SKU:          doc.Preview.SKU,
+		ScrapedAt:    now,
+	}
+	// the uploaded image is still valid while the page shows the same one
+	if cached != nil && cached.ImageID != nil && len(cached.Images) != 0 && len(result.Images) != 0 &&
+		cached.Images[0] == result.Images[0] {
+		result.ImageID = cached.ImageID
+	}
+	err = s.storage.Upsert(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetImage keeps the image uploaded from the first image of the result, so the next wishes by the same link
+// don't download it again
+func (s *Service) SetImage(ctx context.Context, url string, imageID imagePkg.ID) error {
+	result, err := s.storage.Get(ctx, url)
+	if err != nil {
+		return err
+	}
+	result.ImageID = &imageID
+	return s.storage.Upsert(ctx, result)
+}
+
+// Invalidate removes the cached result of the url, the next Scrape fetches the page again
+func (s *Service) Invalidate(ctx context.Context, rawUrl string) error {
+	url, err := scrapePkg.NormalizeUrl(rawUrl)
+	if err != nil {
+		return err
+	}
+	return s.storage.Delete(ctx, url)
+}
+
+// DeleteExpired removes the results which are not used anymore
+func (s *Service) DeleteExpired(ctx context.Context, at time.Time) error {
+	maxTTL := s.ttl
+	if s.failureTTL > maxTTL {
+		maxTTL = s.failureTTL
+	}
+	return s.storage.DeleteScrapedBefore(ctx, at.Add(-maxTTL))
+}
+
+func (s *Service) isExpired(result *scrapePkg.Result, at time.Time) bool {
+	ttl := s.ttl
+	if result.IsFailed() {
+		ttl = s.failureTTL
+	}
+	return !result.ScrapedAt.Add(ttl).After(at)
+}
+
+// isCacheableFailure skips the failures caused by the request itself rather than by the page
+func isCacheableFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return !errors.Is(err, fetcher.ErrForbiddenAddress) && !errors.Is(err, fetcher.ErrForbiddenScheme)
+}
+
+// makePrice returns nil when the page has no price or the currency is unknown
+func makePrice(number, currencyCode string) *currency.Amount {
+	if number == "" || !currency.IsValid(currencyCode) {
+		return nil
+	}
+	amount, err := currency.NewAmount(number, currencyCode)
+	if err != nil {
+		return nil
+	}
+	return &amount
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grulex/go-wishlist/fetcher"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
+	"github.com/grulex/go-wishlist/pkg/scrape/storage/inmemory"
+)
+
+const (
+	ttl        = time.Hour
+	failureTTL = time.Minute * 10
+)
+
+func TestScrapeReturnsCachedResultByNormalizedUrl(t *testing.T) {
+	ctx := context.Background()
+	storage := inmemory.NewScrapeInMemory()
+	s := NewScrapeService(storage, ttl, failureTTL)
+	cached := &scrapePkg.Result{Url: "https://shop.example.com/item?color=red", Title: "Bike", ScrapedAt: time.Now().UTC()}
+	_ = storage.Upsert(ctx, cached)
+
+	result, err := s.Scrape(ctx, "HTTPS://shop.example.com/item?utm_source=tg&color=red#reviews")
+	if err != nil {
+		t.Fatalf("failed to scrape: %v", err)
+	}
+	if result.Title != "Bike" {
+		t.Fatalf("expected the cached result, got %+v", result)
+	}
+
+	imageID := imagePkg.ID("image")
+	if err = s.SetImage(ctx, result.Url, imageID); err != nil {
+		t.Fatalf("failed to set the image: %v", err)
+	}
+	result, _ = s.Scrape(ctx, cached.Url)
+	if result.ImageID == nil || *result.ImageID != imageID {
+		t.Fatalf("expected the image to be kept, got %v", result.ImageID)
+	}
+}
+
+func TestScrapeReturnsCachedFailure(t *testing.T) {
+	ctx := context.Background()
+	storage := inmemory.NewScrapeInMemory()
+	s := NewScrapeService(storage, ttl, failureTTL)
+	_ = storage.Upsert(ctx, &scrapePkg.Result{Url: "https://shop.example.com/item", Error: "status code 403", ScrapedAt: time.Now().UTC()})
+
+	_, err := s.Scrape(ctx, "https://shop.example.com/item")
+	if !errors.Is(err, scrapePkg.ErrScrapeFailed) {
+		t.Fatalf("expected the cached failure, got %v", err)
+	}
+}
+
+func TestScrapeRejectsInvalidUrl(t *testing.T) {
+	s := NewScrapeService(inmemory.NewScrapeInMemory(), ttl, failureTTL)
+	if _, err := s.Scrape(context.Background(), "ftp://shop.example.com/item"); !errors.Is(err, scrapePkg.ErrInvalidUrl) {
+		t.Fatalf("expected the invalid url error, got %v", err)
+	}
+}
+
+func TestScrapeDoesNotCacheForbiddenAddress(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<html><head><title>Internal</title></head></html>"))
+	}))
+	defer server.Close()
+	storage := inmemory.NewScrapeInMemory()
+	s := NewScrapeService(storage, ttl, failureTTL)
+
+	_, err := s.Scrape(ctx, server.URL)
+	if !errors.Is(err, fetcher.ErrForbiddenAddress) {
+		t.Fatalf("expected the loopback address to be refused, got %v", err)
+	}
+	url, _ := scrapePkg.NormalizeUrl(server.URL)
+	if _, err = storage.Get(ctx, url); !errors.Is(err, scrapePkg.ErrNotFound) {
+		t.Fatalf("expected the refused request not to be cached, got %v", err)
+	}
+}
+
+func TestInvalidateAndDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	storage := inmemory.NewScrapeInMemory()
+	s := NewScrapeService(storage, ttl, failureTTL)
+	now := time.Now().UTC()
+	_ = storage.Upsert(ctx, &scrapePkg.Result{Url: "https://shop.example.com/old", ScrapedAt: now.Add(-2 * ttl)})
+	_ = storage.Upsert(ctx, &scrapePkg.Result{Url: "https://shop.example.com/fresh", ScrapedAt: now.Add(-failureTTL * 2)})
+	_ = storage.Upsert(ctx, &scrapePkg.Result{Url: "https://shop.example.com/item", ScrapedAt: now})
+
+	if err := s.Invalidate(ctx, "https://Shop.Example.com/item#top"); err != nil {
+		t.Fatalf("failed to invalidate: %v", err)
+	}
+	if _, err := storage.Get(ctx, "https://shop.example.com/item"); !errors.Is(err, scrapePkg.ErrNotFound) {
+		t.Fatalf("expected the result to be invalidated, got %v", err)
+	}
+
+	// the results are kept for the longest of the ttls
+	if err := s.DeleteExpired(ctx, now); err != nil {
+		t.Fatalf("failed to delete the expired results: %v", err)
+	}
+	if _, err := storage.Get(ctx, "https://shop.example.com/old"); !errors.Is(err, scrapePkg.ErrNotFound) {
+		t.Fatalf("expected the old result to be deleted, got %v", err)
+	}
+	if _, err := storage.Get(ctx, "https://shop.example.com/fresh"); err != nil {
+		t.Fatalf("expected the result within the ttl to stay, got %v", err)
+	}
+}
+
+func TestMakePrice(t *testing.T) {
+	if makePrice("", "USD") != nil || makePrice("10", "XXXX") != nil || makePrice("ten", "USD") != nil {
+		t.Fatal("expected no price for an empty number, an unknown currency or an invalid number")
+	}
+	price := makePrice("10.50", "EUR")
+	if price == nil || price.String() != "10.50 EUR" {
+		t.Fatalf("unexpected price %v", price)
+	}
+}
@@ -0,0 +1,58 @@
+package inmemory
+
+import (
+	"context"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
+	"sync"
+	"time"
+)
+
+type Storage struct {
+	results map[string]scrapePkg.Result
+	lock    *sync.RWMutex
+}
+
+func NewScrapeInMemory() *Storage {
+	return &Storage{
+		results: map[string]scrapePkg.Result{},
+		lock:    &sync.RWMutex{},
+	}
+}
+
+func (s *Storage) Get(_ context.Context, url string) (*scrapePkg.Result, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	result, ok := s.results[url]
+	if !ok {
+		return nil, scrapePkg.ErrNotFound
+	}
+	result.Images = append([]string(nil), result.Images...)
+	return &result, nil
+}
+
+func (s *Storage) Upsert(_ context.Context, result *scrapePkg.Result) error {
+	stored := *result
+	stored.Images = append([]string(nil), result.Images...)
+	s.lock.Lock()
+	s.results[result.Url] = stored
+	s.lock.Unlock()
+	return nil
+}
+
+func (s *Storage) Delete(_ context.Context, url string) error {
+	s.lock.Lock()
+	delete(s.results, url)
+	s.lock.Unlock()
+	return nil
+}
+
+func (s *Storage) DeleteScrapedBefore(_ context.Context, before time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for url, result := range s.results {
+		if result.ScrapedAt.Before(before) {
+			delete(s.results, url)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"github.com/bojanz/currency"
+	imagePkg "github.com/grulex/go-wishlist/pkg/image"
+	scrapePkg "github.com/grulex/go-wishlist/pkg/scrape"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+type scrapePersistent struct {
+	Url          string           `db:"url"`
+	Title        string           `db:"title"`
+	Description  string           `db:"description"`
+	Images       images           `db:"images"`
+	Price        *currency.Amount `db:"price"`
+	Brand        string           `db:"brand"`
+	Availability string           `db:"availability"`
+	SKU          string           `db:"sku"`
+	ImageID      *string          `db:"image_id"`
+	ErrorMessage string           `db:"error_message"`
+	ScrapedAt    time.Time        `db:"scraped_at"`
+}
+
+func (p scrapePersistent) ToResult() *scrapePkg.Result {
+	var imageID *imagePkg.ID
+	if p.ImageID != nil {
+		id := imagePkg.ID(*p.ImageID)
+		imageID = &id
+	}
+	return &scrapePkg.Result{
+		Url:          p.Url,
+		Title:        p.Title,
+		Description:  p.Description,
+		Images:       p.Images,
+		Price:        p.Price,
+		Brand:        p.Brand,
+		Availability: p.Availability,
+		SKU:          p.SKU,
+		ImageID:      imageID,
+		Error:        p.ErrorMessage,
+		ScrapedAt:    p.ScrapedAt,
+	}
+}
+
+type images []string
+
+func (i images) Value() (driver.Value, error) {
+	if i == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(i)
+}
+
+func (i *images) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	switch src := src.(type) {
+	case []byte:
+		return json.Unmarshal(src, i)
+	case string:
+		return json.Unmarshal([]byte(src), i)
+	default:
+		return errors.New("incompatible type for images")
+	}
+}
+
+type Storage struct {
+	db *sqlx.DB
+}
+
+func NewScrapeStorage(db *sqlx.DB) *Storage {
+	return &Storage{db: db}
+}
+
+func (s *Storage) Get(ctx context.Context, url string) (*scrapePkg.Result, error) {
+	query := `SELECT * FROM scrape_cache WHERE url = $1`
+	p := &scrapePersistent{}
+	err := s.db.GetContext(ctx, p, query, url)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, scrapePkg.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.ToResult(), nil
+}
+
+func (s *Storage) Upsert(ctx context.Context, result *scrapePkg.Result) error {
+	query := `INSERT INTO scrape_cache (
+		url,
+		title,
+		description,
+		images,
+		price,
+		brand,
+		availability,
+		sku,
+		image_id,
+		error_message,
+		scraped_at
+	) VALUES (
+		:url,
+		:title,
+		:description,
+		:images,
+		:price,
+		:brand,
+		:availability,
+		:sku,
+		:image_id,
+		:error_message,
+		:scraped_at
+	) ON CONFLICT (url) DO UPDATE SET
+		title = :title,
+		description = :description,
+		images = :images,
+		price = :price,
+		brand = :brand,
+		availability = :availability,
+		sku = :sku,
+		image_id = :image_id,
+		error_message = :error_message,
+		scraped_at = :scraped_at`
+
+	var imageID *string
+	if result.ImageID != nil {
+		imageIDString := string(*result.ImageID)
+		imageID = &imageIDString
+	}
+	_, err := s.db.NamedExecContext(ctx, query, scrapePersistent{
+		Url:          result.Url,
+		Title:        result.Title,
+		Description:  result.Description,
+		Images:       result.Images,
+		Price:        result.Price,
+		Brand:        result.Brand,
+		Availability: result.Availability,
+		SKU:          result.SKU,
+		ImageID:      imageID,
+		ErrorMessage: result.Error,
+		ScrapedAt:    result.ScrapedAt,
+	})
+	return err
+}
+
+func (s *Storage) Delete(ctx context.Context, url string) error {
+	query := `DELETE FROM scrape_cache WHERE url = $1`
+	_, err := s.db.ExecContext(ctx, query, url)
+	return err
+}
+
+func (s *Storage) DeleteScrapedBefore(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM scrape_cache WHERE scraped_at < $1`
+	_, err := s.db.ExecContext(ctx, query, before)
+	return err
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	subscriptionEvents "github.com/grulex/go-wishlist/pkg/events/subscription"
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	"github.com/grulex/go-wishlist/pkg/wishlist"
@@ -10,29 +12,54 @@ import (
 )
 
 type storage interface {
+	InTransaction(ctx context.Context, change func(ctx context.Context) error) error
 	Upsert(ctx context.Context, subscribe *subscribePkg.Subscribe) error
 	Get(ctx context.Context, id userPkg.ID, wishlist wishlist.ID) (*subscribePkg.Subscribe, error)
 	GetByUser(ctx context.Context, id userPkg.ID) ([]*subscribePkg.Subscribe, error)
+	GetByWishlist(ctx context.Context, wishlist wishlist.ID) ([]*subscribePkg.Subscribe, error)
 	Delete(ctx context.Context, id userPkg.ID, wishlist wishlist.ID) error
 }
 
+type eventManager interface {
+	Publish(ctx context.Context, event eventmanager.Event) error
+}
+
 type Service struct {
-	storage storage
+	storage      storage
+	eventManager eventManager
 }
 
-func NewSubscribeService(storage storage) *Service {
+func NewSubscribeService(storage storage, manager eventManager) *Service {
 	return &Service{
-		storage: storage,
+		storage:      storage,
+		eventManager: manager,
 	}
 }
 
+// Subscribe saves the subscription in one transaction with its created event
 func (s *Service) Subscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.subscribe(ctx, userID, wishlistID)
+	})
+}
+
+func (s *Service) subscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID) error {
 	subscribe := &subscribePkg.Subscribe{
+		UserID:          userID,
+		WishlistID:      wishlistID,
+		IsNotifyEnabled: true,
+		CreatedAt:       time.Now().UTC(),
+	}
+	err := s.storage.Upsert(ctx, subscribe)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, subscriptionEvents.NewCreatedEvent(subscriptionEvents.Payload{
 		UserID:     userID,
 		WishlistID: wishlistID,
-		CreatedAt:  time.Now().UTC(),
-	}
-	return s.storage.Upsert(ctx, subscribe)
+		EventAt:    subscribe.CreatedAt,
+	}))
 }
 
 func (s *Service) Get(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID) (*subscribePkg.Subscribe, error) {
@@ -50,6 +77,52 @@ func (s *Service) GetByUser(ctx context.Context, userID userPkg.ID) ([]*subscrib
 	return s.storage.GetByUser(ctx, userID)
 }
 
+func (s *Service) GetByWishlist(ctx context.Context, wishlistID wishlist.ID) ([]*subscribePkg.Subscribe, error) {
+	return s.storage.GetByWishlist(ctx, wishlistID)
+}
+
+func (s *Service) SetNotifyEnabled(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID, isEnabled bool) error {
+	subscribe, err := s.storage.Get(ctx, userID, wishlistID)
+	if err != nil {
+		return err
+	}
+	subscribe.IsNotifyEnabled = isEnabled
+	return s.storage.Upsert(ctx, subscribe)
+}
+
+// Unsubscribe removes the subscription in one transaction with its removed event
 func (s *Service) Unsubscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID) error {
-	return s.storage.Delete(ctx, userID, wishlistID)
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.unsubscribe(ctx, userID, wishlistID)
+	})
+}
+
+// UnsubscribeAll removes all subscriptions to the wishlist at once, e.g. when the wishlist is deleted
+func (s *Service) UnsubscribeAll(ctx context.Context, wishlistID wishlist.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		subscribes, err := s.storage.GetByWishlist(ctx, wishlistID)
+		if err != nil {
+			return err
+		}
+		for _, subscribe := range subscribes {
+			err = s.unsubscribe(ctx, subscribe.UserID, subscribe.WishlistID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Service) unsubscribe(ctx context.Context, userID userPkg.ID, wishlistID wishlist.ID) error {
+	err := s.storage.Delete(ctx, userID, wishlistID)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, subscriptionEvents.NewRemovedEvent(subscriptionEvents.Payload{
+		UserID:     userID,
+		WishlistID: wishlistID,
+		EventAt:    time.Now().UTC(),
+	}))
 }
@@ -5,49 +5,75 @@ import (
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
 	"github.com/grulex/go-wishlist/pkg/user"
 	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"sync"
 )
 
 type Storage struct {
 	subscribes map[user.ID]map[wishlist.ID]*subscribePkg.Subscribe
+	lock       *sync.RWMutex
 }
 
 func NewSubscribeInMemory() *Storage {
 	return &Storage{
 		subscribes: map[user.ID]map[wishlist.ID]*subscribePkg.Subscribe{},
+		lock:       &sync.RWMutex{},
 	}
 }
 
+// InTransaction runs the change as is, the in-memory storage has no transactions
+func (s *Storage) InTransaction(ctx context.Context, change func(ctx context.Context) error) error {
+	return change(ctx)
+}
+
 func (s *Storage) Upsert(_ context.Context, subscribe *subscribePkg.Subscribe) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	if _, ok := s.subscribes[subscribe.UserID]; !ok {
 		s.subscribes[subscribe.UserID] = map[wishlist.ID]*subscribePkg.Subscribe{}
 	}
-	s.subscribes[subscribe.UserID][subscribe.WishlistID] = subscribe
+	subscribeCopy := *subscribe
+	s.subscribes[subscribe.UserID][subscribe.WishlistID] = &subscribeCopy
 	return nil
 }
 
 func (s *Storage) Get(_ context.Context, userID user.ID, wishlistID wishlist.ID) (*subscribePkg.Subscribe, error) {
-	if _, ok := s.subscribes[userID]; !ok {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	subscribe, ok := s.subscribes[userID][wishlistID]
+	if !ok {
 		return nil, subscribePkg.ErrNotFound
 	}
-	if _, ok := s.subscribes[userID][wishlistID]; !ok {
-		return nil, subscribePkg.ErrNotFound
-	}
-	subscribe := s.subscribes[userID][wishlistID]
-	return subscribe, nil
+	subscribeCopy := *subscribe
+	return &subscribeCopy, nil
 }
 
 func (s *Storage) GetByUser(_ context.Context, userID user.ID) ([]*subscribePkg.Subscribe, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
 	var subscribes []*subscribePkg.Subscribe
 	for _, subscribe := range s.subscribes[userID] {
-		subscribes = append(subscribes, subscribe)
+		subscribeCopy := *subscribe
+		subscribes = append(subscribes, &subscribeCopy)
 	}
 	return subscribes, nil
 }
 
-func (s *Storage) Delete(_ context.Context, userID user.ID, wishlistID wishlist.ID) error {
-	if _, ok := s.subscribes[userID]; !ok {
-		return nil
+func (s *Storage) GetByWishlist(_ context.Context, wishlistID wishlist.ID) ([]*subscribePkg.Subscribe, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var subscribes []*subscribePkg.Subscribe
+	for _, byWishlist := range s.subscribes {
+		if subscribe, ok := byWishlist[wishlistID]; ok {
+			subscribeCopy := *subscribe
+			subscribes = append(subscribes, &subscribeCopy)
+		}
 	}
+	return subscribes, nil
+}
+
+func (s *Storage) Delete(_ context.Context, userID user.ID, wishlistID wishlist.ID) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	delete(s.subscribes[userID], wishlistID)
 	return nil
 }
@@ -2,12 +2,32 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"github.com/grulex/go-wishlist/db"
 	subscribePkg "github.com/grulex/go-wishlist/pkg/subscribe"
 	"github.com/grulex/go-wishlist/pkg/user"
 	"github.com/grulex/go-wishlist/pkg/wishlist"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
+type subscribePersistent struct {
+	UserID          string    `db:"user_id"`
+	WishlistID      string    `db:"wishlist_id"`
+	IsNotifyEnabled bool      `db:"is_notify_enabled"`
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+func (p subscribePersistent) ToSubscribe() *subscribePkg.Subscribe {
+	return &subscribePkg.Subscribe{
+		UserID:          user.ID(p.UserID),
+		WishlistID:      wishlist.ID(p.WishlistID),
+		IsNotifyEnabled: p.IsNotifyEnabled,
+		CreatedAt:       p.CreatedAt,
+	}
+}
+
 type Storage struct {
 	db *sqlx.DB
 }
@@ -16,18 +36,77 @@ func NewSubscribeStorage(db *sqlx.DB) *Storage {
 	return &Storage{db: db}
 }
 
+// InTransaction runs the change in one transaction with the events published by it
+func (s *Storage) InTransaction(ctx context.Context, change func(ctx context.Context) error) error {
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, _ *sqlx.Tx) error {
+		return change(ctx)
+	})
+}
+
+func (s *Storage) executor(ctx context.Context) sqlx.ExtContext {
+	return db.Executor(ctx, s.db)
+}
+
 func (s *Storage) Upsert(ctx context.Context, subscribe *subscribePkg.Subscribe) error {
-	return nil
+	query := `INSERT INTO subscribe (
+		user_id,
+		wishlist_id,
+		is_notify_enabled,
+		created_at
+	) VALUES (
+		:user_id,
+		:wishlist_id,
+		:is_notify_enabled,
+		:created_at
+	) ON CONFLICT (user_id, wishlist_id) DO UPDATE SET
+		is_notify_enabled = :is_notify_enabled`
+	_, err := sqlx.NamedExecContext(ctx, s.executor(ctx), query, subscribePersistent{
+		UserID:          string(subscribe.UserID),
+		WishlistID:      string(subscribe.WishlistID),
+		IsNotifyEnabled: subscribe.IsNotifyEnabled,
+		CreatedAt:       subscribe.CreatedAt,
+	})
+	return err
 }
 
 func (s *Storage) Get(ctx context.Context, userID user.ID, wishlistID wishlist.ID) (*subscribePkg.Subscribe, error) {
-	return nil, nil
+	query := `SELECT * FROM subscribe WHERE user_id = $1 AND wishlist_id = $2`
+	p := &subscribePersistent{}
+	err := sqlx.GetContext(ctx, s.executor(ctx), p, query, userID, wishlistID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, subscribePkg.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.ToSubscribe(), nil
 }
 
 func (s *Storage) GetByUser(ctx context.Context, userID user.ID) ([]*subscribePkg.Subscribe, error) {
-	return nil, nil
+	query := `SELECT * FROM subscribe WHERE user_id = $1 ORDER BY created_at DESC`
+	return s.selectSubscribes(ctx, query, userID)
+}
+
+func (s *Storage) GetByWishlist(ctx context.Context, wishlistID wishlist.ID) ([]*subscribePkg.Subscribe, error) {
+	query := `SELECT * FROM subscribe WHERE wishlist_id = $1`
+	return s.selectSubscribes(ctx, query, wishlistID)
+}
+
+func (s *Storage) selectSubscribes(ctx context.Context, query string, args ...any) ([]*subscribePkg.Subscribe, error) {
+	subscribesPersistent := make([]*subscribePersistent, 0)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &subscribesPersistent, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	subscribes := make([]*subscribePkg.Subscribe, 0, len(subscribesPersistent))
+	for _, p := range subscribesPersistent {
+		subscribes = append(subscribes, p.ToSubscribe())
+	}
+	return subscribes, nil
 }
 
 func (s *Storage) Delete(ctx context.Context, userID user.ID, wishlistID wishlist.ID) error {
-	return nil
+	query := `DELETE FROM subscribe WHERE user_id = $1 AND wishlist_id = $2`
+	_, err := s.executor(ctx).ExecContext(ctx, query, userID, wishlistID)
+	return err
 }
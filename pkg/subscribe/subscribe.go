@@ -10,7 +10,8 @@ import (
 var ErrNotFound = errors.New("subscribe not found")
 
 type Subscribe struct {
-	UserID     user.ID
-	WishlistID wishlist.ID
-	CreatedAt  time.Time
+	UserID          user.ID
+	WishlistID      wishlist.ID
+	IsNotifyEnabled bool
+	CreatedAt       time.Time
 }
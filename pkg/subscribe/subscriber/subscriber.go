@@ -0,0 +1,47 @@
+package subscriber
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	wishlistEvents "github.com/grulex/go-wishlist/pkg/events/wishlist"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+)
+
+// subscriptionName identifies the handlers of this subscriber in the retry state and dead letters, don't change it
+const subscriptionName = "subscribe"
+
+type eventManager interface {
+	Subscribe(eventName eventmanager.EventName, name string, handler eventmanager.EventHandler)
+}
+
+type subscribeService interface {
+	UnsubscribeAll(ctx context.Context, wishlistID wishlistPkg.ID) error
+}
+
+// Subscriber removes subscriptions to deleted wishlists
+type Subscriber struct {
+	subscribeService subscribeService
+}
+
+func NewSubscriberForSubscribe(subscribeService subscribeService) *Subscriber {
+	return &Subscriber{
+		subscribeService: subscribeService,
+	}
+}
+
+func (s *Subscriber) Subscribe(manager eventManager) {
+	manager.Subscribe(wishlistEvents.EventWishlistDeleted, subscriptionName, s.onWishlistDeleted())
+}
+
+func (s *Subscriber) onWishlistDeleted() eventmanager.EventHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var wishlistPayload wishlistEvents.Payload
+		err := json.Unmarshal(payload, &wishlistPayload)
+		if err != nil {
+			return eventmanager.ErrInvalidPayload
+		}
+
+		return s.subscribeService.UnsubscribeAll(ctx, wishlistPayload.WishlistID)
+	}
+}
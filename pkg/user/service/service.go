@@ -2,8 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
 	"github.com/google/uuid"
+	"github.com/grulex/go-wishlist/pkg/notify"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
+	"math/big"
+	"net/mail"
 	"time"
 )
 
@@ -13,6 +19,13 @@ type storage interface {
 	GetDailyStats(ctx context.Context, duration time.Duration) ([]*userPkg.Stats, error)
 }
 
+const (
+	emailCodeTTL         = time.Minute * 15
+	maxEmailCodeAttempts = 5
+	// emailCodeResendAfter is how long the user waits before the next code is sent
+	emailCodeResendAfter = time.Minute
+)
+
 type Service struct {
 	storage storage
 }
@@ -25,6 +38,13 @@ func NewUserService(storage storage) *Service {
 
 func (s *Service) Create(ctx context.Context, user *userPkg.User) error {
 	user.ID = userPkg.ID(uuid.NewString())
+	if user.NotifyDelivery == "" {
+		user.NotifyDelivery = notify.DeliveryInstant
+		user.DigestHour = notify.DefaultDigestHour
+	}
+	if user.Timezone == "" {
+		user.Timezone = "UTC"
+	}
 	user.CreatedAt = time.Now().UTC()
 	return s.storage.Upsert(ctx, user)
 }
@@ -34,6 +54,84 @@ func (s *Service) Update(ctx context.Context, user *userPkg.User) error {
 	return s.storage.Upsert(ctx, user)
 }
 
+// SetEmail saves the unverified address and returns the verification code to be sent to it.
+// Email notifications are switched off until the address is verified.
+// A new code is issued not earlier than emailCodeResendAfter since the previous one.
+func (s *Service) SetEmail(ctx context.Context, id userPkg.ID, email string) (string, error) {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", userPkg.ErrInvalidEmail
+	}
+	user, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	if user.EmailCodeExpires != nil && now.Before(user.EmailCodeExpires.Add(emailCodeResendAfter-emailCodeTTL)) {
+		return "", userPkg.ErrEmailCodeTooSoon
+	}
+
+	code, err := generateEmailCode()
+	if err != nil {
+		return "", err
+	}
+	expiresAt := now.Add(emailCodeTTL)
+	user.Email = &address.Address
+	user.IsEmailVerified = false
+	user.EmailCode = &code
+	user.EmailCodeExpires = &expiresAt
+	user.EmailCodeAttempts = 0
+	if user.NotifyType != nil && *user.NotifyType == notify.TypeEmail {
+		user.NotifyType = nil
+		if user.NotifyChannelID != nil {
+			tgType := notify.TypeTelegram
+			user.NotifyType = &tgType
+		}
+	}
+	return code, s.Update(ctx, user)
+}
+
+// VerifyEmail checks the code and switches notifications to email
+func (s *Service) VerifyEmail(ctx context.Context, id userPkg.ID, code string) error {
+	user, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user.EmailCode == nil || user.EmailCodeExpires == nil {
+		return userPkg.ErrEmailCodeInvalid
+	}
+	if user.EmailCodeAttempts >= maxEmailCodeAttempts {
+		return userPkg.ErrEmailCodeAttempts
+	}
+	if time.Now().After(*user.EmailCodeExpires) {
+		return userPkg.ErrEmailCodeExpired
+	}
+	if subtle.ConstantTimeCompare([]byte(*user.EmailCode), []byte(code)) != 1 {
+		user.EmailCodeAttempts++
+		err = s.Update(ctx, user)
+		if err != nil {
+			return err
+		}
+		return userPkg.ErrEmailCodeInvalid
+	}
+
+	emailType := notify.TypeEmail
+	user.IsEmailVerified = true
+	user.EmailCode = nil
+	user.EmailCodeExpires = nil
+	user.EmailCodeAttempts = 0
+	user.NotifyType = &emailType
+	return s.Update(ctx, user)
+}
+
+func generateEmailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
 func (s *Service) Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error) {
 	return s.storage.Get(ctx, id)
 }
@@ -7,16 +7,28 @@ import (
 	"github.com/grulex/go-wishlist/pkg/notify"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	"github.com/jmoiron/sqlx"
+	"strings"
 	"time"
 )
 
 type userPersistent struct {
-	ID              string    `db:"id"`
-	FullName        string    `db:"fullname"`
-	Language        string    `db:"lang"`
-	NotifyType      *string   `db:"notify_type"`
-	NotifyChannelID *string   `db:"notify_channel_id"`
-	CreatedAt       time.Time `db:"created_at"`
+	ID                string     `db:"id"`
+	FullName          string     `db:"fullname"`
+	Language          string     `db:"lang"`
+	NotifyType        *string    `db:"notify_type"`
+	NotifyChannelID   *string    `db:"notify_channel_id"`
+	NotifyDelivery    string     `db:"notify_delivery"`
+	DigestHour        int        `db:"digest_hour"`
+	MutedEvents       string     `db:"muted_notify_events"`
+	QuietHoursFrom    *int       `db:"quiet_hours_from"`
+	QuietHoursTo      *int       `db:"quiet_hours_to"`
+	Timezone          string     `db:"timezone"`
+	Email             *string    `db:"email"`
+	IsEmailVerified   bool       `db:"is_email_verified"`
+	EmailCode         *string    `db:"email_code"`
+	EmailCodeExpires  *time.Time `db:"email_code_expires_at"`
+	EmailCodeAttempts int        `db:"email_code_attempts"`
+	CreatedAt         time.Time  `db:"created_at"`
 }
 
 type Storage struct {
@@ -34,19 +46,52 @@ func (s *Storage) Upsert(ctx context.Context, u *userPkg.User) error {
 		created_at,
 		lang,
 		notify_type,
-		notify_channel_id
+		notify_channel_id,
+		notify_delivery,
+		digest_hour,
+		muted_notify_events,
+		quiet_hours_from,
+		quiet_hours_to,
+		timezone,
+		email,
+		is_email_verified,
+		email_code,
+		email_code_expires_at,
+		email_code_attempts
 	) VALUES (
 		:id,
 		:fullname,
 		:created_at,
 		:lang,
 		:notify_type,
-		:notify_channel_id
+		:notify_channel_id,
+		:notify_delivery,
+		:digest_hour,
+		:muted_notify_events,
+		:quiet_hours_from,
+		:quiet_hours_to,
+		:timezone,
+		:email,
+		:is_email_verified,
+		:email_code,
+		:email_code_expires_at,
+		:email_code_attempts
 	) ON CONFLICT (id) DO UPDATE SET
 		fullname = :fullname,
 		lang = :lang,
 		notify_type = :notify_type,
-		notify_channel_id = :notify_channel_id`
+		notify_channel_id = :notify_channel_id,
+		notify_delivery = :notify_delivery,
+		digest_hour = :digest_hour,
+		muted_notify_events = :muted_notify_events,
+		quiet_hours_from = :quiet_hours_from,
+		quiet_hours_to = :quiet_hours_to,
+		timezone = :timezone,
+		email = :email,
+		is_email_verified = :is_email_verified,
+		email_code = :email_code,
+		email_code_expires_at = :email_code_expires_at,
+		email_code_attempts = :email_code_attempts`
 
 	var notifyType *string
 	if u.NotifyType != nil {
@@ -55,12 +100,23 @@ func (s *Storage) Upsert(ctx context.Context, u *userPkg.User) error {
 	}
 
 	userPersistent := userPersistent{
-		ID:              string(u.ID),
-		FullName:        u.FullName,
-		CreatedAt:       u.CreatedAt,
-		Language:        string(u.Language),
-		NotifyType:      notifyType,
-		NotifyChannelID: u.NotifyChannelID,
+		ID:                string(u.ID),
+		FullName:          u.FullName,
+		CreatedAt:         u.CreatedAt,
+		Language:          string(u.Language),
+		NotifyType:        notifyType,
+		NotifyChannelID:   u.NotifyChannelID,
+		NotifyDelivery:    string(u.NotifyDelivery),
+		DigestHour:        u.DigestHour,
+		MutedEvents:       joinEventKinds(u.MutedNotifyEvents),
+		QuietHoursFrom:    u.QuietHoursFrom,
+		QuietHoursTo:      u.QuietHoursTo,
+		Timezone:          u.Timezone,
+		Email:             u.Email,
+		IsEmailVerified:   u.IsEmailVerified,
+		EmailCode:         u.EmailCode,
+		EmailCodeExpires:  u.EmailCodeExpires,
+		EmailCodeAttempts: u.EmailCodeAttempts,
 	}
 	_, err := s.db.NamedExecContext(ctx, query, userPersistent)
 	return err
@@ -83,15 +139,47 @@ func (s *Storage) Get(ctx context.Context, id userPkg.ID) (*userPkg.User, error)
 		notifyType = &typedType
 	}
 	return &userPkg.User{
-		ID:              userPkg.ID(userPersistent.ID),
-		FullName:        userPersistent.FullName,
-		CreatedAt:       userPersistent.CreatedAt,
-		Language:        userPkg.Language(userPersistent.Language),
-		NotifyType:      notifyType,
-		NotifyChannelID: userPersistent.NotifyChannelID,
+		ID:                userPkg.ID(userPersistent.ID),
+		FullName:          userPersistent.FullName,
+		CreatedAt:         userPersistent.CreatedAt,
+		Language:          userPkg.Language(userPersistent.Language),
+		NotifyType:        notifyType,
+		NotifyChannelID:   userPersistent.NotifyChannelID,
+		NotifyDelivery:    notify.Delivery(userPersistent.NotifyDelivery),
+		DigestHour:        userPersistent.DigestHour,
+		MutedNotifyEvents: splitEventKinds(userPersistent.MutedEvents),
+		QuietHoursFrom:    userPersistent.QuietHoursFrom,
+		QuietHoursTo:      userPersistent.QuietHoursTo,
+		Timezone:          userPersistent.Timezone,
+		Email:             userPersistent.Email,
+		IsEmailVerified:   userPersistent.IsEmailVerified,
+		EmailCode:         userPersistent.EmailCode,
+		EmailCodeExpires:  userPersistent.EmailCodeExpires,
+		EmailCodeAttempts: userPersistent.EmailCodeAttempts,
 	}, nil
 }
 
+// muted event kinds are stored as a comma separated list
+func joinEventKinds(kinds []notify.EventKind) string {
+	kindStrings := make([]string, len(kinds))
+	for i, kind := range kinds {
+		kindStrings[i] = string(kind)
+	}
+	return strings.Join(kindStrings, ",")
+}
+
+func splitEventKinds(kinds string) []notify.EventKind {
+	if kinds == "" {
+		return nil
+	}
+	kindStrings := strings.Split(kinds, ",")
+	eventKinds := make([]notify.EventKind, len(kindStrings))
+	for i, kind := range kindStrings {
+		eventKinds[i] = notify.EventKind(kind)
+	}
+	return eventKinds
+}
+
 func (s *Storage) GetDailyStats(ctx context.Context, duration time.Duration) ([]*userPkg.Stats, error) {
 	createdAt := time.Now().Add(-duration)
 	// start of the day
@@ -7,19 +7,108 @@ import (
 	"time"
 )
 
-var ErrNotFound = errors.New("user not found")
+var (
+	ErrNotFound              = errors.New("user not found")
+	ErrInvalidEmail          = errors.New("invalid email")
+	ErrEmailCodeInvalid      = errors.New("invalid email verification code")
+	ErrEmailCodeExpired      = errors.New("email verification code expired")
+	ErrEmailCodeAttempts     = errors.New("too many email verification attempts")
+	ErrEmailCodeTooSoon      = errors.New("email verification code was sent recently")
+	ErrEmailNotVerified      = errors.New("email is not verified")
+	ErrNotifyChannelNotFound = errors.New("notify channel not found")
+)
 
 type ID string
 type Language string
 
 type User struct {
-	ID              ID
-	FullName        string
-	Language        Language
-	NotifyType      *notify.Type
-	NotifyChannelID *string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
+	ID                ID
+	FullName          string
+	Language          Language
+	NotifyType        *notify.Type
+	NotifyChannelID   *string
+	NotifyDelivery    notify.Delivery
+	DigestHour        int
+	MutedNotifyEvents []notify.EventKind
+	QuietHoursFrom    *int
+	QuietHoursTo      *int
+	Timezone          string
+	Email             *string
+	IsEmailVerified   bool
+	EmailCode         *string
+	EmailCodeExpires  *time.Time
+	EmailCodeAttempts int
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// GetNotifyChannelID returns the address for the current notify type. Telegram chat is kept in NotifyChannelID
+// while email notifications go to the verified email, so switching between them doesn't lose the chat.
+func (u *User) GetNotifyChannelID() (string, bool) {
+	if u.NotifyType == nil {
+		return "", false
+	}
+	switch *u.NotifyType {
+	case notify.TypeEmail:
+		if u.Email == nil || !u.IsEmailVerified {
+			return "", false
+		}
+		return *u.Email, true
+	default:
+		if u.NotifyChannelID == nil {
+			return "", false
+		}
+		return *u.NotifyChannelID, true
+	}
+}
+
+func (u *User) IsDigestDelivery() bool {
+	return u.NotifyDelivery == notify.DeliveryDaily || u.NotifyDelivery == notify.DeliveryWeekly
+}
+
+// GetLocation returns the user's timezone, UTC when it isn't set or unknown
+func (u *User) GetLocation() *time.Location {
+	if u.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// IsNotifyEventEnabled checks the muted kinds, so kinds added later are enabled by default
+func (u *User) IsNotifyEventEnabled(kind notify.EventKind) bool {
+	for _, muted := range u.MutedNotifyEvents {
+		if muted == kind {
+			return false
+		}
+	}
+	return true
+}
+
+// IsQuietAt reports whether the time falls into the user's quiet hours. The range may wrap midnight, e.g. 22-8.
+func (u *User) IsQuietAt(at time.Time) bool {
+	if u.QuietHoursFrom == nil || u.QuietHoursTo == nil || *u.QuietHoursFrom == *u.QuietHoursTo {
+		return false
+	}
+	hour := at.In(u.GetLocation()).Hour()
+	from, to := *u.QuietHoursFrom, *u.QuietHoursTo
+	if from < to {
+		return hour >= from && hour < to
+	}
+	return hour >= from || hour < to
+}
+
+// IsDigestDue reports whether the digest must be sent at the given hour of the user's timezone.
+// Weekly digests are sent on Mondays.
+func (u *User) IsDigestDue(at time.Time) bool {
+	at = at.In(u.GetLocation())
+	if !u.IsDigestDelivery() || at.Hour() != u.DigestHour {
+		return false
+	}
+	return u.NotifyDelivery == notify.DeliveryDaily || at.Weekday() == time.Monday
 }
 
 type Stats struct {
@@ -6,4 +6,21 @@ var ErrNotFound = errors.New("wishlist not found")
 var ErrItemNotFound = errors.New("wishlist item not found")
 var ErrBookingNotAvailable = errors.New("item's booking not available")
 var ErrItemAlreadyBooked = errors.New("item already booked")
-var ErrItemBookedByAnotherUser = errors.New("item booked by another user")
+var ErrInvalidQuantity = errors.New("invalid item quantity")
+var ErrNotEnoughUnits = errors.New("not enough units left to book")
+var ErrQuantityBelowBooked = errors.New("item quantity is less than booked units")
+var ErrDefaultWishlistRemoval = errors.New("default wishlist can't be archived or deleted")
+var ErrArchivedWishlistDefault = errors.New("archived wishlist can't be default")
+var ErrInvalidVisibility = errors.New("invalid wishlist visibility")
+var ErrItemHasNoPrice = errors.New("item has no price")
+var ErrItemHasContributions = errors.New("item has contributions")
+var ErrItemFullyCovered = errors.New("item fully covered by contributions")
+var ErrInvalidContribution = errors.New("invalid contribution amount")
+var ErrContributionCurrencyMismatch = errors.New("contribution currency doesn't match the product")
+var ErrInvalidBookingExpiry = errors.New("booking expiry must be in the future")
+var ErrItemFulfilled = errors.New("wishlist item already fulfilled")
+var ErrItemNotFulfilled = errors.New("wishlist item isn't fulfilled")
+var ErrInvalidPriority = errors.New("invalid item priority")
+var ErrInvalidItemsOrder = errors.New("invalid items order")
+var ErrInvalidReorder = errors.New("reorder list must contain distinct active items of the wishlist")
+var ErrConcurrentUpdate = errors.New("wishlist item was changed concurrently")
@@ -2,24 +2,71 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/bojanz/currency"
 	"github.com/google/uuid"
 	"github.com/grulex/go-wishlist/pkg/eventmanager"
 	"github.com/grulex/go-wishlist/pkg/events/wish"
+	wishlistEvents "github.com/grulex/go-wishlist/pkg/events/wishlist"
+	"github.com/grulex/go-wishlist/pkg/product"
 	"github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	"sort"
 	"time"
 )
 
 type storage interface {
+	InTransaction(ctx context.Context, change func(ctx context.Context) error) error
 	Upsert(ctx context.Context, wishlist *wishlistPkg.Wishlist) error
+	SetDefault(ctx context.Context, userID user.ID, id wishlistPkg.ID) error
+	Delete(ctx context.Context, id wishlistPkg.ID) error
+	AddInvite(ctx context.Context, invite *wishlistPkg.Invite) error
+	IsInvited(ctx context.Context, wishlistID wishlistPkg.ID, userID user.ID) (bool, error)
+	GetInvites(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Invite, error)
+	DeleteInvite(ctx context.Context, wishlistID wishlistPkg.ID, userID user.ID) error
+	DeleteInvites(ctx context.Context, wishlistID wishlistPkg.ID) error
 	Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error)
 	GetByUserID(ctx context.Context, userID user.ID) ([]*wishlistPkg.Wishlist, error)
-	GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, limit, offset uint) (items []*wishlistPkg.Item, haveMore bool, err error)
+	GetWithOccasion(ctx context.Context) ([]*wishlistPkg.Wishlist, error)
+	SetOccasionReminded(ctx context.Context, id wishlistPkg.ID, occasion time.Time) error
+	GetWishlistItems(
+		ctx context.Context,
+		wishlistID wishlistPkg.ID,
+		order wishlistPkg.ItemsOrder,
+		limit, offset uint,
+	) (items []*wishlistPkg.Item, haveMore bool, err error)
 	GetWishlistItemByID(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error)
+	GetFulfilledItems(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Item, error)
+	GetItemsByProductID(ctx context.Context, productID product.ID) ([]*wishlistPkg.Item, error)
 	UpsertWishlistItem(ctx context.Context, item *wishlistPkg.Item) error
+	UpdateWishlistItem(ctx context.Context, item *wishlistPkg.Item) error
+	SetItemPositions(ctx context.Context, wishlistID wishlistPkg.ID, productIDs []product.ID) error
 	DeleteWishlistItem(ctx context.Context, item wishlistPkg.ItemID) error
+	UpsertContribution(ctx context.Context, contribution *wishlistPkg.Contribution, itemVersion uint) error
+	GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) ([]*wishlistPkg.Contribution, error)
+	GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Contribution, error)
+	DeleteContribution(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, itemVersion uint) error
+	UpsertBooking(ctx context.Context, booking *wishlistPkg.Booking, itemVersion uint) error
+	GetBookings(ctx context.Context, itemID wishlistPkg.ItemID) ([]*wishlistPkg.Booking, error)
+	GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Booking, error)
+	DeleteBooking(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, itemVersion uint) error
+	GetBookingsExpiringBefore(ctx context.Context, before time.Time) ([]*wishlistPkg.Booking, error)
+	SetBookingReminderSent(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID) error
 }
 
+// occasionReminderDays is how many days before the occasion the subscribers get a reminder
+const occasionReminderDays = 7
+
+// bookingReminderBefore is how long before the expiry the booker gets a reminder
+const bookingReminderBefore = 24 * time.Hour
+
+// maxItemUpdateAttempts limits retries of an item change that lost the race to a concurrent one
+const maxItemUpdateAttempts = 10
+
 type eventManager interface {
 	Publish(ctx context.Context, event eventmanager.Event) error
 }
@@ -36,13 +83,55 @@ func NewWishlistService(storage storage, manager eventManager) *Service {
 	}
 }
 
+// Create makes the first wishlist of the user the default one. A new default wishlist takes it over from the old one.
 func (s *Service) Create(ctx context.Context, wishlist *wishlistPkg.Wishlist) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.create(ctx, wishlist)
+	})
+}
+
+func (s *Service) create(ctx context.Context, wishlist *wishlistPkg.Wishlist) error {
 	if wishlist.ID == "" {
 		wishlist.ID = wishlistPkg.ID(uuid.NewString())
 	}
+	userWishlists, err := s.storage.GetByUserID(ctx, wishlist.UserID)
+	if err != nil {
+		return err
+	}
+	isDefault := wishlist.IsDefault || wishlistPkg.Wishlists(userWishlists).GetDefault() == nil
+	wishlist.IsDefault = false
+	wishlist.IsArchived = false
+	if wishlist.Visibility == "" {
+		wishlist.Visibility = wishlistPkg.VisibilityPublic
+	}
+	if !wishlist.Visibility.IsValid() {
+		return wishlistPkg.ErrInvalidVisibility
+	}
+	wishlist.ShareToken, err = generateShareToken()
+	if err != nil {
+		return err
+	}
+	normalizeOccasion(wishlist)
+	wishlist.OccasionRemindedFor = nil
 	wishlist.CreatedAt = time.Now().UTC()
 	wishlist.UpdatedAt = wishlist.CreatedAt
-	return s.storage.Upsert(ctx, wishlist)
+	err = s.storage.Upsert(ctx, wishlist)
+	if err != nil {
+		return err
+	}
+	if isDefault {
+		err = s.storage.SetDefault(ctx, wishlist.UserID, wishlist.ID)
+		if err != nil {
+			return err
+		}
+		wishlist.IsDefault = true
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewCreatedEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    wishlist.CreatedAt,
+	}))
 }
 
 func (s *Service) Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error) {
@@ -53,23 +142,272 @@ func (s *Service) GetByUserID(ctx context.Context, userID user.ID) (wishlistPkg.
 	return s.storage.GetByUserID(ctx, userID)
 }
 
+// Update saves the wishlist info. The default and archived flags are changed by SetDefault, Archive and Restore only,
+// the share token by RotateShareToken.
 func (s *Service) Update(ctx context.Context, wishlist *wishlistPkg.Wishlist) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.update(ctx, wishlist)
+	})
+}
+
+func (s *Service) update(ctx context.Context, wishlist *wishlistPkg.Wishlist) error {
+	if !wishlist.Visibility.IsValid() {
+		return wishlistPkg.ErrInvalidVisibility
+	}
+	stored, err := s.storage.Get(ctx, wishlist.ID)
+	if err != nil {
+		return err
+	}
+	wishlist.IsDefault = stored.IsDefault
+	wishlist.IsArchived = stored.IsArchived
+	wishlist.ShareToken = stored.ShareToken
+	normalizeOccasion(wishlist)
+	wishlist.OccasionRemindedFor = stored.OccasionRemindedFor
+	wishlist.UpdatedAt = time.Now().UTC()
+	err = s.storage.Upsert(ctx, wishlist)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewUpdatedEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    wishlist.UpdatedAt,
+	}))
+}
+
+func normalizeOccasion(wishlist *wishlistPkg.Wishlist) {
+	if wishlist.OccasionDate == nil {
+		wishlist.IsOccasionYearly = false
+		return
+	}
+	occasion := wishlistPkg.TruncateToDay(*wishlist.OccasionDate)
+	wishlist.OccasionDate = &occasion
+}
+
+// SendOccasionReminders publishes the upcoming occasion event for the wishlists whose next occasion
+// is within occasionReminderDays. Each occurrence is reminded about once.
+func (s *Service) SendOccasionReminders(ctx context.Context, at time.Time) error {
+	wishlists, err := s.storage.GetWithOccasion(ctx)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, wishlist := range wishlists {
+		occasion := wishlist.NextOccasion(at)
+		if occasion == nil {
+			continue
+		}
+		daysLeft := wishlistPkg.DaysUntil(at, *occasion)
+		if daysLeft > occasionReminderDays {
+			continue
+		}
+		if wishlist.OccasionRemindedFor != nil && wishlist.OccasionRemindedFor.Equal(*occasion) {
+			continue
+		}
+
+		err = s.storage.InTransaction(ctx, func(ctx context.Context) error {
+			err := s.storage.SetOccasionReminded(ctx, wishlist.ID, *occasion)
+			if err != nil {
+				return err
+			}
+			return s.eventManager.Publish(ctx, wishlistEvents.NewOccasionUpcomingEvent(wishlistEvents.OccasionPayload{
+				WishlistID:   wishlist.ID,
+				WishOwner:    wishlist.UserID,
+				OccasionDate: *occasion,
+				DaysLeft:     daysLeft,
+				EventAt:      at,
+			}))
+		})
+		if err != nil {
+			lastErr = fmt.Errorf("remind about occasion of wishlist %s: %w", wishlist.ID, err)
+		}
+	}
+	return lastErr
+}
+
+// RotateShareToken makes the old share links useless. The invites were given for the old token, so they are
+// revoked too and the users have to get the new link to keep reading a non-public wishlist.
+func (s *Service) RotateShareToken(ctx context.Context, id wishlistPkg.ID) (string, error) {
+	var token string
+	err := s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		token, err = s.rotateShareToken(ctx, id)
+		return err
+	})
+	return token, err
+}
+
+func (s *Service) rotateShareToken(ctx context.Context, id wishlistPkg.ID) (string, error) {
+	wishlist, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	wishlist.ShareToken, err = generateShareToken()
+	if err != nil {
+		return "", err
+	}
 	wishlist.UpdatedAt = time.Now().UTC()
-	return s.storage.Upsert(ctx, wishlist)
+	err = s.storage.Upsert(ctx, wishlist)
+	if err != nil {
+		return "", err
+	}
+	err = s.storage.DeleteInvites(ctx, wishlist.ID)
+	if err != nil {
+		return "", err
+	}
+	return wishlist.ShareToken, nil
+}
+
+// CanRead checks whether the user may read the wishlist. The user is nil for anonymous requests, the token is
+// the share token from the link. Invited users read non-public wishlists without the token,
+// private wishlists are readable for the owner and the invited users only.
+func (s *Service) CanRead(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID *user.ID, token string) (bool, error) {
+	if userID != nil && *userID == wishlist.UserID {
+		return true, nil
+	}
+	if wishlist.Visibility == wishlistPkg.VisibilityPublic {
+		return true, nil
+	}
+	if wishlist.Visibility == wishlistPkg.VisibilityLink && isValidShareToken(wishlist, token) {
+		return true, nil
+	}
+	if userID == nil {
+		return false, nil
+	}
+	return s.storage.IsInvited(ctx, wishlist.ID, *userID)
+}
+
+// AcceptInvite keeps the read access of the user who came by the share link of a link wishlist, so the links without
+// the token in notifications and the profile work for them until the token is rotated.
+// The users of a private wishlist are invited by the owner only, see Invite.
+func (s *Service) AcceptInvite(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID user.ID, token string) error {
+	if wishlist.Visibility != wishlistPkg.VisibilityLink || userID == wishlist.UserID {
+		return nil
+	}
+	if !isValidShareToken(wishlist, token) {
+		return nil
+	}
+	return s.storage.AddInvite(ctx, &wishlistPkg.Invite{
+		WishlistID: wishlist.ID,
+		UserID:     userID,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+func isValidShareToken(wishlist *wishlistPkg.Wishlist, token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(wishlist.ShareToken)) == 1
+}
+
+// CanNotify checks whether the subscriber may still see the wishlist in notifications.
+// The notification links carry no token, so non-public wishlists are notified to the invited users only.
+func (s *Service) CanNotify(ctx context.Context, wishlist *wishlistPkg.Wishlist, userID user.ID) (bool, error) {
+	if wishlist.Visibility == wishlistPkg.VisibilityPublic || userID == wishlist.UserID {
+		return true, nil
+	}
+	return s.storage.IsInvited(ctx, wishlist.ID, userID)
+}
+
+func (s *Service) GetInvites(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Invite, error) {
+	return s.storage.GetInvites(ctx, wishlistID)
+}
+
+// Invite gives the user read access to the non-public wishlist. The owner has it anyway.
+func (s *Service) Invite(ctx context.Context, wishlistID wishlistPkg.ID, userID user.ID) error {
+	wishlist, err := s.storage.Get(ctx, wishlistID)
+	if err != nil {
+		return err
+	}
+	if userID == wishlist.UserID {
+		return nil
+	}
+	return s.storage.AddInvite(ctx, &wishlistPkg.Invite{
+		WishlistID: wishlist.ID,
+		UserID:     userID,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+func (s *Service) RemoveInvite(ctx context.Context, wishlistID wishlistPkg.ID, userID user.ID) error {
+	return s.storage.DeleteInvite(ctx, wishlistID, userID)
+}
+
+func generateShareToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// SetDefault moves the default flag to the wishlist, so the user always has exactly one default wishlist
+func (s *Service) SetDefault(ctx context.Context, id wishlistPkg.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.setDefault(ctx, id)
+	})
+}
+
+func (s *Service) setDefault(ctx context.Context, id wishlistPkg.ID) error {
+	wishlist, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if wishlist.IsArchived {
+		return wishlistPkg.ErrArchivedWishlistDefault
+	}
+	if wishlist.IsDefault {
+		return nil
+	}
+
+	err = s.storage.SetDefault(ctx, wishlist.UserID, wishlist.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewUpdatedEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    time.Now().UTC(),
+	}))
 }
 
 func (s *Service) Archive(ctx context.Context, id wishlistPkg.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.archive(ctx, id)
+	})
+}
+
+func (s *Service) archive(ctx context.Context, id wishlistPkg.ID) error {
 	wishlist, err := s.storage.Get(ctx, id)
 	if err != nil {
 		return err
 	}
+	if wishlist.IsDefault {
+		return wishlistPkg.ErrDefaultWishlistRemoval
+	}
 	wishlist.IsArchived = true
 	wishlist.UpdatedAt = time.Now().UTC()
 
-	return s.storage.Upsert(ctx, wishlist)
+	err = s.storage.Upsert(ctx, wishlist)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewArchivedEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    wishlist.UpdatedAt,
+	}))
 }
 
 func (s *Service) Restore(ctx context.Context, id wishlistPkg.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.restore(ctx, id)
+	})
+}
+
+func (s *Service) restore(ctx context.Context, id wishlistPkg.ID) error {
 	wishlist, err := s.storage.Get(ctx, id)
 	if err != nil {
 		return err
@@ -77,63 +415,480 @@ func (s *Service) Restore(ctx context.Context, id wishlistPkg.ID) error {
 	wishlist.IsArchived = false
 	wishlist.UpdatedAt = time.Now().UTC()
 
-	return s.storage.Upsert(ctx, wishlist)
+	err = s.storage.Upsert(ctx, wishlist)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewRestoredEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    wishlist.UpdatedAt,
+	}))
+}
+
+// Delete removes the wishlist with its items. The default wishlist can't be deleted.
+func (s *Service) Delete(ctx context.Context, id wishlistPkg.ID) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.delete(ctx, id)
+	})
+}
+
+func (s *Service) delete(ctx context.Context, id wishlistPkg.ID) error {
+	wishlist, err := s.storage.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if wishlist.IsDefault {
+		return wishlistPkg.ErrDefaultWishlistRemoval
+	}
+
+	err = s.storage.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wishlistEvents.NewDeletedEvent(wishlistEvents.Payload{
+		WishlistID: wishlist.ID,
+		WishOwner:  wishlist.UserID,
+		EventAt:    time.Now().UTC(),
+	}))
 }
 
 func (s *Service) GetWishlistItem(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error) {
 	return s.storage.GetWishlistItemByID(ctx, itemID)
 }
 
-func (s *Service) GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, limit, offset uint) ([]*wishlistPkg.Item, bool, error) {
-	return s.storage.GetWishlistItems(ctx, wishlistID, limit, offset)
+func (s *Service) GetItemsByProductID(ctx context.Context, productID product.ID) ([]*wishlistPkg.Item, error) {
+	return s.storage.GetItemsByProductID(ctx, productID)
+}
+
+// GetWishlistItems returns the active items in the given order, the owner's manual order by default
+func (s *Service) GetWishlistItems(
+	ctx context.Context,
+	wishlistID wishlistPkg.ID,
+	order wishlistPkg.ItemsOrder,
+	limit, offset uint,
+) ([]*wishlistPkg.Item, bool, error) {
+	if order == "" {
+		order = wishlistPkg.OrderByPosition
+	}
+	if !order.IsValid() {
+		return nil, false, wishlistPkg.ErrInvalidItemsOrder
+	}
+	return s.storage.GetWishlistItems(ctx, wishlistID, order, limit, offset)
 }
 
 func (s *Service) AddWishlistItem(ctx context.Context, item *wishlistPkg.Item) error {
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.addWishlistItem(ctx, item)
+	})
+}
+
+func (s *Service) addWishlistItem(ctx context.Context, item *wishlistPkg.Item) error {
+	wishlist, err := s.storage.Get(ctx, item.ID.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	if item.Quantity == 0 {
+		item.Quantity = 1
+	}
+	if item.Priority == "" {
+		item.Priority = wishlistPkg.PriorityNiceToHave
+	}
+	if !item.Priority.IsValid() {
+		return wishlistPkg.ErrInvalidPriority
+	}
 	item.CreatedAt = time.Now().UTC()
 	item.UpdatedAt = item.CreatedAt
-	return s.storage.UpsertWishlistItem(ctx, item)
+	err = s.storage.UpsertWishlistItem(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewItemAddedEvent(wish.ItemPayload{
+		ItemID:    item.ID,
+		WishOwner: wishlist.UserID,
+		EventAt:   item.CreatedAt,
+	}))
 }
 
 func (s *Service) SetBookingAvailabilityForItem(ctx context.Context, itemID wishlistPkg.ItemID, isAvailable bool) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.setBookingAvailabilityForItem(ctx, itemID, isAvailable)
+	})
+}
+
+func (s *Service) setBookingAvailabilityForItem(ctx context.Context, itemID wishlistPkg.ItemID, isAvailable bool) error {
 	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
 	if err != nil {
 		return err
 	}
+	if item.IsFulfilled() {
+		return wishlistPkg.ErrItemFulfilled
+	}
+
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
 
 	item.IsBookingAvailable = isAvailable
+	item.UpdatedAt = time.Now().UTC()
+
+	err = s.storage.UpdateWishlistItem(ctx, item)
+	if err != nil {
+		return err
+	}
 	if !isAvailable {
-		item.IsBookedBy = nil
+		err = s.cancelBookings(ctx, wishlist, item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewItemUpdatedEvent(wish.ItemUpdatePayload{
+		ItemID:             itemID,
+		WishOwner:          wishlist.UserID,
+		IsBookingAvailable: isAvailable,
+		EventAt:            item.UpdatedAt,
+	}))
+}
+
+// SetItemQuantity changes the number of desired units, it can't go below the units already booked
+func (s *Service) SetItemQuantity(ctx context.Context, itemID wishlistPkg.ItemID, quantity uint) error {
+	if quantity == 0 {
+		return wishlistPkg.ErrInvalidQuantity
+	}
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.setItemQuantity(ctx, itemID, quantity)
+	})
+}
+
+func (s *Service) setItemQuantity(ctx context.Context, itemID wishlistPkg.ItemID, quantity uint) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.Quantity == quantity {
+		return nil
+	}
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
 	}
+	if wishlistPkg.Bookings(bookings).Booked() > quantity {
+		return wishlistPkg.ErrQuantityBelowBooked
+	}
+
+	item.Quantity = quantity
 	item.UpdatedAt = time.Now().UTC()
+	return s.storage.UpdateWishlistItem(ctx, item)
+}
 
-	return s.storage.UpsertWishlistItem(ctx, item)
+func (s *Service) SetItemPriority(ctx context.Context, itemID wishlistPkg.ItemID, priority wishlistPkg.Priority) error {
+	if !priority.IsValid() {
+		return wishlistPkg.ErrInvalidPriority
+	}
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if item.Priority == priority {
+			return nil
+		}
+		item.Priority = priority
+		item.UpdatedAt = time.Now().UTC()
+		return s.storage.UpdateWishlistItem(ctx, item)
+	})
+}
+
+// ReorderItems rearranges the given items in the owner's manual order. It may be a part of the list,
+// e.g. the loaded page: the items are swapped among their own places and the other items keep theirs.
+func (s *Service) ReorderItems(ctx context.Context, wishlistID wishlistPkg.ID, productIDs []product.ID) error {
+	if len(productIDs) == 0 {
+		return wishlistPkg.ErrInvalidReorder
+	}
+	seen := make(map[product.ID]bool, len(productIDs))
+	for _, productID := range productIDs {
+		if seen[productID] {
+			return wishlistPkg.ErrInvalidReorder
+		}
+		seen[productID] = true
+
+		item, err := s.storage.GetWishlistItemByID(ctx, wishlistPkg.ItemID{WishlistID: wishlistID, ProductID: productID})
+		if errors.Is(err, wishlistPkg.ErrItemNotFound) {
+			return wishlistPkg.ErrInvalidReorder
+		}
+		if err != nil {
+			return err
+		}
+		if item.IsFulfilled() {
+			return wishlistPkg.ErrInvalidReorder
+		}
+	}
+	return s.storage.SetItemPositions(ctx, wishlistID, productIDs)
 }
 
 func (s *Service) RemoveItem(ctx context.Context, item wishlistPkg.ItemID) error {
-	return s.storage.DeleteWishlistItem(ctx, item)
+	return s.storage.InTransaction(ctx, func(ctx context.Context) error {
+		return s.removeItem(ctx, item)
+	})
+}
+
+func (s *Service) removeItem(ctx context.Context, item wishlistPkg.ItemID) error {
+	wishlist, err := s.storage.Get(ctx, item.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.DeleteWishlistItem(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewItemRemovedEvent(wish.ItemPayload{
+		ItemID:    item,
+		WishOwner: wishlist.UserID,
+		EventAt:   time.Now().UTC(),
+	}))
 }
 
-func (s *Service) BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID) error {
+// FulfillItem marks the item as received by the owner. The item leaves the active list,
+// its bookings and contributions stay as the record of the givers.
+func (s *Service) FulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.fulfillItem(ctx, itemID)
+	})
+}
+
+func (s *Service) fulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error {
 	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
 	if err != nil {
 		return err
 	}
+	if item.IsFulfilled() {
+		return nil
+	}
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
+	givers, err := s.getGivers(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	item.FulfilledAt = &now
+	item.UpdatedAt = now
+	err = s.storage.UpdateWishlistItem(ctx, item)
+	if err != nil {
+		return err
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewItemFulfilledEvent(wish.GiftPayload{
+		ItemID:    itemID,
+		WishOwner: wishlist.UserID,
+		Givers:    givers,
+		EventAt:   now,
+	}))
+}
+
+// UnfulfillItem returns a fulfilled item to the active list
+func (s *Service) UnfulfillItem(ctx context.Context, itemID wishlistPkg.ItemID) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+		if err != nil {
+			return err
+		}
+		if !item.IsFulfilled() {
+			return nil
+		}
+		item.FulfilledAt = nil
+		item.UpdatedAt = time.Now().UTC()
+		return s.storage.UpdateWishlistItem(ctx, item)
+	})
+}
+
+// GetReceivedGifts returns the fulfilled items of all user's wishlists, the most recent first
+func (s *Service) GetReceivedGifts(ctx context.Context, userID user.ID) ([]*wishlistPkg.Gift, error) {
+	wishlists, err := s.storage.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	gifts := make([]*wishlistPkg.Gift, 0)
+	for _, wishlist := range wishlists {
+		items, err := s.storage.GetFulfilledItems(ctx, wishlist.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			continue
+		}
+		bookings, err := s.storage.GetBookingsByWishlist(ctx, wishlist.ID)
+		if err != nil {
+			return nil, err
+		}
+		contributions, err := s.storage.GetContributionsByWishlist(ctx, wishlist.ID)
+		if err != nil {
+			return nil, err
+		}
+		bookingsMap := make(map[product.ID]wishlistPkg.Bookings)
+		for _, booking := range bookings {
+			bookingsMap[booking.ItemID.ProductID] = append(bookingsMap[booking.ItemID.ProductID], booking)
+		}
+		contributionsMap := make(map[product.ID]wishlistPkg.Contributions)
+		for _, contribution := range contributions {
+			contributionsMap[contribution.ItemID.ProductID] = append(contributionsMap[contribution.ItemID.ProductID], contribution)
+		}
+		for _, item := range items {
+			gifts = append(gifts, &wishlistPkg.Gift{
+				Item:   item,
+				Givers: wishlistPkg.GetGivers(bookingsMap[item.ID.ProductID], contributionsMap[item.ID.ProductID]),
+			})
+		}
+	}
+
+	sort.Slice(gifts, func(i, j int) bool {
+		return gifts[i].Item.FulfilledAt.After(*gifts[j].Item.FulfilledAt)
+	})
+	return gifts, nil
+}
+
+// ThankGivers sends the owner's message to everyone who gave the fulfilled item
+func (s *Service) ThankGivers(ctx context.Context, itemID wishlistPkg.ItemID, message string) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if !item.IsFulfilled() {
+		return wishlistPkg.ErrItemNotFulfilled
+	}
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
+	givers, err := s.getGivers(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if len(givers) == 0 {
+		return nil
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewItemThankedEvent(wish.GiftPayload{
+		ItemID:    itemID,
+		WishOwner: wishlist.UserID,
+		Givers:    givers,
+		Message:   message,
+		EventAt:   time.Now().UTC(),
+	}))
+}
+
+func (s *Service) getGivers(ctx context.Context, itemID wishlistPkg.ItemID) ([]user.ID, error) {
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	contributions, err := s.storage.GetContributions(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return wishlistPkg.GetGivers(bookings, contributions), nil
+}
+
+func (s *Service) GetBookings(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Bookings, error) {
+	return s.storage.GetBookings(ctx, itemID)
+}
+
+func (s *Service) GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Bookings, error) {
+	return s.storage.GetBookingsByWishlist(ctx, wishlistID)
+}
+
+// BookItem reserves the quantity of units for the user until the optional expiry.
+// A repeated call replaces the quantity and the expiry of the user's booking.
+func (s *Service) BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, quantity uint, expiresAt *time.Time) error {
+	if quantity == 0 {
+		return wishlistPkg.ErrInvalidQuantity
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return wishlistPkg.ErrInvalidBookingExpiry
+	}
+	err := s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.bookItem(ctx, itemID, userID, quantity, expiresAt)
+	})
+	if errors.Is(err, wishlistPkg.ErrConcurrentUpdate) {
+		// the attempts are used up by the others booking the item at the same moment
+		return wishlistPkg.ErrItemAlreadyBooked
+	}
+	return err
+}
+
+func (s *Service) bookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, quantity uint, expiresAt *time.Time) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.IsFulfilled() {
+		return wishlistPkg.ErrItemFulfilled
+	}
 	if !item.IsBookingAvailable {
 		return wishlistPkg.ErrBookingNotAvailable
 	}
-	if item.IsBookedBy != nil && *item.IsBookedBy == userID {
+	contributions, err := s.storage.GetContributions(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if len(contributions) > 0 {
+		return wishlistPkg.ErrItemHasContributions
+	}
+
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	own := wishlistPkg.Bookings(bookings).GetByUser(userID)
+	var oldQuantity uint
+	if own != nil {
+		oldQuantity = own.Quantity
+	}
+	if oldQuantity == quantity && isSameExpiry(own.ExpiresAt, expiresAt) {
 		return nil
 	}
-	if item.IsBookedBy != nil {
+	available := item.Remaining(bookings) + oldQuantity
+	if available == 0 {
 		return wishlistPkg.ErrItemAlreadyBooked
 	}
-	item.IsBookedBy = &userID
-	item.UpdatedAt = time.Now().UTC()
+	if available < quantity {
+		return wishlistPkg.ErrNotEnoughUnits
+	}
 
-	err = s.storage.UpsertWishlistItem(ctx, item)
+	now := time.Now().UTC()
+	booking := &wishlistPkg.Booking{
+		ItemID:    itemID,
+		UserID:    userID,
+		Quantity:  quantity,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var oldBookedBy *user.ID
+	if own != nil {
+		booking.CreatedAt = own.CreatedAt
+		oldBookedBy = &own.UserID
+	}
+	err = s.storage.UpsertBooking(ctx, booking, item.Version)
 	if err != nil {
 		return err
 	}
+	if oldQuantity == quantity {
+		// only the expiry has changed
+		return nil
+	}
 
 	wishlist, err := s.storage.Get(ctx, item.ID.WishlistID)
 	if err != nil {
@@ -143,34 +898,147 @@ func (s *Service) BookItem(ctx context.Context, itemID wishlistPkg.ItemID, userI
 	return s.eventManager.Publish(ctx, wish.NewBookingUpdateEvent(wish.BookingPayload{
 		ItemID:      itemID,
 		WishOwner:   wishlist.UserID,
-		OldBookedBy: nil,
+		OldBookedBy: oldBookedBy,
 		NewBookedBy: &userID,
+		OldQuantity: oldQuantity,
+		NewQuantity: quantity,
 		EventBy:     userID,
-		EventAt:     time.Now().UTC(),
+		EventAt:     now,
 	}))
 }
 
-func (s *Service) UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID) error {
+// UnBookItem releases the quantity of units booked by the user, zero releases the whole booking.
+// The owner of the wishlist without a booking of their own cancels all bookings of the item.
+func (s *Service) UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, quantity uint) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.unBookItem(ctx, itemID, userID, quantity)
+	})
+}
+
+func (s *Service) unBookItem(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, quantity uint) error {
 	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
 	if err != nil {
 		return err
 	}
-	if item.IsBookedBy == nil {
+	if item.IsFulfilled() {
+		return wishlistPkg.ErrItemFulfilled
+	}
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
+	own := wishlistPkg.Bookings(bookings).GetByUser(userID)
+	if own == nil {
+		if wishlist.UserID == userID {
+			return s.cancelBookings(ctx, wishlist, item)
+		}
 		return nil
 	}
-	wishlist, err := s.storage.Get(ctx, item.ID.WishlistID)
+
+	now := time.Now().UTC()
+	payload := wish.BookingPayload{
+		ItemID:      itemID,
+		WishOwner:   wishlist.UserID,
+		OldBookedBy: &own.UserID,
+		OldQuantity: own.Quantity,
+		EventBy:     userID,
+		EventAt:     now,
+	}
+	if quantity == 0 || quantity >= own.Quantity {
+		err = s.storage.DeleteBooking(ctx, itemID, userID, item.Version)
+	} else {
+		payload.NewBookedBy = &own.UserID
+		payload.NewQuantity = own.Quantity - quantity
+		err = s.storage.UpsertBooking(ctx, &wishlistPkg.Booking{
+			ItemID:         itemID,
+			UserID:         userID,
+			Quantity:       payload.NewQuantity,
+			ExpiresAt:      own.ExpiresAt,
+			IsReminderSent: own.IsReminderSent,
+			CreatedAt:      own.CreatedAt,
+			UpdatedAt:      now,
+		}, item.Version)
+	}
 	if err != nil {
 		return err
 	}
-	isOwner := wishlist.UserID == userID
-	if !isOwner && *item.IsBookedBy != userID {
-		return wishlistPkg.ErrItemBookedByAnotherUser
+
+	return s.eventManager.Publish(ctx, wish.NewBookingUpdateEvent(payload))
+}
+
+// ReleaseExpiredBookings reminds the bookers about bookings expiring within bookingReminderBefore
+// and releases the bookings expired at the given time
+func (s *Service) ReleaseExpiredBookings(ctx context.Context, at time.Time) error {
+	bookings, err := s.storage.GetBookingsExpiringBefore(ctx, at.Add(bookingReminderBefore))
+	if err != nil {
+		return err
 	}
 
-	oldBookedBy := *item.IsBookedBy
-	item.IsBookedBy = nil
-	item.UpdatedAt = time.Now().UTC()
-	err = s.storage.UpsertWishlistItem(ctx, item)
+	var lastErr error
+	for _, booking := range bookings {
+		if booking.IsExpiredAt(at) {
+			err = s.retryItemUpdate(ctx, func(ctx context.Context) error {
+				return s.releaseExpiredBooking(ctx, booking.ItemID, booking.UserID, at)
+			})
+		} else if !booking.IsReminderSent {
+			err = s.storage.InTransaction(ctx, func(ctx context.Context) error {
+				return s.remindAboutBookingExpiry(ctx, booking, at)
+			})
+		}
+		if err != nil {
+			lastErr = fmt.Errorf("process expiry of booking %s/%s by %s: %w", booking.ItemID.WishlistID, booking.ItemID.ProductID, booking.UserID, err)
+		}
+	}
+	return lastErr
+}
+
+func (s *Service) remindAboutBookingExpiry(ctx context.Context, booking *wishlistPkg.Booking, at time.Time) error {
+	wishlist, err := s.storage.Get(ctx, booking.ItemID.WishlistID)
+	if err != nil {
+		return err
+	}
+	err = s.storage.SetBookingReminderSent(ctx, booking.ItemID, booking.UserID)
+	if err != nil {
+		return err
+	}
+	return s.eventManager.Publish(ctx, wish.NewBookingExpiringEvent(wish.BookingExpiringPayload{
+		ItemID:    booking.ItemID,
+		WishOwner: wishlist.UserID,
+		BookedBy:  booking.UserID,
+		Quantity:  booking.Quantity,
+		ExpiresAt: *booking.ExpiresAt,
+		EventAt:   at,
+	}))
+}
+
+func (s *Service) releaseExpiredBooking(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, at time.Time) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.IsFulfilled() {
+		// the bookings of a received item are kept as the gift history
+		return nil
+	}
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	// the booker could have extended the booking in the meantime
+	booking := wishlistPkg.Bookings(bookings).GetByUser(userID)
+	if booking == nil || !booking.IsExpiredAt(at) {
+		return nil
+	}
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.DeleteBooking(ctx, itemID, userID, item.Version)
 	if err != nil {
 		return err
 	}
@@ -178,9 +1046,221 @@ func (s *Service) UnBookItem(ctx context.Context, itemID wishlistPkg.ItemID, use
 	return s.eventManager.Publish(ctx, wish.NewBookingUpdateEvent(wish.BookingPayload{
 		ItemID:      itemID,
 		WishOwner:   wishlist.UserID,
-		OldBookedBy: &oldBookedBy,
-		NewBookedBy: nil,
-		EventBy:     userID,
-		EventAt:     time.Now().UTC(),
+		OldBookedBy: &booking.UserID,
+		OldQuantity: booking.Quantity,
+		IsExpired:   true,
+		EventBy:     booking.UserID,
+		EventAt:     at,
+	}))
+}
+
+func isSameExpiry(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// cancelBookings removes all bookings of the item on behalf of the owner
+func (s *Service) cancelBookings(ctx context.Context, wishlist *wishlistPkg.Wishlist, item *wishlistPkg.Item) error {
+	itemID := item.ID
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	version := item.Version
+	for _, booking := range bookings {
+		err = s.storage.DeleteBooking(ctx, itemID, booking.UserID, version)
+		if err != nil {
+			return err
+		}
+		version++
+		err = s.eventManager.Publish(ctx, wish.NewBookingUpdateEvent(wish.BookingPayload{
+			ItemID:      itemID,
+			WishOwner:   wishlist.UserID,
+			OldBookedBy: &booking.UserID,
+			OldQuantity: booking.Quantity,
+			EventBy:     wishlist.UserID,
+			EventAt:     time.Now().UTC(),
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) (wishlistPkg.Contributions, error) {
+	return s.storage.GetContributions(ctx, itemID)
+}
+
+func (s *Service) GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) (wishlistPkg.Contributions, error) {
+	return s.storage.GetContributionsByWishlist(ctx, wishlistID)
+}
+
+// Contribute pledges the amount toward the item's price or replaces the previous pledge of the user.
+// The price is the current price of the item's product, the amount must be in the same currency.
+func (s *Service) Contribute(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, amount currency.Amount, price *currency.Amount) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.contribute(ctx, itemID, userID, amount, price)
+	})
+}
+
+func (s *Service) contribute(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, amount currency.Amount, price *currency.Amount) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.IsFulfilled() {
+		return wishlistPkg.ErrItemFulfilled
+	}
+	if !item.IsBookingAvailable {
+		return wishlistPkg.ErrBookingNotAvailable
+	}
+	bookings, err := s.storage.GetBookings(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if len(bookings) > 0 {
+		return wishlistPkg.ErrItemAlreadyBooked
+	}
+	if price == nil || !price.IsPositive() {
+		return wishlistPkg.ErrItemHasNoPrice
+	}
+	if !amount.IsPositive() {
+		return wishlistPkg.ErrInvalidContribution
+	}
+	if amount.CurrencyCode() != price.CurrencyCode() {
+		return wishlistPkg.ErrContributionCurrencyMismatch
+	}
+
+	contributions, err := s.storage.GetContributions(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	old, others := splitContributions(contributions, userID)
+	isCovered, err := others.IsFullyCovered(*price)
+	if err != nil {
+		return err
+	}
+	if isCovered {
+		return wishlistPkg.ErrItemFullyCovered
+	}
+
+	now := time.Now().UTC()
+	contribution := &wishlistPkg.Contribution{
+		ItemID:    itemID,
+		UserID:    userID,
+		Amount:    amount,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	var oldAmount *currency.Amount
+	if old != nil {
+		contribution.CreatedAt = old.CreatedAt
+		oldAmount = &old.Amount
+	}
+	err = s.storage.UpsertContribution(ctx, contribution, item.Version)
+	if err != nil {
+		return err
+	}
+
+	return s.publishContributionUpdate(ctx, itemID, userID, oldAmount, &amount, contributions, append(others, contribution), price)
+}
+
+// WithdrawContribution removes the pledge of the user, it does nothing if there is no pledge
+func (s *Service) WithdrawContribution(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, price *currency.Amount) error {
+	return s.retryItemUpdate(ctx, func(ctx context.Context) error {
+		return s.withdrawContribution(ctx, itemID, userID, price)
+	})
+}
+
+func (s *Service) withdrawContribution(ctx context.Context, itemID wishlistPkg.ItemID, userID user.ID, price *currency.Amount) error {
+	item, err := s.storage.GetWishlistItemByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if item.IsFulfilled() {
+		return wishlistPkg.ErrItemFulfilled
+	}
+	contributions, err := s.storage.GetContributions(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	old, others := splitContributions(contributions, userID)
+	if old == nil {
+		return nil
+	}
+
+	err = s.storage.DeleteContribution(ctx, itemID, userID, item.Version)
+	if err != nil {
+		return err
+	}
+
+	return s.publishContributionUpdate(ctx, itemID, userID, &old.Amount, nil, contributions, others, price)
+}
+
+func (s *Service) publishContributionUpdate(
+	ctx context.Context,
+	itemID wishlistPkg.ItemID,
+	userID user.ID,
+	oldAmount, newAmount *currency.Amount,
+	before, after wishlistPkg.Contributions,
+	price *currency.Amount,
+) error {
+	wishlist, err := s.storage.Get(ctx, itemID.WishlistID)
+	if err != nil {
+		return err
+	}
+
+	var progress, previousProgress uint
+	if price != nil && price.IsPositive() {
+		progress, err = after.Progress(*price)
+		if err != nil {
+			return err
+		}
+		previousProgress, err = before.Progress(*price)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.eventManager.Publish(ctx, wish.NewContributionUpdateEvent(wish.ContributionPayload{
+		ItemID:          itemID,
+		WishOwner:       wishlist.UserID,
+		Contributor:     userID,
+		OldAmount:       oldAmount,
+		NewAmount:       newAmount,
+		Progress:        progress,
+		IsFullyCovered:  progress >= 100,
+		WasFullyCovered: previousProgress >= 100,
+		EventAt:         time.Now().UTC(),
 	}))
 }
+
+// splitContributions separates the contribution of the user from the others
+func splitContributions(contributions wishlistPkg.Contributions, userID user.ID) (*wishlistPkg.Contribution, wishlistPkg.Contributions) {
+	var own *wishlistPkg.Contribution
+	others := make(wishlistPkg.Contributions, 0, len(contributions))
+	for _, c := range contributions {
+		if c.UserID == userID {
+			own = c
+			continue
+		}
+		others = append(others, c)
+	}
+	return own, others
+}
+
+// retryItemUpdate repeats the read-check-write operation on an item while it fails with ErrConcurrentUpdate.
+// Every attempt runs in its own transaction, so the events published by the update are stored with its changes.
+func (s *Service) retryItemUpdate(ctx context.Context, update func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < maxItemUpdateAttempts; attempt++ {
+		err = s.storage.InTransaction(ctx, update)
+		if !errors.Is(err, wishlistPkg.ErrConcurrentUpdate) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/grulex/go-wishlist/pkg/eventmanager"
+	"github.com/grulex/go-wishlist/pkg/product"
+	productInmemory "github.com/grulex/go-wishlist/pkg/product/storage/inmemory"
+	"github.com/grulex/go-wishlist/pkg/user"
+	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
+	wishlistInmemory "github.com/grulex/go-wishlist/pkg/wishlist/storage/inmemory"
+)
+
+type nopEventManager struct{}
+
+func (nopEventManager) Publish(_ context.Context, _ eventmanager.Event) error {
+	return nil
+}
+
+func newTestItem(t *testing.T, s *Service, quantity uint) wishlistPkg.ItemID {
+	t.Helper()
+	ctx := context.Background()
+	wishlist := &wishlistPkg.Wishlist{UserID: "owner", Title: "Birthday"}
+	if err := s.Create(ctx, wishlist); err != nil {
+		t.Fatalf("create wishlist: %v", err)
+	}
+	item := &wishlistPkg.Item{
+		ID:                 wishlistPkg.ItemID{WishlistID: wishlist.ID, ProductID: "product"},
+		IsBookingAvailable: true,
+		Quantity:           quantity,
+	}
+	if err := s.AddWishlistItem(ctx, item); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	return item.ID
+}
+
+func TestBookItemConcurrently(t *testing.T) {
+	const goroutines = 50
+	s := NewWishlistService(wishlistInmemory.NewWishlistInMemory(productInmemory.NewProductInMemory()), nopEventManager{})
+	itemID := newTestItem(t, s, 1)
+
+	start := make(chan struct{})
+	errs := make(chan error, goroutines)
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(userID user.ID) {
+			defer wg.Done()
+			<-start
+			errs <- s.BookItem(context.Background(), itemID, userID, 1, nil)
+		}(user.ID(fmt.Sprintf("user-%d", i)))
+	}
+	close(start)
+	wg.Wait()
+	close(errs)
+
+	var booked, alreadyBooked int
+	for err := range errs {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, wishlistPkg.ErrItemAlreadyBooked):
+			alreadyBooked++
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if booked != 1 || alreadyBooked != goroutines-1 {
+		t.Fatalf("got %d bookings and %d rejections, want 1 and %d", booked, alreadyBooked, goroutines-1)
+	}
+
+	bookings, err := s.GetBookings(context.Background(), itemID)
+	if err != nil {
+		t.Fatalf("get bookings: %v", err)
+	}
+	if len(bookings) != 1 || bookings[0].Quantity != 1 {
+		t.Fatalf("got bookings %+v, want a single booking of one unit", bookings)
+	}
+}
+
+func TestLinkWishlistAccess(t *testing.T) {
+	ctx := context.Background()
+	s := NewWishlistService(wishlistInmemory.NewWishlistInMemory(productInmemory.NewProductInMemory()), nopEventManager{})
+	wishlist := &wishlistPkg.Wishlist{UserID: "owner", Title: "Birthday", Visibility: wishlistPkg.VisibilityLink}
+	if err := s.Create(ctx, wishlist); err != nil {
+		t.Fatalf("create wishlist: %v", err)
+	}
+	friend := user.ID("friend")
+
+	canRead := func(token string) bool {
+		t.Helper()
+		ok, err := s.CanRead(ctx, wishlist, &friend, token)
+		if err != nil {
+			t.Fatalf("can read: %v", err)
+		}
+		return ok
+	}
+	canNotify := func() bool {
+		t.Helper()
+		ok, err := s.CanNotify(ctx, wishlist, friend)
+		if err != nil {
+			t.Fatalf("can notify: %v", err)
+		}
+		return ok
+	}
+
+	if !canRead(wishlist.ShareToken) || canRead("") || canNotify() {
+		t.Fatal("reading by the token must not invite the user")
+	}
+	if err := s.AcceptInvite(ctx, wishlist, friend, wishlist.ShareToken); err != nil {
+		t.Fatalf("accept invite: %v", err)
+	}
+	if !canRead("") || !canNotify() {
+		t.Fatal("the invited user must read and be notified without the token")
+	}
+
+	oldToken := wishlist.ShareToken
+	token, err := s.RotateShareToken(ctx, wishlist.ID)
+	if err != nil {
+		t.Fatalf("rotate share token: %v", err)
+	}
+	wishlist.ShareToken = token
+	if canRead("") || canRead(oldToken) || canNotify() {
+		t.Fatal("rotating the token must revoke the access given by the old one")
+	}
+}
+
+func TestPrivateWishlistAccess(t *testing.T) {
+	ctx := context.Background()
+	s := NewWishlistService(wishlistInmemory.NewWishlistInMemory(productInmemory.NewProductInMemory()), nopEventManager{})
+	wishlist := &wishlistPkg.Wishlist{UserID: "owner", Title: "Wedding", Visibility: wishlistPkg.VisibilityPrivate}
+	if err := s.Create(ctx, wishlist); err != nil {
+		t.Fatalf("create wishlist: %v", err)
+	}
+	owner, friend := user.ID("owner"), user.ID("friend")
+
+	canRead := func(userID *user.ID, token string) bool {
+		t.Helper()
+		ok, err := s.CanRead(ctx, wishlist, userID, token)
+		if err != nil {
+			t.Fatalf("can read: %v", err)
+		}
+		return ok
+	}
+
+	if !canRead(&owner, "") {
+		t.Fatal("the owner must read the private wishlist")
+	}
+	if canRead(nil, wishlist.ShareToken) || canRead(&friend, wishlist.ShareToken) {
+		t.Fatal("the share token must not open the private wishlist")
+	}
+	if err := s.AcceptInvite(ctx, wishlist, friend, wishlist.ShareToken); err != nil {
+		t.Fatalf("accept invite: %v", err)
+	}
+	if canRead(&friend, "") {
+		t.Fatal("the share token must not invite the user to the private wishlist")
+	}
+
+	if err := s.Invite(ctx, wishlist.ID, friend); err != nil {
+		t.Fatalf("invite: %v", err)
+	}
+	canNotify, err := s.CanNotify(ctx, wishlist, friend)
+	if err != nil {
+		t.Fatalf("can notify: %v", err)
+	}
+	if !canRead(&friend, "") || !canNotify {
+		t.Fatal("the user invited by the owner must read the wishlist and be notified")
+	}
+
+	if err := s.RemoveInvite(ctx, wishlist.ID, friend); err != nil {
+		t.Fatalf("remove invite: %v", err)
+	}
+	if canRead(&friend, "") {
+		t.Fatal("removing the invite must revoke the access")
+	}
+}
+
+func TestSetDefaultDoesNotChangeReadWishlists(t *testing.T) {
+	ctx := context.Background()
+	s := NewWishlistService(wishlistInmemory.NewWishlistInMemory(productInmemory.NewProductInMemory()), nopEventManager{})
+	first := &wishlistPkg.Wishlist{UserID: "owner", Title: "Birthday"}
+	second := &wishlistPkg.Wishlist{UserID: "owner", Title: "Wedding"}
+	for _, w := range []*wishlistPkg.Wishlist{first, second} {
+		if err := s.Create(ctx, w); err != nil {
+			t.Fatalf("create wishlist: %v", err)
+		}
+	}
+
+	read, err := s.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get wishlist: %v", err)
+	}
+	if err := s.SetDefault(ctx, second.ID); err != nil {
+		t.Fatalf("set default: %v", err)
+	}
+	if !read.IsDefault {
+		t.Fatal("the wishlist read before SetDefault must stay as it was read")
+	}
+
+	wishlists, err := s.GetByUserID(ctx, "owner")
+	if err != nil {
+		t.Fatalf("get wishlists: %v", err)
+	}
+	if d := wishlists.GetDefault(); d == nil || d.ID != second.ID {
+		t.Fatalf("expected %s to be the default wishlist, got %+v", second.ID, d)
+	}
+}
+
+func TestReorderItemsPage(t *testing.T) {
+	ctx := context.Background()
+	s := NewWishlistService(wishlistInmemory.NewWishlistInMemory(productInmemory.NewProductInMemory()), nopEventManager{})
+	wishlist := &wishlistPkg.Wishlist{UserID: "owner", Title: "Birthday"}
+	if err := s.Create(ctx, wishlist); err != nil {
+		t.Fatalf("create wishlist: %v", err)
+	}
+	for _, productID := range []product.ID{"a", "b", "c", "d", "e"} {
+		item := &wishlistPkg.Item{ID: wishlistPkg.ItemID{WishlistID: wishlist.ID, ProductID: productID}}
+		if err := s.AddWishlistItem(ctx, item); err != nil {
+			t.Fatalf("add item: %v", err)
+		}
+	}
+	order := func() []product.ID {
+		t.Helper()
+		items, _, err := s.GetWishlistItems(ctx, wishlist.ID, wishlistPkg.OrderByPosition, 10, 0)
+		if err != nil {
+			t.Fatalf("get items: %v", err)
+		}
+		ids := make([]product.ID, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID.ProductID)
+		}
+		return ids
+	}
+
+	if err := s.ReorderItems(ctx, wishlist.ID, []product.ID{"a", "b", "c", "d", "e"}); err != nil {
+		t.Fatalf("reorder all: %v", err)
+	}
+	// the second page of two items
+	if err := s.ReorderItems(ctx, wishlist.ID, []product.ID{"d", "c"}); err != nil {
+		t.Fatalf("reorder page: %v", err)
+	}
+	if got, want := fmt.Sprint(order()), fmt.Sprint([]product.ID{"a", "b", "d", "c", "e"}); got != want {
+		t.Fatalf("order = %s, want %s", got, want)
+	}
+
+	// the first page while the rest were never reordered
+	if err := s.AddWishlistItem(ctx, &wishlistPkg.Item{ID: wishlistPkg.ItemID{WishlistID: wishlist.ID, ProductID: "f"}}); err != nil {
+		t.Fatalf("add item: %v", err)
+	}
+	if err := s.ReorderItems(ctx, wishlist.ID, []product.ID{"a", "f"}); err != nil {
+		t.Fatalf("reorder new item: %v", err)
+	}
+	if got, want := fmt.Sprint(order()), fmt.Sprint([]product.ID{"a", "f", "b", "d", "c", "e"}); got != want {
+		t.Fatalf("order = %s, want %s", got, want)
+	}
+}
@@ -2,41 +2,117 @@ package inmemory
 
 import (
 	"context"
+	"github.com/grulex/go-wishlist/pkg/product"
 	"github.com/grulex/go-wishlist/pkg/user"
 	"github.com/grulex/go-wishlist/pkg/wishlist"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 )
 
+// productStorage is needed to order the items by price
+type productStorage interface {
+	Get(ctx context.Context, id product.ID) (*product.Product, error)
+}
+
 type Storage struct {
-	Wishlists    map[wishlist.ID]*wishlist.Wishlist
-	Items        map[wishlist.ID][]*wishlist.Item
-	WishlistLock *sync.RWMutex
-	ItemsLock    *sync.RWMutex
+	Wishlists         map[wishlist.ID]*wishlist.Wishlist
+	Items             map[wishlist.ID][]*wishlist.Item
+	Invites           map[wishlist.ID]map[user.ID]*wishlist.Invite
+	Contributions     map[wishlist.ItemID]map[user.ID]*wishlist.Contribution
+	Bookings          map[wishlist.ItemID]map[user.ID]*wishlist.Booking
+	WishlistLock      *sync.RWMutex
+	ItemsLock         *sync.RWMutex
+	InvitesLock       *sync.RWMutex
+	ContributionsLock *sync.RWMutex
+	BookingsLock      *sync.RWMutex
+	productStorage    productStorage
 }
 
-func NewWishlistInMemory() *Storage {
+func NewWishlistInMemory(productStorage productStorage) *Storage {
 	return &Storage{
-		Wishlists:    map[wishlist.ID]*wishlist.Wishlist{},
-		Items:        map[wishlist.ID][]*wishlist.Item{},
-		WishlistLock: &sync.RWMutex{},
-		ItemsLock:    &sync.RWMutex{},
+		Wishlists:         map[wishlist.ID]*wishlist.Wishlist{},
+		Items:             map[wishlist.ID][]*wishlist.Item{},
+		Invites:           map[wishlist.ID]map[user.ID]*wishlist.Invite{},
+		Contributions:     map[wishlist.ItemID]map[user.ID]*wishlist.Contribution{},
+		Bookings:          map[wishlist.ItemID]map[user.ID]*wishlist.Booking{},
+		WishlistLock:      &sync.RWMutex{},
+		ItemsLock:         &sync.RWMutex{},
+		InvitesLock:       &sync.RWMutex{},
+		ContributionsLock: &sync.RWMutex{},
+		BookingsLock:      &sync.RWMutex{},
+		productStorage:    productStorage,
 	}
 }
 
+// InTransaction runs the change as is, the in-memory storage has no transactions
+func (s *Storage) InTransaction(ctx context.Context, change func(ctx context.Context) error) error {
+	return change(ctx)
+}
+
 func (s *Storage) Upsert(_ context.Context, w *wishlist.Wishlist) error {
 	s.WishlistLock.Lock()
-	s.Wishlists[w.ID] = w
+	stored := *w
+	s.Wishlists[w.ID] = &stored
 	s.WishlistLock.Unlock()
 
 	return nil
 }
 
+func (s *Storage) SetDefault(_ context.Context, userID user.ID, id wishlist.ID) error {
+	s.WishlistLock.Lock()
+	for wishlistID, w := range s.Wishlists {
+		if w.UserID != userID || w.IsDefault == (w.ID == id) {
+			continue
+		}
+		// the stored values are never changed in place, the readers may hold copies made of them
+		updated := *w
+		updated.IsDefault = w.ID == id
+		s.Wishlists[wishlistID] = &updated
+	}
+	s.WishlistLock.Unlock()
+	return nil
+}
+
+func (s *Storage) Delete(_ context.Context, id wishlist.ID) error {
+	s.WishlistLock.Lock()
+	delete(s.Wishlists, id)
+	s.WishlistLock.Unlock()
+
+	s.ItemsLock.Lock()
+	delete(s.Items, id)
+	s.ItemsLock.Unlock()
+
+	s.InvitesLock.Lock()
+	delete(s.Invites, id)
+	s.InvitesLock.Unlock()
+	s.ContributionsLock.Lock()
+	for itemID := range s.Contributions {
+		if itemID.WishlistID == id {
+			delete(s.Contributions, itemID)
+		}
+	}
+	s.ContributionsLock.Unlock()
+	s.BookingsLock.Lock()
+	for itemID := range s.Bookings {
+		if itemID.WishlistID == id {
+			delete(s.Bookings, itemID)
+		}
+	}
+	s.BookingsLock.Unlock()
+	return nil
+}
+
 func (s *Storage) Get(_ context.Context, id wishlist.ID) (*wishlist.Wishlist, error) {
+	s.WishlistLock.RLock()
+	defer s.WishlistLock.RUnlock()
 	w, ok := s.Wishlists[id]
 	if !ok {
 		return nil, wishlist.ErrNotFound
 	}
-	return w, nil
+	wishlistCopy := *w
+	return &wishlistCopy, nil
 }
 
 func (s *Storage) GetByUserID(_ context.Context, userID user.ID) ([]*wishlist.Wishlist, error) {
@@ -44,18 +120,64 @@ func (s *Storage) GetByUserID(_ context.Context, userID user.ID) ([]*wishlist.Wi
 	var wishlists []*wishlist.Wishlist
 	for _, w := range s.Wishlists {
 		if w.UserID == userID {
-			wishlists = append(wishlists, w)
+			wishlistCopy := *w
+			wishlists = append(wishlists, &wishlistCopy)
 		}
 	}
 	s.WishlistLock.RUnlock()
 	return wishlists, nil
 }
 
-func (s *Storage) GetWishlistItems(_ context.Context, wishlistID wishlist.ID, limit, offset uint) (items []*wishlist.Item, haveMore bool, err error) {
+func (s *Storage) GetWithOccasion(_ context.Context) ([]*wishlist.Wishlist, error) {
+	s.WishlistLock.RLock()
+	wishlists := make([]*wishlist.Wishlist, 0)
+	for _, w := range s.Wishlists {
+		if w.OccasionDate != nil && !w.IsArchived {
+			wishlistCopy := *w
+			wishlists = append(wishlists, &wishlistCopy)
+		}
+	}
+	s.WishlistLock.RUnlock()
+	return wishlists, nil
+}
+
+func (s *Storage) SetOccasionReminded(_ context.Context, id wishlist.ID, occasion time.Time) error {
+	s.WishlistLock.Lock()
+	defer s.WishlistLock.Unlock()
+	w, ok := s.Wishlists[id]
+	if !ok {
+		return wishlist.ErrNotFound
+	}
+	updated := *w
+	updated.OccasionRemindedFor = &occasion
+	s.Wishlists[id] = &updated
+	return nil
+}
+
+func (s *Storage) GetWishlistItems(
+	ctx context.Context,
+	wishlistID wishlist.ID,
+	order wishlist.ItemsOrder,
+	limit, offset uint,
+) (items []*wishlist.Item, haveMore bool, err error) {
+	if !order.IsValid() {
+		return nil, false, wishlist.ErrInvalidItemsOrder
+	}
+
 	s.ItemsLock.RLock()
-	defer s.ItemsLock.RUnlock()
+	items = make([]*wishlist.Item, 0, len(s.Items[wishlistID]))
+	for _, item := range s.Items[wishlistID] {
+		if !item.IsFulfilled() {
+			itemCopy := *item
+			items = append(items, &itemCopy)
+		}
+	}
+	s.ItemsLock.RUnlock()
 
-	items = s.Items[wishlistID]
+	err = s.sortItems(ctx, items, order)
+	if err != nil {
+		return nil, false, err
+	}
 	if offset > uint(len(items)) {
 		return nil, false, nil
 	}
@@ -65,13 +187,97 @@ func (s *Storage) GetWishlistItems(_ context.Context, wishlistID wishlist.ID, li
 	return items[offset : offset+limit], true, nil
 }
 
+func (s *Storage) sortItems(ctx context.Context, items []*wishlist.Item, order wishlist.ItemsOrder) error {
+	newestFirst := func(a, b *wishlist.Item) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	byPosition := func(a, b *wishlist.Item) bool {
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return newestFirst(a, b)
+	}
+
+	var less func(a, b *wishlist.Item) bool
+	switch order {
+	case wishlist.OrderByPosition:
+		less = byPosition
+	case wishlist.OrderByPriority:
+		less = func(a, b *wishlist.Item) bool {
+			if a.Priority != b.Priority {
+				return a.Priority == wishlist.PriorityMustHave
+			}
+			return byPosition(a, b)
+		}
+	case wishlist.OrderByPriceAsc, wishlist.OrderByPriceDesc:
+		prices := make(map[product.ID]float64, len(items))
+		for _, item := range items {
+			p, err := s.productStorage.Get(ctx, item.ID.ProductID)
+			if err != nil {
+				return err
+			}
+			if p.Price == nil {
+				continue
+			}
+			price, err := strconv.ParseFloat(p.Price.Number(), 64)
+			if err != nil {
+				return err
+			}
+			prices[item.ID.ProductID] = price
+		}
+		less = func(a, b *wishlist.Item) bool {
+			priceA, hasPriceA := prices[a.ID.ProductID]
+			priceB, hasPriceB := prices[b.ID.ProductID]
+			if hasPriceA != hasPriceB {
+				return hasPriceA
+			}
+			if priceA != priceB {
+				return (priceA < priceB) == (order == wishlist.OrderByPriceAsc)
+			}
+			return newestFirst(a, b)
+		}
+	case wishlist.OrderByNewest:
+		less = newestFirst
+	case wishlist.OrderByOldest:
+		less = func(a, b *wishlist.Item) bool {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return less(items[i], items[j])
+	})
+	return nil
+}
+
+func (s *Storage) GetFulfilledItems(_ context.Context, wishlistID wishlist.ID) ([]*wishlist.Item, error) {
+	s.ItemsLock.RLock()
+	items := make([]*wishlist.Item, 0)
+	for _, item := range s.Items[wishlistID] {
+		if item.IsFulfilled() {
+			itemCopy := *item
+			items = append(items, &itemCopy)
+		}
+	}
+	s.ItemsLock.RUnlock()
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].FulfilledAt.After(*items[j].FulfilledAt)
+	})
+	return items, nil
+}
+
 func (s *Storage) UpsertWishlistItem(_ context.Context, item *wishlist.Item) error {
 	s.ItemsLock.Lock()
-	if _, ok := s.Items[item.ID.WishlistID]; !ok {
-		s.Items[item.ID.WishlistID] = []*wishlist.Item{}
+	defer s.ItemsLock.Unlock()
+	itemCopy := *item
+	for i, existing := range s.Items[item.ID.WishlistID] {
+		if existing.ID.ProductID == item.ID.ProductID {
+			itemCopy.Version = existing.Version + 1
+			s.Items[item.ID.WishlistID][i] = &itemCopy
+			return nil
+		}
 	}
-	s.Items[item.ID.WishlistID] = append(s.Items[item.ID.WishlistID], item)
-	s.ItemsLock.Unlock()
+	s.Items[item.ID.WishlistID] = append(s.Items[item.ID.WishlistID], &itemCopy)
 	return nil
 }
 
@@ -88,6 +294,12 @@ func (s *Storage) DeleteWishlistItem(_ context.Context, itemID wishlist.ItemID)
 	}
 	s.Items[itemID.WishlistID] = newItems
 	s.ItemsLock.Unlock()
+	s.ContributionsLock.Lock()
+	delete(s.Contributions, itemID)
+	s.ContributionsLock.Unlock()
+	s.BookingsLock.Lock()
+	delete(s.Bookings, itemID)
+	s.BookingsLock.Unlock()
 	return nil
 }
 
@@ -96,8 +308,307 @@ func (s *Storage) GetWishlistItemByID(_ context.Context, itemID wishlist.ItemID)
 	defer s.ItemsLock.RUnlock()
 	for _, i := range s.Items[itemID.WishlistID] {
 		if i.ID.ProductID == itemID.ProductID {
-			return i, nil
+			itemCopy := *i
+			return &itemCopy, nil
 		}
 	}
 	return nil, wishlist.ErrItemNotFound
 }
+
+// UpdateWishlistItem saves the item only if nobody changed it since it was read
+func (s *Storage) UpdateWishlistItem(_ context.Context, item *wishlist.Item) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	for i, existing := range s.Items[item.ID.WishlistID] {
+		if existing.ID.ProductID == item.ID.ProductID {
+			if existing.Version != item.Version {
+				return wishlist.ErrConcurrentUpdate
+			}
+			item.Version++
+			itemCopy := *item
+			s.Items[item.ID.WishlistID][i] = &itemCopy
+			return nil
+		}
+	}
+	return wishlist.ErrConcurrentUpdate
+}
+
+// SetItemPositions renumbers the whole active list from 1, see wishlist.Reorder
+func (s *Storage) SetItemPositions(ctx context.Context, wishlistID wishlist.ID, productIDs []product.ID) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	active := make([]*wishlist.Item, 0, len(s.Items[wishlistID]))
+	for _, item := range s.Items[wishlistID] {
+		if !item.IsFulfilled() {
+			active = append(active, item)
+		}
+	}
+	if err := s.sortItems(ctx, active, wishlist.OrderByPosition); err != nil {
+		return err
+	}
+	current := make([]product.ID, 0, len(active))
+	for _, item := range active {
+		current = append(current, item.ID.ProductID)
+	}
+	reordered, err := wishlist.Reorder(current, productIDs)
+	if err != nil {
+		return err
+	}
+
+	positions := make(map[product.ID]uint, len(reordered))
+	for i, productID := range reordered {
+		positions[productID] = uint(i + 1)
+	}
+	now := time.Now().UTC()
+	for idx, i := range s.Items[wishlistID] {
+		position, ok := positions[i.ID.ProductID]
+		if !ok || i.Position == position {
+			continue
+		}
+		updated := *i
+		updated.Position = position
+		updated.Version++
+		updated.UpdatedAt = now
+		s.Items[wishlistID][idx] = &updated
+	}
+	return nil
+}
+
+// bumpItemVersion increases the version of the item if it still matches the expected one, ItemsLock must be held
+func (s *Storage) bumpItemVersion(itemID wishlist.ItemID, version uint) error {
+	for idx, i := range s.Items[itemID.WishlistID] {
+		if i.ID.ProductID == itemID.ProductID {
+			if i.Version != version {
+				return wishlist.ErrConcurrentUpdate
+			}
+			// stored items are never changed in place, readers may still hold them
+			updated := *i
+			updated.Version++
+			s.Items[itemID.WishlistID][idx] = &updated
+			return nil
+		}
+	}
+	return wishlist.ErrConcurrentUpdate
+}
+
+func (s *Storage) GetItemsByProductID(_ context.Context, productID product.ID) ([]*wishlist.Item, error) {
+	s.ItemsLock.RLock()
+	defer s.ItemsLock.RUnlock()
+	var items []*wishlist.Item
+	for _, wishlistItems := range s.Items {
+		for _, i := range wishlistItems {
+			if i.ID.ProductID == productID {
+				itemCopy := *i
+				items = append(items, &itemCopy)
+			}
+		}
+	}
+	return items, nil
+}
+
+func (s *Storage) AddInvite(_ context.Context, invite *wishlist.Invite) error {
+	s.InvitesLock.Lock()
+	defer s.InvitesLock.Unlock()
+	if _, ok := s.Invites[invite.WishlistID]; !ok {
+		s.Invites[invite.WishlistID] = map[user.ID]*wishlist.Invite{}
+	}
+	if _, ok := s.Invites[invite.WishlistID][invite.UserID]; !ok {
+		s.Invites[invite.WishlistID][invite.UserID] = invite
+	}
+	return nil
+}
+
+func (s *Storage) IsInvited(_ context.Context, wishlistID wishlist.ID, userID user.ID) (bool, error) {
+	s.InvitesLock.RLock()
+	defer s.InvitesLock.RUnlock()
+	_, ok := s.Invites[wishlistID][userID]
+	return ok, nil
+}
+
+func (s *Storage) GetInvites(_ context.Context, wishlistID wishlist.ID) ([]*wishlist.Invite, error) {
+	s.InvitesLock.RLock()
+	defer s.InvitesLock.RUnlock()
+	invites := make([]*wishlist.Invite, 0, len(s.Invites[wishlistID]))
+	for _, invite := range s.Invites[wishlistID] {
+		invites = append(invites, invite)
+	}
+	return invites, nil
+}
+
+func (s *Storage) DeleteInvite(_ context.Context, wishlistID wishlist.ID, userID user.ID) error {
+	s.InvitesLock.Lock()
+	delete(s.Invites[wishlistID], userID)
+	s.InvitesLock.Unlock()
+	return nil
+}
+
+func (s *Storage) DeleteInvites(_ context.Context, wishlistID wishlist.ID) error {
+	s.InvitesLock.Lock()
+	delete(s.Invites, wishlistID)
+	s.InvitesLock.Unlock()
+	return nil
+}
+
+func (s *Storage) UpsertContribution(_ context.Context, contribution *wishlist.Contribution, itemVersion uint) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	if err := s.bumpItemVersion(contribution.ItemID, itemVersion); err != nil {
+		return err
+	}
+	s.ContributionsLock.Lock()
+	defer s.ContributionsLock.Unlock()
+	if _, ok := s.Contributions[contribution.ItemID]; !ok {
+		s.Contributions[contribution.ItemID] = map[user.ID]*wishlist.Contribution{}
+	}
+	s.Contributions[contribution.ItemID][contribution.UserID] = contribution
+	return nil
+}
+
+func (s *Storage) GetContributions(_ context.Context, itemID wishlist.ItemID) ([]*wishlist.Contribution, error) {
+	s.ContributionsLock.RLock()
+	contributions := make([]*wishlist.Contribution, 0, len(s.Contributions[itemID]))
+	for _, contribution := range s.Contributions[itemID] {
+		contributions = append(contributions, contribution)
+	}
+	s.ContributionsLock.RUnlock()
+	sortContributions(contributions)
+	return contributions, nil
+}
+
+func (s *Storage) GetContributionsByWishlist(_ context.Context, wishlistID wishlist.ID) ([]*wishlist.Contribution, error) {
+	s.ContributionsLock.RLock()
+	contributions := make([]*wishlist.Contribution, 0)
+	for itemID, itemContributions := range s.Contributions {
+		if itemID.WishlistID != wishlistID {
+			continue
+		}
+		for _, contribution := range itemContributions {
+			contributions = append(contributions, contribution)
+		}
+	}
+	s.ContributionsLock.RUnlock()
+	sortContributions(contributions)
+	return contributions, nil
+}
+
+func sortContributions(contributions []*wishlist.Contribution) {
+	sort.Slice(contributions, func(i, j int) bool {
+		return contributions[i].CreatedAt.Before(contributions[j].CreatedAt)
+	})
+}
+
+func (s *Storage) DeleteContribution(_ context.Context, itemID wishlist.ItemID, userID user.ID, itemVersion uint) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	if err := s.bumpItemVersion(itemID, itemVersion); err != nil {
+		return err
+	}
+	s.ContributionsLock.Lock()
+	delete(s.Contributions[itemID], userID)
+	s.ContributionsLock.Unlock()
+	return nil
+}
+
+func (s *Storage) UpsertBooking(_ context.Context, booking *wishlist.Booking, itemVersion uint) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	if err := s.bumpItemVersion(booking.ItemID, itemVersion); err != nil {
+		return err
+	}
+	s.BookingsLock.Lock()
+	defer s.BookingsLock.Unlock()
+	if _, ok := s.Bookings[booking.ItemID]; !ok {
+		s.Bookings[booking.ItemID] = map[user.ID]*wishlist.Booking{}
+	}
+	s.Bookings[booking.ItemID][booking.UserID] = booking
+	return nil
+}
+
+func (s *Storage) GetBookings(_ context.Context, itemID wishlist.ItemID) ([]*wishlist.Booking, error) {
+	s.BookingsLock.RLock()
+	bookings := make([]*wishlist.Booking, 0, len(s.Bookings[itemID]))
+	for _, booking := range s.Bookings[itemID] {
+		bookings = append(bookings, booking)
+	}
+	s.BookingsLock.RUnlock()
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (s *Storage) GetBookingsByWishlist(_ context.Context, wishlistID wishlist.ID) ([]*wishlist.Booking, error) {
+	s.BookingsLock.RLock()
+	bookings := make([]*wishlist.Booking, 0)
+	for itemID, itemBookings := range s.Bookings {
+		if itemID.WishlistID != wishlistID {
+			continue
+		}
+		for _, booking := range itemBookings {
+			bookings = append(bookings, booking)
+		}
+	}
+	s.BookingsLock.RUnlock()
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (s *Storage) GetBookingsExpiringBefore(_ context.Context, before time.Time) ([]*wishlist.Booking, error) {
+	s.BookingsLock.RLock()
+	bookings := make([]*wishlist.Booking, 0)
+	for _, itemBookings := range s.Bookings {
+		for _, booking := range itemBookings {
+			if booking.ExpiresAt != nil && !booking.ExpiresAt.After(before) {
+				bookingCopy := *booking
+				bookings = append(bookings, &bookingCopy)
+			}
+		}
+	}
+	s.BookingsLock.RUnlock()
+
+	s.ItemsLock.RLock()
+	active := make([]*wishlist.Booking, 0, len(bookings))
+	for _, booking := range bookings {
+		for _, item := range s.Items[booking.ItemID.WishlistID] {
+			if item.ID.ProductID == booking.ItemID.ProductID && !item.IsFulfilled() {
+				active = append(active, booking)
+				break
+			}
+		}
+	}
+	s.ItemsLock.RUnlock()
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].ExpiresAt.Before(*active[j].ExpiresAt)
+	})
+	return active, nil
+}
+
+func (s *Storage) SetBookingReminderSent(_ context.Context, itemID wishlist.ItemID, userID user.ID) error {
+	s.BookingsLock.Lock()
+	defer s.BookingsLock.Unlock()
+	booking, ok := s.Bookings[itemID][userID]
+	if !ok {
+		return nil
+	}
+	updated := *booking
+	updated.IsReminderSent = true
+	s.Bookings[itemID][userID] = &updated
+	return nil
+}
+
+func sortBookings(bookings []*wishlist.Booking) {
+	sort.Slice(bookings, func(i, j int) bool {
+		return bookings[i].CreatedAt.Before(bookings[j].CreatedAt)
+	})
+}
+
+func (s *Storage) DeleteBooking(_ context.Context, itemID wishlist.ItemID, userID user.ID, itemVersion uint) error {
+	s.ItemsLock.Lock()
+	defer s.ItemsLock.Unlock()
+	if err := s.bumpItemVersion(itemID, itemVersion); err != nil {
+		return err
+	}
+	s.BookingsLock.Lock()
+	delete(s.Bookings[itemID], userID)
+	s.BookingsLock.Unlock()
+	return nil
+}
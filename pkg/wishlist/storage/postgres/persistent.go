@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"github.com/bojanz/currency"
 	imagePkg "github.com/grulex/go-wishlist/pkg/image"
 	productPkg "github.com/grulex/go-wishlist/pkg/product"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
@@ -9,15 +10,57 @@ import (
 )
 
 type wishlistPersistent struct {
-	ID          string    `db:"id"`
-	UserID      string    `db:"user_id"`
-	IsDefault   bool      `db:"is_default"`
-	Title       string    `db:"title"`
-	ImageId     *string   `db:"image_id"`
-	Description string    `db:"description"`
-	IsArchived  bool      `db:"is_archived"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID                  string     `db:"id"`
+	UserID              string     `db:"user_id"`
+	IsDefault           bool       `db:"is_default"`
+	Title               string     `db:"title"`
+	ImageId             *string    `db:"image_id"`
+	Description         string     `db:"description"`
+	IsArchived          bool       `db:"is_archived"`
+	Visibility          string     `db:"visibility"`
+	ShareToken          string     `db:"share_token"`
+	IsSurpriseMode      bool       `db:"is_surprise_mode"`
+	OccasionDate        *time.Time `db:"occasion_date"`
+	IsOccasionYearly    bool       `db:"is_occasion_yearly"`
+	OccasionRemindedFor *time.Time `db:"occasion_reminded_for"`
+	CreatedAt           time.Time  `db:"created_at"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+}
+
+type invitePersistent struct {
+	WishlistID string    `db:"wishlist_id"`
+	UserID     string    `db:"user_id"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type contributionPersistent struct {
+	WishlistID string          `db:"wishlist_id"`
+	ProductID  string          `db:"product_id"`
+	UserID     string          `db:"user_id"`
+	Amount     currency.Amount `db:"amount"`
+	CreatedAt  time.Time       `db:"created_at"`
+	UpdatedAt  time.Time       `db:"updated_at"`
+}
+
+func (c contributionPersistent) ToContribution() *wishlistPkg.Contribution {
+	return &wishlistPkg.Contribution{
+		ItemID:    wishlistPkg.ItemID{WishlistID: wishlistPkg.ID(c.WishlistID), ProductID: productPkg.ID(c.ProductID)},
+		UserID:    userPkg.ID(c.UserID),
+		Amount:    c.Amount,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+func (c contributionPersistent) FromContribution(contribution *wishlistPkg.Contribution) *contributionPersistent {
+	return &contributionPersistent{
+		WishlistID: string(contribution.ItemID.WishlistID),
+		ProductID:  string(contribution.ItemID.ProductID),
+		UserID:     string(contribution.UserID),
+		Amount:     contribution.Amount,
+		CreatedAt:  contribution.CreatedAt,
+		UpdatedAt:  contribution.UpdatedAt,
+	}
 }
 
 func (w wishlistPersistent) ToWishlist() *wishlistPkg.Wishlist {
@@ -27,15 +70,21 @@ func (w wishlistPersistent) ToWishlist() *wishlistPkg.Wishlist {
 		avatar = &avatarID
 	}
 	return &wishlistPkg.Wishlist{
-		ID:          wishlistPkg.ID(w.ID),
-		UserID:      userPkg.ID(w.UserID),
-		IsDefault:   w.IsDefault,
-		Title:       w.Title,
-		Avatar:      avatar,
-		Description: w.Description,
-		IsArchived:  w.IsArchived,
-		CreatedAt:   w.CreatedAt,
-		UpdatedAt:   w.UpdatedAt,
+		ID:                  wishlistPkg.ID(w.ID),
+		UserID:              userPkg.ID(w.UserID),
+		IsDefault:           w.IsDefault,
+		Title:               w.Title,
+		Avatar:              avatar,
+		Description:         w.Description,
+		IsArchived:          w.IsArchived,
+		Visibility:          wishlistPkg.Visibility(w.Visibility),
+		ShareToken:          w.ShareToken,
+		IsSurpriseMode:      w.IsSurpriseMode,
+		OccasionDate:        w.OccasionDate,
+		IsOccasionYearly:    w.IsOccasionYearly,
+		OccasionRemindedFor: w.OccasionRemindedFor,
+		CreatedAt:           w.CreatedAt,
+		UpdatedAt:           w.UpdatedAt,
 	}
 }
 
@@ -46,54 +95,98 @@ func (w wishlistPersistent) FromWishlist(wishlist *wishlistPkg.Wishlist) *wishli
 		avatar = &stringAvatar
 	}
 	return &wishlistPersistent{
-		ID:          string(wishlist.ID),
-		UserID:      string(wishlist.UserID),
-		IsDefault:   wishlist.IsDefault,
-		Title:       wishlist.Title,
-		ImageId:     avatar,
-		Description: wishlist.Description,
-		IsArchived:  wishlist.IsArchived,
-		CreatedAt:   wishlist.CreatedAt,
-		UpdatedAt:   wishlist.UpdatedAt,
+		ID:                  string(wishlist.ID),
+		UserID:              string(wishlist.UserID),
+		IsDefault:           wishlist.IsDefault,
+		Title:               wishlist.Title,
+		ImageId:             avatar,
+		Description:         wishlist.Description,
+		IsArchived:          wishlist.IsArchived,
+		Visibility:          string(wishlist.Visibility),
+		ShareToken:          wishlist.ShareToken,
+		IsSurpriseMode:      wishlist.IsSurpriseMode,
+		OccasionDate:        wishlist.OccasionDate,
+		IsOccasionYearly:    wishlist.IsOccasionYearly,
+		OccasionRemindedFor: wishlist.OccasionRemindedFor,
+		CreatedAt:           wishlist.CreatedAt,
+		UpdatedAt:           wishlist.UpdatedAt,
 	}
 }
 
 type itemPersistent struct {
-	WishlistID         string    `db:"wishlist_id"`
-	ProductID          string    `db:"product_id"`
-	IsBookingAvailable bool      `db:"is_booking_available"`
-	IsBookedBy         *string   `db:"is_booked_by"`
-	CreatedAt          time.Time `db:"created_at"`
-	UpdatedAt          time.Time `db:"updated_at"`
+	WishlistID         string     `db:"wishlist_id"`
+	ProductID          string     `db:"product_id"`
+	IsBookingAvailable bool       `db:"is_booking_available"`
+	Quantity           uint       `db:"quantity"`
+	Priority           string     `db:"priority"`
+	Position           uint       `db:"position"`
+	Version            uint       `db:"version"`
+	FulfilledAt        *time.Time `db:"fulfilled_at"`
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at"`
 }
 
 func (i itemPersistent) ToItem() *wishlistPkg.Item {
-	var isBookedBy *userPkg.ID
-	if i.IsBookedBy != nil {
-		userID := userPkg.ID(*i.IsBookedBy)
-		isBookedBy = &userID
-	}
 	return &wishlistPkg.Item{
 		ID:                 wishlistPkg.ItemID{WishlistID: wishlistPkg.ID(i.WishlistID), ProductID: productPkg.ID(i.ProductID)},
 		IsBookingAvailable: i.IsBookingAvailable,
-		IsBookedBy:         isBookedBy,
+		Quantity:           i.Quantity,
+		Priority:           wishlistPkg.Priority(i.Priority),
+		Position:           i.Position,
+		Version:            i.Version,
+		FulfilledAt:        i.FulfilledAt,
 		CreatedAt:          i.CreatedAt,
 		UpdatedAt:          i.UpdatedAt,
 	}
 }
 
 func (i itemPersistent) FromItem(item *wishlistPkg.Item) *itemPersistent {
-	var isBookedBy *string
-	if item.IsBookedBy != nil {
-		userIDString := string(*item.IsBookedBy)
-		isBookedBy = &userIDString
-	}
 	return &itemPersistent{
 		WishlistID:         string(item.ID.WishlistID),
 		ProductID:          string(item.ID.ProductID),
 		IsBookingAvailable: item.IsBookingAvailable,
-		IsBookedBy:         isBookedBy,
+		Quantity:           item.Quantity,
+		Priority:           string(item.Priority),
+		Position:           item.Position,
+		Version:            item.Version,
+		FulfilledAt:        item.FulfilledAt,
 		CreatedAt:          item.CreatedAt,
 		UpdatedAt:          item.UpdatedAt,
 	}
 }
+
+type bookingPersistent struct {
+	WishlistID     string     `db:"wishlist_id"`
+	ProductID      string     `db:"product_id"`
+	UserID         string     `db:"user_id"`
+	Quantity       uint       `db:"quantity"`
+	ExpiresAt      *time.Time `db:"expires_at"`
+	IsReminderSent bool       `db:"is_reminder_sent"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+func (b bookingPersistent) ToBooking() *wishlistPkg.Booking {
+	return &wishlistPkg.Booking{
+		ItemID:         wishlistPkg.ItemID{WishlistID: wishlistPkg.ID(b.WishlistID), ProductID: productPkg.ID(b.ProductID)},
+		UserID:         userPkg.ID(b.UserID),
+		Quantity:       b.Quantity,
+		ExpiresAt:      b.ExpiresAt,
+		IsReminderSent: b.IsReminderSent,
+		CreatedAt:      b.CreatedAt,
+		UpdatedAt:      b.UpdatedAt,
+	}
+}
+
+func (b bookingPersistent) FromBooking(booking *wishlistPkg.Booking) *bookingPersistent {
+	return &bookingPersistent{
+		WishlistID:     string(booking.ItemID.WishlistID),
+		ProductID:      string(booking.ItemID.ProductID),
+		UserID:         string(booking.UserID),
+		Quantity:       booking.Quantity,
+		ExpiresAt:      booking.ExpiresAt,
+		IsReminderSent: booking.IsReminderSent,
+		CreatedAt:      booking.CreatedAt,
+		UpdatedAt:      booking.UpdatedAt,
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/grulex/go-wishlist/db"
+	"github.com/grulex/go-wishlist/pkg/product"
 	userPkg "github.com/grulex/go-wishlist/pkg/user"
 	wishlistPkg "github.com/grulex/go-wishlist/pkg/wishlist"
 	"github.com/jmoiron/sqlx"
+	"time"
 )
 
 type Storage struct {
@@ -17,6 +20,18 @@ func NewImageStorage(db *sqlx.DB) *Storage {
 	return &Storage{db: db}
 }
 
+// InTransaction runs the change in one transaction with the events published by it
+func (s *Storage) InTransaction(ctx context.Context, change func(ctx context.Context) error) error {
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, _ *sqlx.Tx) error {
+		return change(ctx)
+	})
+}
+
+func (s *Storage) executor(ctx context.Context) sqlx.ExtContext {
+	return db.Executor(ctx, s.db)
+}
+
+// Upsert doesn't change the default flag of an existing wishlist, SetDefault does it
 func (s *Storage) Upsert(ctx context.Context, w *wishlistPkg.Wishlist) error {
 	query := `INSERT INTO wishlist (
 		id,
@@ -26,6 +41,12 @@ func (s *Storage) Upsert(ctx context.Context, w *wishlistPkg.Wishlist) error {
 		image_id,
 		description,
 		is_archived,
+		visibility,
+		share_token,
+		is_surprise_mode,
+		occasion_date,
+		is_occasion_yearly,
+		occasion_reminded_for,
 		created_at,
 		updated_at
 	) VALUES (
@@ -36,24 +57,65 @@ func (s *Storage) Upsert(ctx context.Context, w *wishlistPkg.Wishlist) error {
 		:image_id,
 		:description,
 		:is_archived,
+		:visibility,
+		:share_token,
+		:is_surprise_mode,
+		:occasion_date,
+		:is_occasion_yearly,
+		:occasion_reminded_for,
 		:created_at,
 		:updated_at
 	) ON CONFLICT (id) DO UPDATE SET
-		is_default = :is_default,
 		title = :title,
 		image_id = :image_id,
 		description = :description,
 		is_archived = :is_archived,
+		visibility = :visibility,
+		share_token = :share_token,
+		is_surprise_mode = :is_surprise_mode,
+		occasion_date = :occasion_date,
+		is_occasion_yearly = :is_occasion_yearly,
+		occasion_reminded_for = :occasion_reminded_for,
 		updated_at = :updated_at`
 	wishlistPersistent := wishlistPersistent{}.FromWishlist(w)
-	_, err := s.db.NamedExecContext(ctx, query, wishlistPersistent)
+	_, err := sqlx.NamedExecContext(ctx, s.executor(ctx), query, wishlistPersistent)
+	return err
+}
+
+// SetDefault switches the default wishlist of the user in a single statement
+func (s *Storage) SetDefault(ctx context.Context, userID userPkg.ID, id wishlistPkg.ID) error {
+	query := `UPDATE wishlist SET is_default = (id = $2) WHERE user_id = $1`
+	_, err := s.executor(ctx).ExecContext(ctx, query, userID, id)
 	return err
 }
 
+func (s *Storage) Delete(ctx context.Context, id wishlistPkg.ID) error {
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `DELETE FROM wishlist_item WHERE wishlist_id = $1`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM wishlist_item_booking WHERE wishlist_id = $1`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM wishlist_item_contribution WHERE wishlist_id = $1`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM wishlist_invite WHERE wishlist_id = $1`, id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM wishlist WHERE id = $1`, id)
+		return err
+	})
+}
+
 func (s *Storage) Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wishlist, error) {
 	query := `SELECT * FROM wishlist WHERE id = $1`
 	w := &wishlistPersistent{}
-	err := s.db.GetContext(ctx, w, query, id)
+	err := sqlx.GetContext(ctx, s.executor(ctx), w, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, wishlistPkg.ErrNotFound
@@ -67,7 +129,7 @@ func (s *Storage) Get(ctx context.Context, id wishlistPkg.ID) (*wishlistPkg.Wish
 func (s *Storage) GetByUserID(ctx context.Context, userID userPkg.ID) ([]*wishlistPkg.Wishlist, error) {
 	query := `SELECT * FROM wishlist WHERE user_id = $1`
 	wishlistsPersistent := make([]*wishlistPersistent, 0)
-	err := s.db.SelectContext(ctx, &wishlistsPersistent, query, userID)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &wishlistsPersistent, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -79,10 +141,54 @@ func (s *Storage) GetByUserID(ctx context.Context, userID userPkg.ID) ([]*wishli
 	return wishlists, nil
 }
 
-func (s *Storage) GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.ID, limit, offset uint) (items []*wishlistPkg.Item, haveMore bool, err error) {
+// GetWithOccasion returns the active wishlists which have an occasion date
+func (s *Storage) GetWithOccasion(ctx context.Context) ([]*wishlistPkg.Wishlist, error) {
+	query := `SELECT * FROM wishlist WHERE occasion_date IS NOT NULL AND is_archived = false`
+	wishlistsPersistent := make([]*wishlistPersistent, 0)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &wishlistsPersistent, query)
+	if err != nil {
+		return nil, err
+	}
+	wishlists := make([]*wishlistPkg.Wishlist, 0, len(wishlistsPersistent))
+	for _, w := range wishlistsPersistent {
+		wishlists = append(wishlists, w.ToWishlist())
+	}
+	return wishlists, nil
+}
+
+// SetOccasionReminded doesn't touch the other fields, so it can't overwrite changes made by the owner meanwhile
+func (s *Storage) SetOccasionReminded(ctx context.Context, id wishlistPkg.ID, occasion time.Time) error {
+	query := `UPDATE wishlist SET occasion_reminded_for = $1 WHERE id = $2`
+	_, err := s.executor(ctx).ExecContext(ctx, query, occasion, id)
+	return err
+}
+
+// itemsOrderClauses are the ORDER BY clauses of the items query, the product id makes the pagination stable
+var itemsOrderClauses = map[wishlistPkg.ItemsOrder]string{
+	wishlistPkg.OrderByPosition:  `i.position, i.created_at DESC, i.product_id`,
+	wishlistPkg.OrderByPriority:  `CASE i.priority WHEN 'must_have' THEN 0 ELSE 1 END, i.position, i.created_at DESC, i.product_id`,
+	wishlistPkg.OrderByPriceAsc:  `(p.price).number ASC NULLS LAST, i.created_at DESC, i.product_id`,
+	wishlistPkg.OrderByPriceDesc: `(p.price).number DESC NULLS LAST, i.created_at DESC, i.product_id`,
+	wishlistPkg.OrderByNewest:    `i.created_at DESC, i.product_id`,
+	wishlistPkg.OrderByOldest:    `i.created_at, i.product_id`,
+}
+
+func (s *Storage) GetWishlistItems(
+	ctx context.Context,
+	wishlistID wishlistPkg.ID,
+	order wishlistPkg.ItemsOrder,
+	limit, offset uint,
+) (items []*wishlistPkg.Item, haveMore bool, err error) {
+	orderClause, ok := itemsOrderClauses[order]
+	if !ok {
+		return nil, false, wishlistPkg.ErrInvalidItemsOrder
+	}
 	itemsPersistent := make([]*itemPersistent, 0)
-	query := `SELECT * FROM wishlist_item WHERE wishlist_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
-	err = s.db.SelectContext(ctx, &itemsPersistent, query, wishlistID, limit+1, offset)
+	query := `SELECT i.* FROM wishlist_item i
+		LEFT JOIN product p ON p.id = i.product_id
+		WHERE i.wishlist_id = $1 AND i.fulfilled_at IS NULL
+		ORDER BY ` + orderClause + ` LIMIT $2 OFFSET $3`
+	err = sqlx.SelectContext(ctx, s.executor(ctx), &itemsPersistent, query, wishlistID, limit+1, offset)
 	if err != nil {
 		return nil, false, err
 	}
@@ -99,41 +205,168 @@ func (s *Storage) GetWishlistItems(ctx context.Context, wishlistID wishlistPkg.I
 	return items, hasMore, nil
 }
 
+func (s *Storage) GetFulfilledItems(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Item, error) {
+	itemsPersistent := make([]*itemPersistent, 0)
+	query := `SELECT * FROM wishlist_item WHERE wishlist_id = $1 AND fulfilled_at IS NOT NULL ORDER BY fulfilled_at DESC`
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &itemsPersistent, query, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*wishlistPkg.Item, 0, len(itemsPersistent))
+	for _, p := range itemsPersistent {
+		items = append(items, p.ToItem())
+	}
+	return items, nil
+}
+
+func (s *Storage) GetItemsByProductID(ctx context.Context, productID product.ID) ([]*wishlistPkg.Item, error) {
+	itemsPersistent := make([]*itemPersistent, 0)
+	query := `SELECT * FROM wishlist_item WHERE product_id = $1`
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &itemsPersistent, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*wishlistPkg.Item, 0, len(itemsPersistent))
+	for _, p := range itemsPersistent {
+		items = append(items, p.ToItem())
+	}
+	return items, nil
+}
+
 func (s *Storage) UpsertWishlistItem(ctx context.Context, item *wishlistPkg.Item) error {
 	query := `INSERT INTO wishlist_item (
 		wishlist_id,
 		product_id,
 		is_booking_available,
-		is_booked_by,
+		quantity,
+		priority,
+		position,
+		fulfilled_at,
 		created_at,
 		updated_at
 	) VALUES (
 		:wishlist_id,
 		:product_id,
 		:is_booking_available,
-		:is_booked_by,
+		:quantity,
+		:priority,
+		:position,
+		:fulfilled_at,
 		:created_at,
 		:updated_at
 	) ON CONFLICT (wishlist_id, product_id) DO UPDATE SET
 		is_booking_available = :is_booking_available,
-		is_booked_by = :is_booked_by,
+		quantity = :quantity,
+		priority = :priority,
+		position = :position,
+		fulfilled_at = :fulfilled_at,
+		version = wishlist_item.version + 1,
 		updated_at = :updated_at`
 
 	itemPersistent := itemPersistent{}.FromItem(item)
-	_, err := s.db.NamedExecContext(ctx, query, itemPersistent)
+	_, err := sqlx.NamedExecContext(ctx, s.executor(ctx), query, itemPersistent)
 	return err
 }
 
+// UpdateWishlistItem saves the item only if nobody changed it since it was read
+func (s *Storage) UpdateWishlistItem(ctx context.Context, item *wishlistPkg.Item) error {
+	query := `UPDATE wishlist_item SET
+		is_booking_available = :is_booking_available,
+		quantity = :quantity,
+		priority = :priority,
+		position = :position,
+		fulfilled_at = :fulfilled_at,
+		version = version + 1,
+		updated_at = :updated_at
+	WHERE wishlist_id = :wishlist_id AND product_id = :product_id AND version = :version`
+
+	result, err := sqlx.NamedExecContext(ctx, s.executor(ctx), query, itemPersistent{}.FromItem(item))
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return wishlistPkg.ErrConcurrentUpdate
+	}
+	item.Version++
+	return nil
+}
+
+// SetItemPositions renumbers the whole active list from 1 in one transaction, see wishlistPkg.Reorder
+func (s *Storage) SetItemPositions(ctx context.Context, wishlistID wishlistPkg.ID, productIDs []product.ID) error {
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var current []product.ID
+		query := `SELECT i.product_id FROM wishlist_item i
+			WHERE i.wishlist_id = $1 AND i.fulfilled_at IS NULL
+			ORDER BY ` + itemsOrderClauses[wishlistPkg.OrderByPosition] + ` FOR UPDATE`
+		err := tx.SelectContext(ctx, &current, query, wishlistID)
+		if err != nil {
+			return err
+		}
+		reordered, err := wishlistPkg.Reorder(current, productIDs)
+		if err != nil {
+			return err
+		}
+
+		query = `UPDATE wishlist_item SET position = $1, version = version + 1, updated_at = $2
+			WHERE wishlist_id = $3 AND product_id = $4 AND position <> $1`
+		now := time.Now().UTC()
+		for i, productID := range reordered {
+			_, err = tx.ExecContext(ctx, query, i+1, now, wishlistID, productID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// withItemVersion runs the change in a transaction that bumps the item version, the transaction from the context
+// is joined if there is one. The change is rejected if the version isn't the expected one anymore.
+func (s *Storage) withItemVersion(ctx context.Context, itemID wishlistPkg.ItemID, version uint, change func(tx *sqlx.Tx) error) error {
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		query := `UPDATE wishlist_item SET version = version + 1 WHERE wishlist_id = $1 AND product_id = $2 AND version = $3`
+		result, err := tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID, version)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return wishlistPkg.ErrConcurrentUpdate
+		}
+
+		return change(tx)
+	})
+}
+
 func (s *Storage) DeleteWishlistItem(ctx context.Context, itemID wishlistPkg.ItemID) error {
-	query := `DELETE FROM wishlist_item WHERE wishlist_id = $1 AND product_id = $2`
-	_, err := s.db.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID)
-	return err
+	return db.InTransaction(ctx, s.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		query := `DELETE FROM wishlist_item_booking WHERE wishlist_id = $1 AND product_id = $2`
+		_, err := tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID)
+		if err != nil {
+			return err
+		}
+		query = `DELETE FROM wishlist_item_contribution WHERE wishlist_id = $1 AND product_id = $2`
+		_, err = tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID)
+		if err != nil {
+			return err
+		}
+		query = `DELETE FROM wishlist_item WHERE wishlist_id = $1 AND product_id = $2`
+		_, err = tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID)
+		return err
+	})
 }
 
 func (s *Storage) GetWishlistItemByID(ctx context.Context, itemID wishlistPkg.ItemID) (*wishlistPkg.Item, error) {
 	query := `SELECT * FROM wishlist_item WHERE wishlist_id = $1 AND product_id = $2`
 	itemPersistent := &itemPersistent{}
-	err := s.db.GetContext(ctx, itemPersistent, query, itemID.WishlistID, itemID.ProductID)
+	err := sqlx.GetContext(ctx, s.executor(ctx), itemPersistent, query, itemID.WishlistID, itemID.ProductID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, wishlistPkg.ErrItemNotFound
@@ -143,3 +376,189 @@ func (s *Storage) GetWishlistItemByID(ctx context.Context, itemID wishlistPkg.It
 
 	return itemPersistent.ToItem(), nil
 }
+
+func (s *Storage) AddInvite(ctx context.Context, invite *wishlistPkg.Invite) error {
+	query := `INSERT INTO wishlist_invite (
+		wishlist_id,
+		user_id,
+		created_at
+	) VALUES (
+		:wishlist_id,
+		:user_id,
+		:created_at
+	) ON CONFLICT (wishlist_id, user_id) DO NOTHING`
+	_, err := sqlx.NamedExecContext(ctx, s.executor(ctx), query, invitePersistent{
+		WishlistID: string(invite.WishlistID),
+		UserID:     string(invite.UserID),
+		CreatedAt:  invite.CreatedAt,
+	})
+	return err
+}
+
+func (s *Storage) IsInvited(ctx context.Context, wishlistID wishlistPkg.ID, userID userPkg.ID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM wishlist_invite WHERE wishlist_id = $1 AND user_id = $2)`
+	var exists bool
+	err := sqlx.GetContext(ctx, s.executor(ctx), &exists, query, wishlistID, userID)
+	return exists, err
+}
+
+func (s *Storage) GetInvites(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Invite, error) {
+	query := `SELECT * FROM wishlist_invite WHERE wishlist_id = $1 ORDER BY created_at`
+	invitesPersistent := make([]*invitePersistent, 0)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &invitesPersistent, query, wishlistID)
+	if err != nil {
+		return nil, err
+	}
+	invites := make([]*wishlistPkg.Invite, 0, len(invitesPersistent))
+	for _, p := range invitesPersistent {
+		invites = append(invites, &wishlistPkg.Invite{
+			WishlistID: wishlistPkg.ID(p.WishlistID),
+			UserID:     userPkg.ID(p.UserID),
+			CreatedAt:  p.CreatedAt,
+		})
+	}
+	return invites, nil
+}
+
+func (s *Storage) DeleteInvite(ctx context.Context, wishlistID wishlistPkg.ID, userID userPkg.ID) error {
+	query := `DELETE FROM wishlist_invite WHERE wishlist_id = $1 AND user_id = $2`
+	_, err := s.executor(ctx).ExecContext(ctx, query, wishlistID, userID)
+	return err
+}
+
+func (s *Storage) DeleteInvites(ctx context.Context, wishlistID wishlistPkg.ID) error {
+	query := `DELETE FROM wishlist_invite WHERE wishlist_id = $1`
+	_, err := s.executor(ctx).ExecContext(ctx, query, wishlistID)
+	return err
+}
+
+func (s *Storage) UpsertContribution(ctx context.Context, contribution *wishlistPkg.Contribution, itemVersion uint) error {
+	query := `INSERT INTO wishlist_item_contribution (
+		wishlist_id,
+		product_id,
+		user_id,
+		amount,
+		created_at,
+		updated_at
+	) VALUES (
+		:wishlist_id,
+		:product_id,
+		:user_id,
+		:amount,
+		:created_at,
+		:updated_at
+	) ON CONFLICT (wishlist_id, product_id, user_id) DO UPDATE SET
+		amount = :amount,
+		updated_at = :updated_at`
+	return s.withItemVersion(ctx, contribution.ItemID, itemVersion, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, query, contributionPersistent{}.FromContribution(contribution))
+		return err
+	})
+}
+
+func (s *Storage) GetContributions(ctx context.Context, itemID wishlistPkg.ItemID) ([]*wishlistPkg.Contribution, error) {
+	query := `SELECT * FROM wishlist_item_contribution WHERE wishlist_id = $1 AND product_id = $2 ORDER BY created_at`
+	return s.selectContributions(ctx, query, itemID.WishlistID, itemID.ProductID)
+}
+
+func (s *Storage) GetContributionsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Contribution, error) {
+	query := `SELECT * FROM wishlist_item_contribution WHERE wishlist_id = $1 ORDER BY created_at`
+	return s.selectContributions(ctx, query, wishlistID)
+}
+
+func (s *Storage) selectContributions(ctx context.Context, query string, args ...any) ([]*wishlistPkg.Contribution, error) {
+	contributionsPersistent := make([]*contributionPersistent, 0)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &contributionsPersistent, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	contributions := make([]*wishlistPkg.Contribution, 0, len(contributionsPersistent))
+	for _, c := range contributionsPersistent {
+		contributions = append(contributions, c.ToContribution())
+	}
+	return contributions, nil
+}
+
+func (s *Storage) DeleteContribution(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, itemVersion uint) error {
+	query := `DELETE FROM wishlist_item_contribution WHERE wishlist_id = $1 AND product_id = $2 AND user_id = $3`
+	return s.withItemVersion(ctx, itemID, itemVersion, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID, userID)
+		return err
+	})
+}
+
+func (s *Storage) UpsertBooking(ctx context.Context, booking *wishlistPkg.Booking, itemVersion uint) error {
+	query := `INSERT INTO wishlist_item_booking (
+		wishlist_id,
+		product_id,
+		user_id,
+		quantity,
+		expires_at,
+		is_reminder_sent,
+		created_at,
+		updated_at
+	) VALUES (
+		:wishlist_id,
+		:product_id,
+		:user_id,
+		:quantity,
+		:expires_at,
+		:is_reminder_sent,
+		:created_at,
+		:updated_at
+	) ON CONFLICT (wishlist_id, product_id, user_id) DO UPDATE SET
+		quantity = :quantity,
+		expires_at = :expires_at,
+		is_reminder_sent = :is_reminder_sent,
+		updated_at = :updated_at`
+	return s.withItemVersion(ctx, booking.ItemID, itemVersion, func(tx *sqlx.Tx) error {
+		_, err := tx.NamedExecContext(ctx, query, bookingPersistent{}.FromBooking(booking))
+		return err
+	})
+}
+
+func (s *Storage) GetBookings(ctx context.Context, itemID wishlistPkg.ItemID) ([]*wishlistPkg.Booking, error) {
+	query := `SELECT * FROM wishlist_item_booking WHERE wishlist_id = $1 AND product_id = $2 ORDER BY created_at`
+	return s.selectBookings(ctx, query, itemID.WishlistID, itemID.ProductID)
+}
+
+func (s *Storage) GetBookingsByWishlist(ctx context.Context, wishlistID wishlistPkg.ID) ([]*wishlistPkg.Booking, error) {
+	query := `SELECT * FROM wishlist_item_booking WHERE wishlist_id = $1 ORDER BY created_at`
+	return s.selectBookings(ctx, query, wishlistID)
+}
+
+func (s *Storage) GetBookingsExpiringBefore(ctx context.Context, before time.Time) ([]*wishlistPkg.Booking, error) {
+	query := `SELECT b.* FROM wishlist_item_booking b
+		JOIN wishlist_item i ON i.wishlist_id = b.wishlist_id AND i.product_id = b.product_id
+		WHERE b.expires_at <= $1 AND i.fulfilled_at IS NULL
+		ORDER BY b.expires_at`
+	return s.selectBookings(ctx, query, before)
+}
+
+// SetBookingReminderSent marks the booking without changing the item version, the reminder doesn't affect availability
+func (s *Storage) SetBookingReminderSent(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID) error {
+	query := `UPDATE wishlist_item_booking SET is_reminder_sent = true WHERE wishlist_id = $1 AND product_id = $2 AND user_id = $3`
+	_, err := s.executor(ctx).ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID, userID)
+	return err
+}
+
+func (s *Storage) selectBookings(ctx context.Context, query string, args ...any) ([]*wishlistPkg.Booking, error) {
+	bookingsPersistent := make([]*bookingPersistent, 0)
+	err := sqlx.SelectContext(ctx, s.executor(ctx), &bookingsPersistent, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	bookings := make([]*wishlistPkg.Booking, 0, len(bookingsPersistent))
+	for _, b := range bookingsPersistent {
+		bookings = append(bookings, b.ToBooking())
+	}
+	return bookings, nil
+}
+
+func (s *Storage) DeleteBooking(ctx context.Context, itemID wishlistPkg.ItemID, userID userPkg.ID, itemVersion uint) error {
+	query := `DELETE FROM wishlist_item_booking WHERE wishlist_id = $1 AND product_id = $2 AND user_id = $3`
+	return s.withItemVersion(ctx, itemID, itemVersion, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, query, itemID.WishlistID, itemID.ProductID, userID)
+		return err
+	})
+}
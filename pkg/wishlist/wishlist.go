@@ -1,13 +1,89 @@
 package wishlist
 
 import (
+	"github.com/bojanz/currency"
 	"github.com/grulex/go-wishlist/pkg/image"
 	"github.com/grulex/go-wishlist/pkg/product"
 	"github.com/grulex/go-wishlist/pkg/user"
+	"strconv"
 	"time"
 )
 
 type ID string
+type Visibility string
+
+const (
+	VisibilityPublic Visibility = "public"
+	// VisibilityLink makes the wishlist readable with the share token and for the invited users
+	VisibilityLink Visibility = "link"
+	// VisibilityPrivate makes the wishlist readable for the users invited by the owner only
+	VisibilityPrivate Visibility = "private"
+)
+
+func (v Visibility) IsValid() bool {
+	return v == VisibilityPublic || v == VisibilityLink || v == VisibilityPrivate
+}
+
+type Priority string
+
+const (
+	PriorityMustHave   Priority = "must_have"
+	PriorityNiceToHave Priority = "nice_to_have"
+)
+
+func (p Priority) IsValid() bool {
+	return p == PriorityMustHave || p == PriorityNiceToHave
+}
+
+// ItemsOrder is the sort order of the active wishlist items
+type ItemsOrder string
+
+const (
+	// OrderByPosition follows the owner's manual order. Items added after the last reorder have position 0
+	// and go first, the newest first.
+	OrderByPosition ItemsOrder = "position"
+	// OrderByPriority puts must-have items first, keeping the manual order inside a priority
+	OrderByPriority ItemsOrder = "priority"
+	// OrderByPriceAsc and OrderByPriceDesc compare the price numbers only, items without a price go last
+	OrderByPriceAsc  ItemsOrder = "price_asc"
+	OrderByPriceDesc ItemsOrder = "price_desc"
+	OrderByNewest    ItemsOrder = "newest"
+	OrderByOldest    ItemsOrder = "oldest"
+)
+
+func (o ItemsOrder) IsValid() bool {
+	switch o {
+	case OrderByPosition, OrderByPriority, OrderByPriceAsc, OrderByPriceDesc, OrderByNewest, OrderByOldest:
+		return true
+	}
+	return false
+}
+
+// Reorder moves the given items into the places they take in the current order, in the given sequence.
+// The moved items may be a part of the list, e.g. one page, the other items keep their places.
+func Reorder(current []product.ID, moved []product.ID) ([]product.ID, error) {
+	isMoved := make(map[product.ID]bool, len(moved))
+	for _, productID := range moved {
+		isMoved[productID] = true
+	}
+	reordered := make([]product.ID, len(current))
+	next := 0
+	for i, productID := range current {
+		if !isMoved[productID] {
+			reordered[i] = productID
+			continue
+		}
+		if next == len(moved) {
+			return nil, ErrInvalidReorder
+		}
+		reordered[i] = moved[next]
+		next++
+	}
+	if next != len(moved) {
+		return nil, ErrInvalidReorder
+	}
+	return reordered, nil
+}
 
 type Wishlist struct {
 	ID          ID
@@ -17,8 +93,69 @@ type Wishlist struct {
 	Avatar      *image.ID
 	Description string
 	IsArchived  bool
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	Visibility  Visibility
+	ShareToken  string
+	// IsSurpriseMode hides the booking state of the items from the owner
+	IsSurpriseMode bool
+	// OccasionDate is the day of the event the wishlist is made for, in UTC
+	OccasionDate *time.Time
+	// IsOccasionYearly repeats the occasion every year, e.g. a birthday
+	IsOccasionYearly bool
+	// OccasionRemindedFor is the occurrence the subscribers were last reminded about
+	OccasionRemindedFor *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// NextOccasion returns the nearest occurrence of the occasion on or after the day of the given time.
+// It is nil if there is no occasion or a one-time occasion has passed.
+func (w Wishlist) NextOccasion(at time.Time) *time.Time {
+	if w.OccasionDate == nil {
+		return nil
+	}
+	today := TruncateToDay(at)
+	occasion := TruncateToDay(*w.OccasionDate)
+	if w.IsOccasionYearly {
+		occasion = anniversary(*w.OccasionDate, today.Year())
+		if occasion.Before(today) {
+			occasion = anniversary(*w.OccasionDate, today.Year()+1)
+		}
+	}
+	if occasion.Before(today) {
+		return nil
+	}
+	return &occasion
+}
+
+// DaysUntil returns the number of whole days from the day of the given time to the date
+func DaysUntil(at time.Time, date time.Time) int {
+	return int(TruncateToDay(date).Sub(TruncateToDay(at)).Hours() / 24)
+}
+
+// TruncateToDay returns the midnight of the day in UTC
+func TruncateToDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func anniversary(date time.Time, year int) time.Time {
+	day := date.Day()
+	// February 29 is celebrated on February 28 in common years
+	if date.Month() == time.February && day == 29 && time.Date(year, time.March, 0, 0, 0, 0, 0, time.UTC).Day() != 29 {
+		day = 28
+	}
+	return time.Date(year, date.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// IsBookingHiddenFor reports whether the user must not see the bookings and contributions of the items
+func (w Wishlist) IsBookingHiddenFor(userID *user.ID) bool {
+	return w.IsSurpriseMode && userID != nil && *userID == w.UserID
+}
+
+type Invite struct {
+	WishlistID ID
+	UserID     user.ID
+	CreatedAt  time.Time
 }
 
 type Wishlists []*Wishlist
@@ -35,12 +172,152 @@ func (w Wishlists) GetDefault() *Wishlist {
 type Item struct {
 	ID                 ItemID
 	IsBookingAvailable bool
-	IsBookedBy         *user.ID
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	// Quantity is the number of desired units, every booking reserves some of them
+	Quantity uint
+	Priority Priority
+	// Position is the place in the owner's manual order, starting from 1
+	Position uint
+	// Version increases with every change of the item, its bookings and contributions
+	Version uint
+	// FulfilledAt is set when the owner has received the item, it leaves the active list then
+	FulfilledAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (i Item) IsFulfilled() bool {
+	return i.FulfilledAt != nil
+}
+
+// Remaining returns the number of units not reserved by the bookings
+func (i Item) Remaining(bookings Bookings) uint {
+	booked := bookings.Booked()
+	if booked >= i.Quantity {
+		return 0
+	}
+	return i.Quantity - booked
+}
+
+// Gift is a fulfilled item. The givers are the users who booked it or contributed to it,
+// their bookings and contributions are kept unchanged after the fulfilment.
+type Gift struct {
+	Item   *Item
+	Givers []user.ID
+}
+
+// GetGivers returns the bookers and the contributors of the item without duplicates
+func GetGivers(bookings Bookings, contributions Contributions) []user.ID {
+	givers := make([]user.ID, 0, len(bookings)+len(contributions))
+	seen := make(map[user.ID]bool, cap(givers))
+	for _, booking := range bookings {
+		if !seen[booking.UserID] {
+			seen[booking.UserID] = true
+			givers = append(givers, booking.UserID)
+		}
+	}
+	for _, contribution := range contributions {
+		if !seen[contribution.UserID] {
+			seen[contribution.UserID] = true
+			givers = append(givers, contribution.UserID)
+		}
+	}
+	return givers
+}
+
+type Booking struct {
+	ItemID   ItemID
+	UserID   user.ID
+	Quantity uint
+	// ExpiresAt is optional, the booking is released automatically after it
+	ExpiresAt      *time.Time
+	IsReminderSent bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (b Booking) IsExpiredAt(at time.Time) bool {
+	return b.ExpiresAt != nil && !b.ExpiresAt.After(at)
+}
+
+type Bookings []*Booking
+
+// Booked returns the number of units reserved by all bookings
+func (b Bookings) Booked() uint {
+	var booked uint
+	for _, booking := range b {
+		booked += booking.Quantity
+	}
+	return booked
+}
+
+func (b Bookings) GetByUser(userID user.ID) *Booking {
+	for _, booking := range b {
+		if booking.UserID == userID {
+			return booking
+		}
+	}
+	return nil
 }
 
 type ItemID struct {
 	WishlistID ID         `json:"wishlist_id"`
 	ProductID  product.ID `json:"product_id"`
 }
+
+// Contribution is a pledge of a participant toward the item's price, in the currency of the product
+type Contribution struct {
+	ItemID    ItemID
+	UserID    user.ID
+	Amount    currency.Amount
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Contributions []*Contribution
+
+// Total sums the pledged amounts, the result is zero in the given currency if there are no contributions
+func (c Contributions) Total(currencyCode string) (currency.Amount, error) {
+	total, err := currency.NewAmount("0", currencyCode)
+	if err != nil {
+		return currency.Amount{}, err
+	}
+	for _, contribution := range c {
+		total, err = total.Add(contribution.Amount)
+		if err != nil {
+			return currency.Amount{}, err
+		}
+	}
+	return total, nil
+}
+
+// Progress returns the covered share of the price in whole percents. It may exceed 100.
+func (c Contributions) Progress(price currency.Amount) (uint, error) {
+	if !price.IsPositive() {
+		return 0, ErrItemHasNoPrice
+	}
+	total, err := c.Total(price.CurrencyCode())
+	if err != nil {
+		return 0, err
+	}
+	percent, err := total.Mul("100")
+	if err != nil {
+		return 0, err
+	}
+	percent, err = percent.Div(price.Number())
+	if err != nil {
+		return 0, err
+	}
+	progress, err := strconv.ParseUint(percent.RoundTo(0, currency.RoundDown).Number(), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return uint(progress), nil
+}
+
+func (c Contributions) IsFullyCovered(price currency.Amount) (bool, error) {
+	progress, err := c.Progress(price)
+	if err != nil {
+		return false, err
+	}
+	return progress >= 100, nil
+}
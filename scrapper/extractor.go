@@ -0,0 +1,154 @@
+package scrapper
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// Extractor handles the pages of a specific site, which the generic parser can't read well
+type Extractor interface {
+	// Prepare may rewrite the url of the request and set its headers before the page is fetched
+	Prepare(req *http.Request)
+	// Extract returns the fields found on the page, the empty ones are taken from the generic parser
+	Extract(pageUrl string, root *html.Node) DocumentPreview
+}
+
+type registeredExtractor struct {
+	domain    string
+	extractor Extractor
+}
+
+// Registry keeps the extractors by domain, the first registered match wins
+type Registry struct {
+	extractors []registeredExtractor
+	mu         *sync.RWMutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{mu: &sync.RWMutex{}}
+}
+
+// DefaultRegistry is used by Scrape and Parse, it contains the extractors of the major marketplaces
+var DefaultRegistry = newDefaultRegistry()
+
+// Register adds the extractor for the domain and its subdomains, so "amazon.co.uk" matches
+// "www.amazon.co.uk" but neither "amazon.co.uk.example.com" nor "myamazon.co.uk"
+func (r *Registry) Register(domain string, extractor Extractor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.extractors = append(r.extractors, registeredExtractor{
+		domain:    strings.TrimPrefix(cleanStr(domain), "."),
+		extractor: extractor,
+	})
+}
+
+func (r *Registry) Find(host string) (Extractor, bool) {
+	host = cleanStr(host)
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.HasSuffix(host, "]") {
+		host = host[:i]
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	host = strings.TrimSuffix(host, ".")
+	for _, registered := range r.extractors {
+		if host == registered.domain || strings.HasSuffix(host, "."+registered.domain) {
+			return registered.extractor, true
+		}
+	}
+	return nil, false
+}
+
+// mergePreview sets the non-empty fields found by the extractor over the generic ones
+func mergePreview(preview *DocumentPreview, found DocumentPreview) {
+	if found.Title != "" {
+		preview.Title = found.Title
+	}
+	if found.Description != "" {
+		preview.Description = found.Description
+	}
+	if len(found.Images) != 0 {
+		preview.Images = found.Images
+	}
+	if found.Link != "" {
+		preview.Link = found.Link
+	}
+	if found.Price != "" {
+		preview.Price = found.Price
+	}
+	if found.Currency != "" {
+		preview.Currency = found.Currency
+	}
+	if found.Brand != "" {
+		preview.Brand = found.Brand
+	}
+	if found.Availability != "" {
+		preview.Availability = found.Availability
+	}
+	if found.SKU != "" {
+		preview.SKU = found.SKU
+	}
+}
+
+func findNode(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if n.Type == html.ElementNode && match(n) {
+		return n
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if found := findNode(child, match); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func byID(id string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return getAttr(n, "id") == id
+	}
+}
+
+func byClass(class string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		for _, c := range strings.Fields(getAttr(n, "class")) {
+			if c == class {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if cleanStr(attr.Key) == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// nodeText returns the text of the node and its children with the whitespaces collapsed
+func nodeText(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+			b.WriteString(" ")
+		}
+		if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,103 @@
+package scrapper
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		fixture  string
+		title    string
+		image    string
+		price    string
+		currency string
+		sku      string
+	}{
+		{
+			name:     "amazon",
+			url:      "https://www.amazon.de/Wasserkocher-Edelstahl/dp/B08KETTLE1",
+			fixture:  "amazon.html",
+			title:    "Wasserkocher Edelstahl 1,7 L",
+			image:    "https://m.media-amazon.com/images/I/71kettle._AC_SL1500_.jpg",
+			price:    "1249.99",
+			currency: "EUR",
+			sku:      "B08KETTLE1",
+		},
+		{
+			name:     "ebay",
+			url:      "https://www.ebay.com/itm/1234567890",
+			fixture:  "ebay.html",
+			title:    "Vintage Film Camera 35mm with Lens",
+			image:    "https://i.ebayimg.com/images/g/abc/s-l1600.jpg",
+			price:    "149.50",
+			currency: "USD",
+			sku:      "1234567890",
+		},
+		{
+			name:     "generic",
+			url:      "https://pottery.example/teapot",
+			fixture:  "generic.html",
+			title:    "Ceramic Teapot",
+			image:    "https://pottery.example/media/teapot.jpg",
+			price:    "2490.00",
+			currency: "RUB",
+			sku:      "TP-800",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = f.Close()
+			}()
+
+			doc, err := Parse(tt.url, f)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			preview := doc.Preview
+			if preview.Title != tt.title {
+				t.Errorf("title = %q, want %q", preview.Title, tt.title)
+			}
+			if len(preview.Images) == 0 || preview.Images[0] != tt.image {
+				t.Errorf("images = %q, want %q first", preview.Images, tt.image)
+			}
+			if preview.Price != tt.price || preview.Currency != tt.currency {
+				t.Errorf("price = %s %s, want %s %s", preview.Price, preview.Currency, tt.price, tt.currency)
+			}
+			if preview.SKU != tt.sku {
+				t.Errorf("sku = %q, want %q", preview.SKU, tt.sku)
+			}
+		})
+	}
+}
+
+func TestRegistryFind(t *testing.T) {
+	tests := []struct {
+		host  string
+		found bool
+	}{
+		{"amazon.com", true},
+		{"www.amazon.co.uk", true},
+		{"smile.amazon.de:443", true},
+		{"amazon.evil.example", false},
+		{"amazon.co.uk.evil.example", false},
+		{"myamazon.com", false},
+		{"www.ebay.de", true},
+		{"ebay.attacker.example", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		if _, found := DefaultRegistry.Find(tt.host); found != tt.found {
+			t.Errorf("Find(%q) found = %v, want %v", tt.host, found, tt.found)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package scrapper
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const browserAcceptLanguage = "en-US,en;q=0.9"
+
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for tld := range amazonCurrencies {
+		registry.Register("amazon."+tld, amazonExtractor{})
+	}
+	for _, tld := range ebayTlds {
+		registry.Register("ebay."+tld, ebayExtractor{})
+	}
+	return registry
+}
+
+var (
+	amazonAsinRegexp = regexp.MustCompile(`/(?:dp|gp/product|gp/aw/d)/([A-Z0-9]{10})`)
+	ebayItemRegexp   = regexp.MustCompile(`/itm/(?:[^/]+/)?(\d+)`)
+)
+
+var ebayTlds = []string{"com", "ca", "co.uk", "de", "fr", "it", "es", "nl", "be", "at", "ch", "ie", "pl", "com.au"}
+
+// amazonCurrencies maps the domain after "amazon." to the currency of its prices
+var amazonCurrencies = map[string]string{
+	"com":    "USD",
+	"ca":     "CAD",
+	"com.mx": "MXN",
+	"com.br": "BRL",
+	"co.uk":  "GBP",
+	"de":     "EUR",
+	"fr":     "EUR",
+	"it":     "EUR",
+	"es":     "EUR",
+	"nl":     "EUR",
+	"com.be": "EUR",
+	"ie":     "EUR",
+	"se":     "SEK",
+	"pl":     "PLN",
+	"com.tr": "TRY",
+	"ae":     "AED",
+	"sa":     "SAR",
+	"in":     "INR",
+	"co.jp":  "JPY",
+	"sg":     "SGD",
+	"com.au": "AUD",
+}
+
+// amazonExtractor reads the product block of Amazon, the OpenGraph tags there are usually missing
+// and the images found by the generic parser are the navigation sprites
+type amazonExtractor struct{}
+
+// Prepare drops the slug and the tracking parameters, the short /dp/ url is less likely to get a captcha
+func (amazonExtractor) Prepare(req *http.Request) {
+	req.Header.Set("Accept-Language", browserAcceptLanguage)
+	matches := amazonAsinRegexp.FindStringSubmatch(req.URL.Path)
+	if len(matches) > 1 {
+		req.URL.Path = "/dp/" + matches[1]
+		req.URL.RawPath = ""
+		req.URL.RawQuery = ""
+		req.URL.Fragment = ""
+	}
+}
+
+func (amazonExtractor) Extract(pageUrl string, root *html.Node) DocumentPreview {
+	preview := DocumentPreview{
+		Title: nodeText(findNode(root, byID("productTitle"))),
+	}
+
+	if image := findNode(root, byID("landingImage")); image != nil {
+		src := getAttr(image, "data-old-hires")
+		if src == "" {
+			src = getAttr(image, "src")
+		}
+		if src != "" {
+			preview.Images = []string{src}
+		}
+	}
+
+	priceBlock := findNode(root, byID("corePrice_feature_div"))
+	if priceBlock == nil {
+		priceBlock = findNode(root, byID("corePriceDisplay_desktop_feature_div"))
+	}
+	if priceBlock == nil {
+		priceBlock = root
+	}
+	if price := findNode(priceBlock, byClass("a-offscreen")); price != nil {
+		preview.Price = normalizePrice(nodeText(price))
+	}
+
+	if u, err := url.Parse(pageUrl); err == nil {
+		host := cleanStr(u.Hostname())
+		if i := strings.Index(host, "amazon."); i >= 0 && preview.Price != "" {
+			preview.Currency = amazonCurrencies[host[i+len("amazon."):]]
+		}
+		if matches := amazonAsinRegexp.FindStringSubmatch(u.Path); len(matches) > 1 {
+			preview.SKU = matches[1]
+		}
+	}
+	return preview
+}
+
+// ebayExtractor reads the item page of eBay, the generic description there is the seller's boilerplate
+type ebayExtractor struct{}
+
+func (ebayExtractor) Prepare(req *http.Request) {
+	req.Header.Set("Accept-Language", browserAcceptLanguage)
+	matches := ebayItemRegexp.FindStringSubmatch(req.URL.Path)
+	if len(matches) > 1 {
+		req.URL.Path = "/itm/" + matches[1]
+		req.URL.RawPath = ""
+		req.URL.RawQuery = ""
+		req.URL.Fragment = ""
+	}
+}
+
+func (ebayExtractor) Extract(pageUrl string, root *html.Node) DocumentPreview {
+	preview := DocumentPreview{
+		Title: nodeText(findNode(root, byClass("x-item-title__mainTitle"))),
+	}
+
+	if carousel := findNode(root, byClass("ux-image-carousel-item")); carousel != nil {
+		if image := findNode(carousel, func(n *html.Node) bool { return n.Data == "img" }); image != nil {
+			src := getAttr(image, "data-zoom-src")
+			if src == "" {
+				src = getAttr(image, "src")
+			}
+			if src != "" {
+				preview.Images = []string{src}
+			}
+		}
+	}
+
+	if price := findNode(root, byClass("x-price-primary")); price != nil {
+		text := nodeText(price)
+		preview.Price = normalizePrice(text)
+		preview.Currency = currencyFromPriceText(text)
+	}
+
+	if u, err := url.Parse(pageUrl); err == nil {
+		if matches := ebayItemRegexp.FindStringSubmatch(u.Path); len(matches) > 1 {
+			preview.SKU = matches[1]
+		}
+	}
+	return preview
+}
+
+// priceCurrencyPrefixes are checked in order, so the longer prefixes go first
+var priceCurrencyPrefixes = []struct {
+	prefix   string
+	currency string
+}{
+	{"US $", "USD"},
+	{"C $", "CAD"},
+	{"AU $", "AUD"},
+	{"EUR", "EUR"},
+	{"GBP", "GBP"},
+	{"€", "EUR"},
+	{"£", "GBP"},
+	{"$", "USD"},
+}
+
+func currencyFromPriceText(text string) string {
+	text = strings.TrimSpace(text)
+	for _, p := range priceCurrencyPrefixes {
+		if strings.HasPrefix(text, p.prefix) {
+			return p.currency
+		}
+	}
+	return ""
+}
@@ -2,13 +2,14 @@ package scrapper
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
 
+	"github.com/grulex/go-wishlist/fetcher"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/charset"
 )
@@ -16,34 +17,76 @@ import (
 var (
 	escapedFragment string = "_escaped_fragment_="
 	fragmentRegexp         = regexp.MustCompile("#!(.*)")
+	// DefaultFetcher downloads the pages for Scrape
+	DefaultFetcher = fetcher.NewFetcher(fetcher.PageConfig())
 )
 
 type scraper struct {
 	Url                *url.URL
 	EscapedFragmentUrl *url.URL
 	MaxRedirect        int
+	Registry           *Registry
+	Fetcher            *fetcher.Fetcher
+	ctx                context.Context
 }
 
 type Document struct {
 	Body    bytes.Buffer
-	Preview documentPreview
+	Preview DocumentPreview
 }
 
-type documentPreview struct {
+type DocumentPreview struct {
 	Icon        string
 	Name        string
 	Title       string
 	Description string
 	Images      []string
 	Link        string
+	// Price is a decimal number like "1299.90", Currency is an ISO 4217 code
+	Price        string
+	Currency     string
+	Brand        string
+	Availability string
+	SKU          string
 }
 
-func Scrape(uri string, maxRedirect int) (*Document, error) {
+// Scrape downloads the page and reads its preview, maxRedirect limits the refetches by the canonical
+// and the escaped fragment urls. The ctx cancels the requests.
+func Scrape(ctx context.Context, uri string, maxRedirect int) (*Document, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
 		return nil, err
 	}
-	return (&scraper{Url: u, MaxRedirect: maxRedirect}).Scrape()
+	return (&scraper{
+		Url:         u,
+		MaxRedirect: maxRedirect,
+		Registry:    DefaultRegistry,
+		Fetcher:     DefaultFetcher,
+		ctx:         ctx,
+	}).Scrape()
+}
+
+// Parse reads the already downloaded page without any requests, e.g. a saved one
+func Parse(uri string, body io.Reader) (*Document, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	scraper := &scraper{Url: u, Registry: DefaultRegistry}
+	doc := &Document{Preview: DocumentPreview{Link: u.String()}}
+	_, err = io.Copy(&doc.Body, body)
+	if err != nil {
+		return nil, err
+	}
+	err = scraper.parseDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+	err = scraper.extract(doc)
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
 }
 
 func (scraper *scraper) Scrape() (*Document, error) {
@@ -55,9 +98,30 @@ func (scraper *scraper) Scrape() (*Document, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = scraper.extract(doc)
+	if err != nil {
+		return nil, err
+	}
 	return doc, nil
 }
 
+// extract runs the site-specific extractor over the generic preview
+func (scraper *scraper) extract(doc *Document) error {
+	if scraper.Registry == nil {
+		return nil
+	}
+	extractor, ok := scraper.Registry.Find(scraper.Url.Host)
+	if !ok {
+		return nil
+	}
+	root, err := html.Parse(bytes.NewReader(doc.Body.Bytes()))
+	if err != nil {
+		return err
+	}
+	mergePreview(&doc.Preview, extractor.Extract(scraper.Url.String(), root))
+	return nil
+}
+
 func (scraper *scraper) getUrl() string {
 	if scraper.EscapedFragmentUrl != nil {
 		return scraper.EscapedFragmentUrl.String()
@@ -117,34 +181,34 @@ func (scraper *scraper) getDocument() (*Document, error) {
 		scraper.EscapedFragmentUrl = scraper.Url
 	}
 
-	req, err := http.NewRequest("GET", scraper.getUrl(), nil)
+	ctx := scraper.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := scraper.Fetcher.NewRequest(ctx, scraper.getUrl())
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.1.2 Safari/605.1.15")
+	if scraper.Registry != nil {
+		if extractor, ok := scraper.Registry.Find(req.URL.Host); ok {
+			extractor.Prepare(req)
+		}
+	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := scraper.Fetcher.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	if resp != nil {
-		defer func(Body io.ReadCloser) {
-			_ = Body.Close()
-		}(resp.Body)
-	}
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("status code %d", resp.StatusCode)
-	}
 
-	if resp.Request.URL.String() != scraper.getUrl() {
+	if resp.Url.String() != scraper.getUrl() {
 		scraper.EscapedFragmentUrl = nil
-		scraper.Url = resp.Request.URL
+		scraper.Url = resp.Url
 	}
-	b, err := convertUTF8(resp.Body, resp.Header.Get("content-type"))
+	b, err := convertUTF8(bytes.NewReader(resp.Body), resp.Header.Get("content-type"))
 	if err != nil {
 		return nil, err
 	}
-	doc := &Document{Body: b, Preview: documentPreview{Link: scraper.Url.String()}}
+	doc := &Document{Body: b, Preview: DocumentPreview{Link: scraper.Url.String()}}
 
 	return doc, nil
 }
@@ -163,12 +227,14 @@ func convertUTF8(content io.Reader, contentType string) (bytes.Buffer, error) {
 }
 
 func (scraper *scraper) parseDocument(doc *Document) error {
-	t := html.NewTokenizer(&doc.Body)
+	// the body is kept for the site-specific extractors
+	t := html.NewTokenizer(bytes.NewReader(doc.Body.Bytes()))
 	var ogImage bool
 	var headPassed bool
 	var hasFragment bool
 	var hasCanonical bool
 	var canonicalUrl *url.URL
+	var microdata microdataParser
 	doc.Preview.Images = []string{}
 	// saves previews' link in case that <link rel="canonical"> is found after <meta property="og:url">
 	link := doc.Preview.Link
@@ -186,6 +252,13 @@ func (scraper *scraper) parseDocument(doc *Document) error {
 		}
 		token := t.Token()
 
+		if tokenType != html.EndTagToken {
+			if data, ok := microdata.parse(t, token, tokenType); ok {
+				data.fill(&doc.Preview)
+				continue
+			}
+		}
+
 		switch token.Data {
 		case "head":
 			if tokenType == html.EndTagToken {
@@ -238,6 +311,10 @@ func (scraper *scraper) parseDocument(doc *Document) error {
 					content = attr.Val
 				}
 			}
+			if data, ok := parseMetaProduct(cleanStr(property), content); ok {
+				data.fill(&doc.Preview)
+				break
+			}
 			switch cleanStr(property) {
 			case "twitter:title":
 				doc.Preview.Title = content
@@ -287,6 +364,13 @@ func (scraper *scraper) parseDocument(doc *Document) error {
 				}
 			}
 
+		case "script":
+			if tokenType == html.StartTagToken && isJsonLdScript(token) && t.Next() == html.TextToken {
+				if data, ok := parseJsonLd(t.Token().Data); ok {
+					data.override(&doc.Preview)
+				}
+			}
+
 		case "img":
 			for _, attr := range token.Attr {
 				if cleanStr(attr.Key) == "src" {
@@ -332,7 +416,8 @@ func (scraper *scraper) parseDocument(doc *Document) error {
 			return scraper.parseDocument(doc)
 		}
 
-		if len(doc.Preview.Title) > 0 && len(doc.Preview.Description) > 0 && ogImage && headPassed {
+		// structured data is often placed in the body, so the price is awaited as well
+		if len(doc.Preview.Title) > 0 && len(doc.Preview.Description) > 0 && ogImage && headPassed && len(doc.Preview.Price) > 0 {
 			return nil
 		}
 
@@ -373,6 +458,15 @@ func metaFragment(token html.Token) bool {
 	return false
 }
 
+func isJsonLdScript(token html.Token) bool {
+	for _, attr := range token.Attr {
+		if cleanStr(attr.Key) == "type" && cleanStr(attr.Val) == "application/ld+json" {
+			return true
+		}
+	}
+	return false
+}
+
 func cleanStr(str string) string {
 	return strings.ToLower(strings.TrimSpace(str))
 }
@@ -0,0 +1,340 @@
+package scrapper
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// productData is the product information found in the structured data of the page:
+// JSON-LD blocks, schema.org microdata or OpenGraph product meta tags
+type productData struct {
+	Price        string
+	Currency     string
+	Brand        string
+	Availability string
+	SKU          string
+}
+
+// fill sets the empty fields of the preview, the values found earlier win
+func (p productData) fill(preview *DocumentPreview) {
+	if preview.Price == "" {
+		preview.Price = p.Price
+	}
+	if preview.Currency == "" {
+		preview.Currency = p.Currency
+	}
+	if preview.Brand == "" {
+		preview.Brand = p.Brand
+	}
+	if preview.Availability == "" {
+		preview.Availability = p.Availability
+	}
+	if preview.SKU == "" {
+		preview.SKU = p.SKU
+	}
+}
+
+// override sets the non-empty fields to the preview, used for JSON-LD as the most reliable source
+func (p productData) override(preview *DocumentPreview) {
+	if p.Price != "" {
+		preview.Price = p.Price
+	}
+	if p.Currency != "" {
+		preview.Currency = p.Currency
+	}
+	if p.Brand != "" {
+		preview.Brand = p.Brand
+	}
+	if p.Availability != "" {
+		preview.Availability = p.Availability
+	}
+	if p.SKU != "" {
+		preview.SKU = p.SKU
+	}
+}
+
+// parseMetaProduct handles the OpenGraph product meta tags, it reports whether the property is a product one
+func parseMetaProduct(property, content string) (productData, bool) {
+	switch property {
+	case "product:price:amount", "og:price:amount", "product:sale_price:amount":
+		return productData{Price: normalizePrice(content)}, true
+	case "product:price:currency", "og:price:currency", "product:sale_price:currency":
+		return productData{Currency: normalizeCurrency(content)}, true
+	case "product:brand", "og:brand":
+		return productData{Brand: strings.TrimSpace(content)}, true
+	case "product:availability", "og:availability":
+		return productData{Availability: normalizeAvailability(content)}, true
+	case "product:retailer_item_id", "product:sku":
+		return productData{SKU: strings.TrimSpace(content)}, true
+	}
+	return productData{}, false
+}
+
+// parseJsonLd looks for the first schema.org Product in the application/ld+json block
+func parseJsonLd(raw string) (productData, bool) {
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return productData{}, false
+	}
+	product, ok := findJsonLdProduct(value)
+	if !ok {
+		return productData{}, false
+	}
+
+	data := productData{
+		Brand: jsonLdName(product["brand"]),
+		SKU:   jsonLdString(product["sku"]),
+	}
+	if offer, ok := firstJsonLdObject(product["offers"]); ok {
+		price := jsonLdString(offer["price"])
+		if price == "" {
+			// AggregateOffer has a price range only
+			price = jsonLdString(offer["lowPrice"])
+		}
+		if price == "" {
+			if specification, ok := firstJsonLdObject(offer["priceSpecification"]); ok {
+				price = jsonLdString(specification["price"])
+				if offer["priceCurrency"] == nil {
+					offer["priceCurrency"] = specification["priceCurrency"]
+				}
+			}
+		}
+		data.Price = normalizePrice(price)
+		data.Currency = normalizeCurrency(jsonLdString(offer["priceCurrency"]))
+		data.Availability = normalizeAvailability(jsonLdString(offer["availability"]))
+		if data.SKU == "" {
+			data.SKU = jsonLdString(offer["sku"])
+		}
+	}
+	return data, true
+}
+
+func findJsonLdProduct(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if product, ok := findJsonLdProduct(item); ok {
+				return product, true
+			}
+		}
+	case map[string]any:
+		if isJsonLdType(v["@type"], "Product") {
+			return v, true
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findJsonLdProduct(graph)
+		}
+		// the product may be the main entity of a web page
+		if entity, ok := v["mainEntity"]; ok {
+			return findJsonLdProduct(entity)
+		}
+	}
+	return nil, false
+}
+
+func isJsonLdType(value any, expected string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.EqualFold(v, expected) || strings.HasSuffix(v, "/"+expected)
+	case []any:
+		for _, item := range v {
+			if isJsonLdType(item, expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstJsonLdObject(value any) (map[string]any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		return v, true
+	case []any:
+		for _, item := range v {
+			if object, ok := item.(map[string]any); ok {
+				return object, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// jsonLdString returns strings and numbers as they are, prices are often written as numbers
+func jsonLdString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// jsonLdName returns the value itself or the name of the nested object, e.g. for the Brand type
+func jsonLdName(value any) string {
+	if object, ok := firstJsonLdObject(value); ok {
+		return jsonLdString(object["name"])
+	}
+	return jsonLdString(value)
+}
+
+// microdataParser keeps the state between the tokens of schema.org microdata
+type microdataParser struct {
+	inBrandScope bool
+}
+
+// parse handles the element with the itemprop attribute. The text content is read from the tokenizer
+// when the element has no content attribute.
+func (m *microdataParser) parse(t *html.Tokenizer, token html.Token, tokenType html.TokenType) (productData, bool) {
+	var itemProp string
+	var value string
+	var hasValue bool
+	var isScope bool
+	for _, attr := range token.Attr {
+		switch cleanStr(attr.Key) {
+		case "itemprop":
+			itemProp = strings.TrimSpace(attr.Val)
+		case "content":
+			value, hasValue = attr.Val, true
+		case "href":
+			if token.Data == "link" && !hasValue {
+				value, hasValue = attr.Val, true
+			}
+		case "itemscope":
+			isScope = true
+		}
+	}
+	if !isProductItemProp(itemProp) {
+		return productData{}, false
+	}
+	if isScope {
+		// the brand may be a nested item with its own name property
+		m.inBrandScope = itemProp == "brand"
+		return productData{}, false
+	}
+	if itemProp == "name" && !m.inBrandScope {
+		return productData{}, false
+	}
+	if !hasValue {
+		if tokenType != html.StartTagToken || isVoidElement(token.Data) {
+			return productData{}, false
+		}
+		if t.Next() != html.TextToken {
+			return productData{}, false
+		}
+		value = t.Token().Data
+	}
+
+	switch itemProp {
+	case "price", "lowPrice":
+		return productData{Price: normalizePrice(value)}, true
+	case "priceCurrency":
+		return productData{Currency: normalizeCurrency(value)}, true
+	case "brand":
+		return productData{Brand: strings.TrimSpace(value)}, true
+	case "availability":
+		return productData{Availability: normalizeAvailability(value)}, true
+	case "sku":
+		return productData{SKU: strings.TrimSpace(value)}, true
+	case "name":
+		m.inBrandScope = false
+		return productData{Brand: strings.TrimSpace(value)}, true
+	}
+	return productData{}, false
+}
+
+func isProductItemProp(itemProp string) bool {
+	switch itemProp {
+	case "price", "lowPrice", "priceCurrency", "brand", "availability", "sku", "name":
+		return true
+	}
+	return false
+}
+
+func isVoidElement(tag string) bool {
+	switch tag {
+	case "meta", "link", "img", "input", "br", "hr", "source":
+		return true
+	}
+	return false
+}
+
+// normalizePrice converts prices like "1 299,90 ₽" or "$1,299.90" to the "1299.90" form,
+// an empty string is returned when there is no number
+func normalizePrice(raw string) string {
+	var b strings.Builder
+	for _, r := range raw {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' {
+			b.WriteRune(r)
+		}
+	}
+	price := strings.Trim(b.String(), ".,")
+	if price == "" {
+		return ""
+	}
+
+	lastDot := strings.LastIndex(price, ".")
+	lastComma := strings.LastIndex(price, ",")
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		// the latter separator is the decimal one
+		if lastComma > lastDot {
+			price = strings.ReplaceAll(price, ".", "")
+			price = strings.Replace(price, ",", ".", 1)
+		} else {
+			price = strings.ReplaceAll(price, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(price, ",") == 1 && len(price)-lastComma-1 != 3 {
+			price = strings.Replace(price, ",", ".", 1)
+		} else {
+			price = strings.ReplaceAll(price, ",", "")
+		}
+	case strings.Count(price, ".") > 1:
+		price = strings.ReplaceAll(price, ".", "")
+	}
+
+	if _, err := strconv.ParseFloat(price, 64); err != nil {
+		return ""
+	}
+	return price
+}
+
+func normalizeCurrency(raw string) string {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if len(code) != 3 {
+		return ""
+	}
+	return code
+}
+
+// normalizeAvailability turns "https://schema.org/InStock" and "instock" into "InStock"
+func normalizeAvailability(raw string) string {
+	value := strings.TrimSpace(raw)
+	if i := strings.LastIndex(value, "/"); i >= 0 {
+		value = value[i+1:]
+	}
+	switch strings.ToLower(strings.ReplaceAll(value, " ", "")) {
+	case "":
+		return ""
+	case "instock", "in_stock":
+		return "InStock"
+	case "outofstock", "out_of_stock", "oos":
+		return "OutOfStock"
+	case "preorder", "pre_order":
+		return "PreOrder"
+	case "backorder", "back_order":
+		return "BackOrder"
+	case "discontinued":
+		return "Discontinued"
+	case "limitedavailability":
+		return "LimitedAvailability"
+	case "soldout":
+		return "SoldOut"
+	}
+	return value
+}
@@ -0,0 +1,18 @@
+create table event_outbox
+(
+    id           bigserial    not null,
+    name         varchar(255) not null,
+    payload      jsonb        not null,
+    created_at   timestamp    not null,
+    processed_at timestamp
+);
+
+alter table event_outbox
+    owner to postgres;
+
+create unique index event_outbox_id_uindex
+    on event_outbox (id);
+
+create index event_outbox_unprocessed_index
+    on event_outbox (id)
+    where processed_at is null;
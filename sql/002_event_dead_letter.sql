@@ -0,0 +1,16 @@
+create table event_dead_letter
+(
+    id              varchar(255) not null,
+    event_name      varchar(255) not null,
+    subscription_id varchar(255) not null,
+    payload         jsonb        not null,
+    error           text         not null,
+    attempts        integer      not null,
+    failed_at       timestamp    not null
+);
+
+alter table event_dead_letter
+    owner to postgres;
+
+create unique index event_dead_letter_id_uindex
+    on event_dead_letter (id);
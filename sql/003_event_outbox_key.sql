@@ -0,0 +1,2 @@
+alter table event_outbox
+    add event_key varchar(255) default '' not null;
@@ -0,0 +1,8 @@
+alter table subscribe
+    add is_notify_enabled boolean default true not null;
+
+create unique index subscribe_user_id_wishlist_id_uindex
+    on subscribe (user_id, wishlist_id);
+
+create index subscribe_wishlist_id_index
+    on subscribe (wishlist_id);
@@ -0,0 +1,26 @@
+alter table users
+    add notify_delivery varchar(255) default 'instant'::character varying not null;
+
+alter table users
+    add digest_hour integer default 9 not null;
+
+create table digest_entry
+(
+    id          varchar(255) not null,
+    user_id     varchar(255) not null,
+    wishlist_id varchar(255) not null,
+    product_id  varchar(255) not null,
+    type        varchar(255) not null,
+    old_price   price,
+    new_price   price,
+    created_at  timestamp    not null
+);
+
+alter table digest_entry
+    owner to postgres;
+
+create unique index digest_entry_id_uindex
+    on digest_entry (id);
+
+create index digest_entry_user_id_index
+    on digest_entry (user_id);
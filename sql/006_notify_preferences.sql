@@ -0,0 +1,11 @@
+alter table users
+    add muted_notify_events varchar(255) default ''::character varying not null;
+
+alter table users
+    add quiet_hours_from integer;
+
+alter table users
+    add quiet_hours_to integer;
+
+alter table users
+    add timezone varchar(255) default 'UTC'::character varying not null;
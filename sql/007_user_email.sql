@@ -0,0 +1,14 @@
+alter table users
+    add email varchar(255);
+
+alter table users
+    add is_email_verified boolean default false not null;
+
+alter table users
+    add email_code varchar(255);
+
+alter table users
+    add email_code_expires_at timestamp;
+
+alter table users
+    add email_code_attempts integer default 0 not null;
@@ -0,0 +1,22 @@
+alter table wishlist
+    add visibility varchar(255) default 'public'::character varying not null;
+
+alter table wishlist
+    add share_token varchar(255) default '' not null;
+
+update wishlist
+set share_token = md5(random()::text || id)
+where share_token = '';
+
+create table wishlist_invite
+(
+    wishlist_id varchar(255) not null,
+    user_id     varchar(255) not null,
+    created_at  timestamp    not null
+);
+
+alter table wishlist_invite
+    owner to postgres;
+
+create unique index wishlist_invite_wishlist_id_user_id_uindex
+    on wishlist_invite (wishlist_id, user_id);
@@ -0,0 +1,2 @@
+alter table wishlist
+    add is_surprise_mode boolean default true not null;
@@ -0,0 +1,15 @@
+create table wishlist_item_contribution
+(
+    wishlist_id varchar(255) not null,
+    product_id  varchar(255) not null,
+    user_id     varchar(255) not null,
+    amount      price        not null,
+    created_at  timestamp    not null,
+    updated_at  timestamp    not null
+);
+
+alter table wishlist_item_contribution
+    owner to postgres;
+
+create unique index wishlist_item_contribution_item_user_uindex
+    on wishlist_item_contribution (wishlist_id, product_id, user_id);
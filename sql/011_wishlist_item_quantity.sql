@@ -0,0 +1,26 @@
+alter table wishlist_item
+    add quantity integer default 1 not null;
+
+create table wishlist_item_booking
+(
+    wishlist_id varchar(255) not null,
+    product_id  varchar(255) not null,
+    user_id     varchar(255) not null,
+    quantity    integer      not null,
+    created_at  timestamp    not null,
+    updated_at  timestamp    not null
+);
+
+alter table wishlist_item_booking
+    owner to postgres;
+
+create unique index wishlist_item_booking_item_user_uindex
+    on wishlist_item_booking (wishlist_id, product_id, user_id);
+
+insert into wishlist_item_booking (wishlist_id, product_id, user_id, quantity, created_at, updated_at)
+select wishlist_id, product_id, is_booked_by, 1, updated_at, updated_at
+from wishlist_item
+where is_booked_by is not null;
+
+alter table wishlist_item
+    drop column is_booked_by;
@@ -0,0 +1,2 @@
+alter table wishlist_item
+    add version integer default 0 not null;
@@ -0,0 +1,8 @@
+alter table wishlist_item_booking
+    add expires_at timestamp;
+
+alter table wishlist_item_booking
+    add is_reminder_sent boolean default false not null;
+
+create index wishlist_item_booking_expires_at_index
+    on wishlist_item_booking (expires_at);
@@ -0,0 +1,5 @@
+alter table wishlist_item
+    add fulfilled_at timestamp;
+
+create index wishlist_item_wishlist_id_fulfilled_at_index
+    on wishlist_item (wishlist_id, fulfilled_at);
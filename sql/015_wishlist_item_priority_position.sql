@@ -0,0 +1,8 @@
+alter table wishlist_item
+    add priority varchar(255) default 'nice_to_have'::character varying not null;
+
+alter table wishlist_item
+    add position integer default 0 not null;
+
+create index wishlist_item_wishlist_id_position_index
+    on wishlist_item (wishlist_id, position);
@@ -0,0 +1,8 @@
+alter table wishlist
+    add occasion_date date;
+
+alter table wishlist
+    add is_occasion_yearly boolean default false not null;
+
+alter table wishlist
+    add occasion_reminded_for date;
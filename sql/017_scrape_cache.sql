@@ -0,0 +1,23 @@
+create table scrape_cache
+(
+    url           text                      not null,
+    title         text                      not null,
+    description   text                      not null,
+    images        jsonb default '[]'::jsonb not null,
+    price         price,
+    brand         varchar(255)              not null,
+    availability  varchar(255)              not null,
+    sku           varchar(255)              not null,
+    image_id      varchar(255),
+    error_message text                      not null,
+    scraped_at    timestamp                 not null
+);
+
+alter table scrape_cache
+    owner to postgres;
+
+create unique index scrape_cache_url_uindex
+    on scrape_cache (url);
+
+create index scrape_cache_scraped_at_index
+    on scrape_cache (scraped_at);
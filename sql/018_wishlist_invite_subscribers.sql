@@ -0,0 +1,7 @@
+insert into wishlist_invite (wishlist_id, user_id, created_at)
+select subscribe.wishlist_id, subscribe.user_id, subscribe.created_at
+from subscribe
+         join wishlist on wishlist.id = subscribe.wishlist_id
+where wishlist.visibility = 'link'
+  and wishlist.user_id <> subscribe.user_id
+on conflict (wishlist_id, user_id) do nothing;
@@ -0,0 +1,15 @@
+create table new_item_pending
+(
+    wishlist_id varchar(255) not null,
+    product_id  varchar(255) not null,
+    created_at  timestamp    not null
+);
+
+alter table new_item_pending
+    owner to postgres;
+
+create unique index new_item_pending_wishlist_id_product_id_uindex
+    on new_item_pending (wishlist_id, product_id);
+
+create index new_item_pending_created_at_index
+    on new_item_pending (created_at);
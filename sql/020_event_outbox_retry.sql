@@ -0,0 +1,25 @@
+alter table event_outbox
+    add attempts integer default 0 not null;
+
+alter table event_outbox
+    add next_attempt_at timestamp;
+
+alter table event_outbox
+    add handled_by jsonb default '[]'::jsonb not null;
+
+create index event_outbox_event_key_index
+    on event_outbox (event_key, id)
+    where processed_at is null;
+
+-- subscriptions were identified by their registration order, "notify" was registered before "digest"
+update event_dead_letter
+set subscription_id = event_name || '/digest'
+where subscription_id = event_name || '#1';
+
+update event_dead_letter
+set subscription_id = event_name || '/' || case
+    when event_name in ('product.price.changed', 'wish.item.removed') then 'digest'
+    when event_name = 'wishlist.deleted' then 'subscribe'
+    else 'notify'
+    end
+where subscription_id = event_name || '#0';
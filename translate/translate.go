@@ -94,4 +94,96 @@ var translates = map[string]map[string]string{
 		"en": "Empty",
 		"ru": "Пусто",
 	},
+	"notify_wish_booked_by_you": {
+		"en": "You have booked the wish [%s](%s).\n\nNobody else can book it now. Don't forget about your gift!",
+		"ru": "Вы забронировали желание [%s](%s).\n\nТеперь никто другой не сможет его забронировать. Не забудьте про подарок!",
+	},
+	"notify_wish_units_booked_by_you": {
+		"en": "You have booked %d pcs. of the wish [%s](%s). Don't forget about your gift!",
+		"ru": "Вы забронировали %d шт. желания [%s](%s). Не забудьте про подарок!",
+	},
+	"notify_wish_booked_for_owner": {
+		"en": "🎁 Someone has booked the wish [%s](%s) from your list!",
+		"ru": "🎁 Кто-то забронировал желание [%s](%s) из вашего списка!",
+	},
+	"notify_wish_unbooked_by_owner": {
+		"en": "The owner of the wishlist has cancelled your booking of the wish [%s](%s).",
+		"ru": "Владелец вишлиста отменил вашу бронь желания [%s](%s).",
+	},
+	"notify_wishlist_occasion_upcoming": {
+		"en": "The occasion of the wishlist [%s](%s) is on %s, %d day(s) left. Wishes not booked yet: %d.",
+		"ru": "Событие вишлиста [%s](%s) состоится %s, осталось дней: %d. Ещё не забронировано желаний: %d.",
+	},
+	"notify_wish_booking_expiring": {
+		"en": "Your booking of the wish [%s](%s) expires on %s. Extend it or it will be released for others.",
+		"ru": "Ваша бронь желания [%s](%s) истекает %s. Продлите её, иначе желание станет доступно другим.",
+	},
+	"notify_wish_booking_expired": {
+		"en": "Your booking of the wish [%s](%s) has expired and the wish is available again.",
+		"ru": "Срок вашей брони желания [%s](%s) истёк, и желание снова доступно.",
+	},
+	"notify_gift_received_for_giver": {
+		"en": "%s has received your gift \"%s\"!",
+		"ru": "%s получил(а) ваш подарок «%s»!",
+	},
+	"notify_gift_received_for_owner": {
+		"en": "The wish \"%s\" is marked as received. It was given by %s. Don't forget to [say thanks](%s)!",
+		"ru": "Желание «%s» отмечено как полученное. Его подарили: %s. Не забудьте [сказать спасибо](%s)!",
+	},
+	"notify_gift_thanks": {
+		"en": "%s thanks you for the gift \"%s\"!",
+		"ru": "%s благодарит вас за подарок «%s»!",
+	},
+	"notify_gift_thanks_with_message": {
+		"en": "%s thanks you for the gift \"%s\":\n\n%s",
+		"ru": "%s благодарит вас за подарок «%s»:\n\n%s",
+	},
+	"notify_wish_unbooked_for_owner": {
+		"en": "The booking of the wish [%s](%s) has been cancelled. It is available again.",
+		"ru": "Бронь желания [%s](%s) отменена. Оно снова доступно.",
+	},
+	"notify_wish_contributed_by_you": {
+		"en": "You have pledged %s toward the wish [%s](%s). It is covered by %d%% now.",
+		"ru": "Вы вложили %s в желание [%s](%s). Сейчас собрано %d%%.",
+	},
+	"notify_wish_fully_covered_for_contributor": {
+		"en": "🎉 The wish [%s](%s) is fully covered by contributions. Time to buy the gift together!",
+		"ru": "🎉 На желание [%s](%s) собрана вся сумма. Пора вместе купить подарок!",
+	},
+	"notify_wish_fully_covered_for_owner": {
+		"en": "🎁 Friends have chipped in for the wish [%s](%s) from your list!",
+		"ru": "🎁 Друзья скинулись на желание [%s](%s) из вашего списка!",
+	},
+	"notify_wish_coverage_lost_for_contributor": {
+		"en": "A contribution to the wish [%s](%s) was withdrawn. It is covered by %d%% now.",
+		"ru": "Один из участников отозвал свой вклад в желание [%s](%s). Сейчас собрано %d%%.",
+	},
+	"notify_wishlist_new_items": {
+		"en": "New wishes in the wishlist [%s](%s):\n\n%s",
+		"ru": "Новые желания в вишлисте [%s](%s):\n\n%s",
+	},
+	"email_subject": {
+		"en": "News from your wishlists",
+		"ru": "Новости ваших вишлистов",
+	},
+	"email_footer": {
+		"en": "You receive this email because you have chosen email notifications in the wishlist app.",
+		"ru": "Вы получили это письмо, потому что выбрали уведомления по email в приложении вишлистов.",
+	},
+	"email_verification_code": {
+		"en": "Your verification code: *%s*\n\nIt is valid for 15 minutes.",
+		"ru": "Ваш код подтверждения: *%s*\n\nОн действует 15 минут.",
+	},
+	"notify_digest_daily": {
+		"en": "What changed in your followed wishlists today:\n\n%s",
+		"ru": "Что изменилось в ваших вишлистах за день:\n\n%s",
+	},
+	"notify_digest_weekly": {
+		"en": "What changed in your followed wishlists this week:\n\n%s",
+		"ru": "Что изменилось в ваших вишлистах за неделю:\n\n%s",
+	},
+	"notify_digest_continued": {
+		"en": "(continued)\n\n%s",
+		"ru": "(продолжение)\n\n%s",
+	},
 }